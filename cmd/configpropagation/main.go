@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"time"
 
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
@@ -15,6 +16,7 @@ import (
 	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
 	crwebhook "sigs.k8s.io/controller-runtime/pkg/webhook"
 
+	"configpropagation/pkg/adapters/webhooks"
 	configv1alpha1 "configpropagation/pkg/api/v1alpha1"
 	"configpropagation/pkg/controllers/configpropagation"
 )
@@ -36,21 +38,37 @@ func main() {
 	var probeAddress string
 	var enableLeaderElection bool
 	var webhookPort int
+	var serverSideApply bool
+	var apiCallTimeout time.Duration
+	var requireSourceOptIn bool
 
 	enableWebhooks := defaultEnableWebhooks()
+	kubeAPIQPS, kubeAPIBurst := defaultRateLimit()
+	maxConcurrentReconciles := defaultMaxConcurrentReconciles()
 
 	flag.StringVar(&metricsAddress, "metrics-bind-address", ":8080", "The address the metric endpoint binds to.")
 	flag.StringVar(&probeAddress, "health-probe-bind-address", ":8081", "The address the health probe endpoint binds to.")
 	flag.BoolVar(&enableLeaderElection, "leader-elect", false, "Enable leader election for controller manager. Enabling this will ensure there is only one active controller manager.")
 	flag.IntVar(&webhookPort, "webhook-port", 9443, "Webhook server port.")
 	flag.BoolVar(&enableWebhooks, "enable-webhooks", enableWebhooks, "Enable Kubernetes admission webhooks.")
+	flag.BoolVar(&serverSideApply, "server-side-apply", false, "Write target ConfigMaps with server-side apply under the configpropagation field manager.")
+	flag.DurationVar(&apiCallTimeout, "api-call-timeout", 30*time.Second, "Timeout applied to each Kubernetes API call made while reconciling; 0 disables it.")
+	flag.BoolVar(&requireSourceOptIn, "require-source-opt-in", true, "Only read a source ConfigMap in another namespace when its allow-from-namespaces annotation lists the ConfigPropagation's namespace.")
+	flag.IntVar(&maxConcurrentReconciles, "max-concurrent-reconciles", maxConcurrentReconciles, "Number of ConfigPropagations reconciled concurrently.")
+	flag.Float64Var(&kubeAPIQPS, "kube-api-qps", kubeAPIQPS, "Sustained queries per second the manager may send to the API server; target workers share this budget.")
+	flag.IntVar(&kubeAPIBurst, "kube-api-burst", kubeAPIBurst, "Burst of queries the manager may send to the API server above kube-api-qps.")
 	opts := zap.Options{Development: true}
 	opts.BindFlags(flag.CommandLine)
 	flag.Parse()
 
 	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&opts)))
 
-	manager, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
+	// Every reconcile and target worker shares the client-side rate limiter configured here.
+	restConfig := ctrl.GetConfigOrDie()
+	restConfig.QPS = float32(kubeAPIQPS)
+	restConfig.Burst = kubeAPIBurst
+
+	manager, err := ctrl.NewManager(restConfig, ctrl.Options{
 		Scheme: scheme,
 		Metrics: metricsserver.Options{
 			BindAddress: metricsAddress,
@@ -65,13 +83,18 @@ func main() {
 		os.Exit(1)
 	}
 
-	if err := configpropagation.SetupWithManager(manager); err != nil {
+	if err := configpropagation.SetupWithManager(manager, configpropagation.Options{
+		ServerSideApply:         serverSideApply,
+		APICallTimeout:          apiCallTimeout,
+		MaxConcurrentReconciles: maxConcurrentReconciles,
+		RequireSourceOptIn:      requireSourceOptIn,
+	}); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "ConfigPropagation")
 		os.Exit(1)
 	}
 
 	if enableWebhooks {
-		if err := (&configv1alpha1.ConfigPropagation{}).SetupWebhookWithManager(manager); err != nil {
+		if err := webhooks.NewConfigPropagationValidator(manager.GetClient()).SetupWebhookWithManager(manager); err != nil {
 			setupLog.Error(err, "unable to create webhook", "webhook", "ConfigPropagation")
 			os.Exit(1)
 		}
@@ -108,3 +131,47 @@ func defaultEnableWebhooks() bool {
 
 	return parsedValue
 }
+
+// defaultRateLimit reads the client QPS and burst from RATE_LIMIT_QPS and BURST, falling back to the
+// controller-runtime defaults.
+func defaultRateLimit() (float64, int) {
+	qps, burst := 20.0, 30
+
+	if environmentValue := os.Getenv("RATE_LIMIT_QPS"); environmentValue != "" {
+		parsedValue, err := strconv.ParseFloat(environmentValue, 64)
+		if err != nil || parsedValue <= 0 {
+			setupLog.Error(fmt.Errorf("invalid RATE_LIMIT_QPS value %q", environmentValue), "using the default client QPS")
+		} else {
+			qps = parsedValue
+		}
+	}
+
+	if environmentValue := os.Getenv("BURST"); environmentValue != "" {
+		parsedValue, err := strconv.Atoi(environmentValue)
+		if err != nil || parsedValue <= 0 {
+			setupLog.Error(fmt.Errorf("invalid BURST value %q", environmentValue), "using the default client burst")
+		} else {
+			burst = parsedValue
+		}
+	}
+
+	return qps, burst
+}
+
+// defaultMaxConcurrentReconciles reads the number of concurrent reconciles from MAX_CONCURRENT_RECONCILES.
+func defaultMaxConcurrentReconciles() int {
+	const fallback = 4
+
+	environmentValue := os.Getenv("MAX_CONCURRENT_RECONCILES")
+	if environmentValue == "" {
+		return fallback
+	}
+
+	parsedValue, err := strconv.Atoi(environmentValue)
+	if err != nil || parsedValue < 1 {
+		setupLog.Error(fmt.Errorf("invalid MAX_CONCURRENT_RECONCILES value %q", environmentValue), "using the default concurrency")
+		return fallback
+	}
+
+	return parsedValue
+}
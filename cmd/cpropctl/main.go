@@ -0,0 +1,168 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/clientcmd"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	configv1alpha1 "configpropagation/pkg/api/v1alpha1"
+	"configpropagation/pkg/cpropctl"
+)
+
+const usage = `cpropctl inspects and operates ConfigPropagations.
+
+Usage:
+  cpropctl <command> [flags] [NAME]
+
+Commands:
+  status NAME   show the sync state of every selected namespace
+  diff NAME     compare the propagated data with each target; exits 1 when a target differs
+  plan NAME     show what the next reconcile would write
+  pause NAME    stop writing targets until resumed
+  resume NAME   resume writing targets
+  orphans       list managed targets whose ConfigPropagation no longer exists
+
+Flags:
+`
+
+// commands maps each command to whether it takes a ConfigPropagation name.
+var commands = map[string]bool{"status": true, "diff": true, "plan": true, "pause": true, "resume": true, "orphans": false}
+
+var scheme = runtime.NewScheme()
+
+// init registers the Kubernetes API types the CLI reads.
+func init() {
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+	utilruntime.Must(configv1alpha1.AddToScheme(scheme))
+}
+
+// main runs one cpropctl command and exits with its status.
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// run parses the command line, connects with the kubeconfig and runs the command; it returns the exit status.
+func run(args []string, out, errOut io.Writer) int {
+	flags := flag.NewFlagSet("cpropctl", flag.ContinueOnError)
+	flags.SetOutput(errOut)
+	flags.Usage = func() {
+		fmt.Fprint(errOut, usage)
+		flags.PrintDefaults()
+	}
+
+	var kubeconfig, contextName, namespace string
+	var requireSourceOptIn bool
+
+	flags.StringVar(&kubeconfig, "kubeconfig", "", "Path to the kubeconfig file; defaults to $KUBECONFIG or ~/.kube/config.")
+	flags.StringVar(&contextName, "context", "", "The kubeconfig context to use.")
+	flags.StringVar(&namespace, "namespace", "", "Namespace of the ConfigPropagation; defaults to the context namespace. Limits orphans to one namespace.")
+	flags.StringVar(&namespace, "n", "", "Shorthand for --namespace.")
+	flags.BoolVar(&requireSourceOptIn, "require-source-opt-in", true, "Match the manager flag of the same name when running diff and plan.")
+
+	if len(args) == 0 {
+		flags.Usage()
+		return 2
+	}
+
+	command := args[0]
+	if _, known := commands[command]; !known {
+		fmt.Fprintf(errOut, "error: unknown command %q\n", command)
+		flags.Usage()
+		return 2
+	}
+
+	positional, err := parseInterspersed(flags, args[1:])
+	if err != nil {
+		return 2
+	}
+
+	needsName := commands[command]
+	if needsName && len(positional) != 1 || !needsName && len(positional) != 0 {
+		flags.Usage()
+		return 2
+	}
+
+	var name string
+	if needsName {
+		name = positional[0]
+	}
+
+	loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
+	loadingRules.ExplicitPath = kubeconfig
+	clientConfig := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(loadingRules, &clientcmd.ConfigOverrides{CurrentContext: contextName})
+
+	restConfig, err := clientConfig.ClientConfig()
+	if err != nil {
+		fmt.Fprintf(errOut, "error: load kubeconfig: %v\n", err)
+		return 1
+	}
+
+	// Orphans span every namespace unless one is asked for; the other commands default to the context namespace.
+	if namespace == "" && needsName {
+		if namespace, _, err = clientConfig.Namespace(); err != nil {
+			fmt.Fprintf(errOut, "error: resolve namespace: %v\n", err)
+			return 1
+		}
+	}
+
+	kubeClient, err := client.New(restConfig, client.Options{Scheme: scheme})
+	if err != nil {
+		fmt.Fprintf(errOut, "error: create client: %v\n", err)
+		return 1
+	}
+
+	cli := cpropctl.NewCLI(kubeClient, out)
+	cli.RequireSourceOptIn = requireSourceOptIn
+	requestContext := context.Background()
+
+	switch command {
+	case "status":
+		err = cli.Status(requestContext, namespace, name)
+	case "diff":
+		var differs bool
+		if differs, err = cli.Diff(requestContext, namespace, name); err == nil && differs {
+			return 1
+		}
+	case "plan":
+		err = cli.Plan(requestContext, namespace, name)
+	case "pause":
+		err = cli.Pause(requestContext, namespace, name)
+	case "resume":
+		err = cli.Resume(requestContext, namespace, name)
+	case "orphans":
+		err = cli.Orphans(requestContext, namespace)
+	}
+
+	if err != nil {
+		fmt.Fprintf(errOut, "error: %v\n", err)
+		return 1
+	}
+
+	return 0
+}
+
+// parseInterspersed parses flags placed before and after positional arguments, as kubectl accepts them.
+func parseInterspersed(flags *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+
+	for {
+		if err := flags.Parse(args); err != nil {
+			return nil, err
+		}
+
+		if flags.NArg() == 0 {
+			return positional, nil
+		}
+
+		positional = append(positional, flags.Arg(0))
+		args = flags.Args()[1:]
+	}
+}
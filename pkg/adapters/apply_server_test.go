@@ -0,0 +1,230 @@
+package adapters
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	configv1alpha1 "configpropagation/pkg/api/v1alpha1"
+)
+
+// otherManager owns every field written outside server-side apply, like kubectl edit or another controller's update.
+const otherManager = "other"
+
+// applyServer emulates server-side apply field ownership for ConfigMaps on top of the fake client,
+// which cannot create objects from apply patches nor track managed fields.
+// Ownership is tracked per data, binaryData, label and annotation key.
+type applyServer struct {
+	mutex  sync.Mutex
+	owners map[types.NamespacedName]map[string]string
+}
+
+// newApplyFakeClient returns a fake client whose apply patches go through an applyServer.
+func newApplyFakeClient(t *testing.T, objects ...client.Object) client.Client {
+	t.Helper()
+
+	server := &applyServer{owners: map[types.NamespacedName]map[string]string{}}
+
+	// ConfigPropagation types are registered so a Reconciler can list policies through the client.
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatalf("add core scheme: %v", err)
+	}
+	if err := configv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatalf("add configpropagation scheme: %v", err)
+	}
+
+	return withTargetIndexes(fake.NewClientBuilder()).
+		WithScheme(scheme).
+		WithObjects(objects...).
+		WithInterceptorFuncs(interceptor.Funcs{
+			Patch: func(requestContext context.Context, kubeClient client.WithWatch, object client.Object, patch client.Patch, options ...client.PatchOption) error {
+				configMap, isConfigMap := object.(*corev1.ConfigMap)
+				if patch.Type() != types.ApplyPatchType || !isConfigMap {
+					return kubeClient.Patch(requestContext, object, patch, options...)
+				}
+
+				return server.apply(requestContext, kubeClient, configMap, options)
+			},
+		}).
+		Build()
+}
+
+// apply merges the applied ConfigMap into the stored one following server-side apply ownership rules.
+func (server *applyServer) apply(requestContext context.Context, kubeClient client.Client, applied *corev1.ConfigMap, options []client.PatchOption) error {
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+
+	patchOptions := &client.PatchOptions{}
+	patchOptions.ApplyOptions(options)
+	manager := patchOptions.FieldManager
+	force := patchOptions.Force != nil && *patchOptions.Force
+
+	objectKey := types.NamespacedName{Namespace: applied.Namespace, Name: applied.Name}
+	appliedFields := configMapFields(applied)
+
+	var existing corev1.ConfigMap
+	if err := kubeClient.Get(requestContext, objectKey, &existing); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return err
+		}
+
+		created := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: applied.Namespace, Name: applied.Name}}
+		applyFields(created, appliedFields)
+		server.owners[objectKey] = ownedBy(manager, appliedFields)
+
+		if err := kubeClient.Create(requestContext, created); err != nil {
+			return err
+		}
+
+		// Like the API server, respond with the stored object.
+		created.DeepCopyInto(applied)
+		return nil
+	}
+
+	owners := server.owners[objectKey]
+	if owners == nil {
+		owners = map[string]string{}
+	}
+
+	var causes []metav1.StatusCause
+	for _, path := range sortedFieldPaths(appliedFields) {
+		liveValue, exists := configMapFields(&existing)[path]
+		owner, owned := owners[path]
+		if !owned {
+			owner = otherManager
+		}
+
+		if exists && liveValue != appliedFields[path] && owner != manager && !force {
+			causes = append(causes, metav1.StatusCause{Type: metav1.CauseTypeFieldManagerConflict, Message: fmt.Sprintf("conflict with %q", owner), Field: path})
+		}
+	}
+
+	if len(causes) > 0 {
+		return apierrors.NewApplyConflict(causes, fmt.Sprintf("Apply failed with %d conflicts", len(causes)))
+	}
+
+	// Fields this manager applied before but omits now are removed.
+	for path, owner := range owners {
+		if _, stillApplied := appliedFields[path]; owner == manager && !stillApplied {
+			removeField(&existing, path)
+			delete(owners, path)
+		}
+	}
+
+	applyFields(&existing, appliedFields)
+	for path := range appliedFields {
+		owners[path] = manager
+	}
+	server.owners[objectKey] = owners
+
+	if err := kubeClient.Update(requestContext, &existing); err != nil {
+		return err
+	}
+
+	existing.DeepCopyInto(applied)
+	return nil
+}
+
+// configMapFields flattens the keyed fields of a ConfigMap into path/value pairs.
+func configMapFields(configMap *corev1.ConfigMap) map[string]string {
+	fields := map[string]string{}
+	for key, value := range configMap.Labels {
+		fields["labels/"+key] = value
+	}
+	for key, value := range configMap.Annotations {
+		fields["annotations/"+key] = value
+	}
+	for key, value := range configMap.Data {
+		fields["data/"+key] = value
+	}
+	for key, value := range configMap.BinaryData {
+		fields["binaryData/"+key] = string(value)
+	}
+	return fields
+}
+
+// applyFields sets the flattened fields on a ConfigMap.
+func applyFields(configMap *corev1.ConfigMap, fields map[string]string) {
+	for path, value := range fields {
+		section, key := splitFieldPath(path)
+		switch section {
+		case "labels":
+			if configMap.Labels == nil {
+				configMap.Labels = map[string]string{}
+			}
+			configMap.Labels[key] = value
+		case "annotations":
+			if configMap.Annotations == nil {
+				configMap.Annotations = map[string]string{}
+			}
+			configMap.Annotations[key] = value
+		case "data":
+			if configMap.Data == nil {
+				configMap.Data = map[string]string{}
+			}
+			configMap.Data[key] = value
+		case "binaryData":
+			if configMap.BinaryData == nil {
+				configMap.BinaryData = map[string][]byte{}
+			}
+			configMap.BinaryData[key] = []byte(value)
+		}
+	}
+}
+
+// removeField deletes one flattened field from a ConfigMap.
+func removeField(configMap *corev1.ConfigMap, path string) {
+	section, key := splitFieldPath(path)
+	switch section {
+	case "labels":
+		delete(configMap.Labels, key)
+	case "annotations":
+		delete(configMap.Annotations, key)
+	case "data":
+		delete(configMap.Data, key)
+	case "binaryData":
+		delete(configMap.BinaryData, key)
+	}
+}
+
+// splitFieldPath separates the section from the key of a flattened field path.
+func splitFieldPath(path string) (string, string) {
+	for index := range path {
+		if path[index] == '/' {
+			return path[:index], path[index+1:]
+		}
+	}
+	return path, ""
+}
+
+// ownedBy records the manager as owner of every field.
+func ownedBy(manager string, fields map[string]string) map[string]string {
+	owners := make(map[string]string, len(fields))
+	for path := range fields {
+		owners[path] = manager
+	}
+	return owners
+}
+
+// sortedFieldPaths returns the field paths in a stable order for deterministic conflict messages.
+func sortedFieldPaths(fields map[string]string) []string {
+	paths := make([]string, 0, len(fields))
+	for path := range fields {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths
+}
@@ -3,44 +3,151 @@ package adapters
 import (
 	"context"
 	"fmt"
+	"sort"
+	"time"
 
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/selection"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
+	configv1alpha1 "configpropagation/pkg/api/v1alpha1"
 	"configpropagation/pkg/core"
 )
 
+// ClientOptions tunes the KubeClient adapters.
+type ClientOptions struct {
+	// CallTimeout bounds each KubeClient call, including the read an update makes first; zero leaves calls bounded
+	// only by the caller's context.
+	CallTimeout time.Duration
+	// UnindexedTargetLookup makes ListManagedTargets list ConfigMaps by the managed label and match the source and
+	// owner annotations in memory, for clients without the indexes of RegisterTargetIndexes such as a direct API
+	// server client.
+	UnindexedTargetLookup bool
+}
+
+var (
+	_ PolicyLister         = &controllerRuntimeClient{}
+	_ SourceMetadataReader = &controllerRuntimeClient{}
+)
+
 type controllerRuntimeClient struct {
-	client client.Client
+	client                client.Client
+	callTimeout           time.Duration
+	unindexedTargetLookup bool
 }
 
 // NewControllerRuntimeClient returns a KubeClient backed by a controller-runtime client.Client.
-func NewControllerRuntimeClient(kubeClient client.Client) KubeClient {
-	return &controllerRuntimeClient{client: kubeClient}
+func NewControllerRuntimeClient(kubeClient client.Client, options ClientOptions) KubeClient {
+	return &controllerRuntimeClient{client: kubeClient, callTimeout: options.CallTimeout, unindexedTargetLookup: options.UnindexedTargetLookup}
+}
+
+// callContext derives the context of one API call, applying the per-call timeout when configured.
+func (clientAdapter *controllerRuntimeClient) callContext(requestContext context.Context) (context.Context, context.CancelFunc) {
+	if clientAdapter.callTimeout <= 0 {
+		return context.WithCancel(requestContext)
+	}
+
+	return context.WithTimeout(requestContext, clientAdapter.callTimeout)
 }
 
-// GetSourceConfigMap retrieves the source ConfigMap data for reconciliation.
-func (clientAdapter *controllerRuntimeClient) GetSourceConfigMap(namespace, name string) (map[string]string, error) {
-	requestContext := context.Background()
+// GetSourceConfigMap retrieves the source ConfigMap data and binaryData for reconciliation.
+func (clientAdapter *controllerRuntimeClient) GetSourceConfigMap(requestContext context.Context, namespace, name string) (map[string]string, map[string][]byte, error) {
+	requestContext, cancel := clientAdapter.callContext(requestContext)
+	defer cancel()
 
 	var configMap corev1.ConfigMap
 
 	if err := clientAdapter.client.Get(requestContext, types.NamespacedName{Namespace: namespace, Name: name}, &configMap); err != nil {
-		return nil, err
+		return nil, nil, err
+	}
+
+	return copyStringMap(configMap.Data), copyBinaryMap(configMap.BinaryData), nil
+}
+
+// GetSourceAnnotations returns a copy of the source ConfigMap annotations.
+func (clientAdapter *controllerRuntimeClient) GetSourceAnnotations(requestContext context.Context, namespace, name string) (map[string]string, bool, error) {
+	requestContext, cancel := clientAdapter.callContext(requestContext)
+	defer cancel()
+
+	var configMap corev1.ConfigMap
+
+	if err := clientAdapter.client.Get(requestContext, types.NamespacedName{Namespace: namespace, Name: name}, &configMap); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, false, nil
+		}
+
+		return nil, false, err
 	}
 
-	return copyStringMap(configMap.Data), nil
+	return copyStringMap(configMap.Annotations), true, nil
 }
 
 // ListNamespacesBySelector resolves namespace names that satisfy the selector requirements.
-func (clientAdapter *controllerRuntimeClient) ListNamespacesBySelector(matchLabels map[string]string, selectorRequirements []LabelSelectorRequirement) ([]string, error) {
-	requestContext := context.Background()
+func (clientAdapter *controllerRuntimeClient) ListNamespacesBySelector(requestContext context.Context, matchLabels map[string]string, selectorRequirements []LabelSelectorRequirement) ([]string, error) {
+	requestContext, cancel := clientAdapter.callContext(requestContext)
+	defer cancel()
+
+	selector, err := NewLabelSelector(matchLabels, selectorRequirements)
+	if err != nil {
+		return nil, err
+	}
+
+	var namespaces corev1.NamespaceList
+
+	if err := clientAdapter.client.List(requestContext, &namespaces); err != nil {
+		return nil, err
+	}
 
+	var namespaceNames []string
+
+	for _, namespaceItem := range namespaces.Items {
+		if selector.Empty() || selector.Matches(labels.Set(namespaceItem.Labels)) {
+			namespaceNames = append(namespaceNames, namespaceItem.Name)
+		}
+	}
+
+	return namespaceNames, nil
+}
+
+// GetNamespaceMetadata returns copies of the namespace labels and annotations.
+func (clientAdapter *controllerRuntimeClient) GetNamespaceMetadata(requestContext context.Context, name string) (map[string]string, map[string]string, error) {
+	requestContext, cancel := clientAdapter.callContext(requestContext)
+	defer cancel()
+
+	var namespace corev1.Namespace
+
+	if err := clientAdapter.client.Get(requestContext, types.NamespacedName{Name: name}, &namespace); err != nil {
+		return nil, nil, err
+	}
+
+	return copyStringMap(namespace.Labels), copyStringMap(namespace.Annotations), nil
+}
+
+// ListPolicies reads every ConfigPropagationPolicy. Clusters without the policy CRD have no policies.
+func (clientAdapter *controllerRuntimeClient) ListPolicies(requestContext context.Context) ([]core.Policy, error) {
+	requestContext, cancel := clientAdapter.callContext(requestContext)
+	defer cancel()
+
+	var configPropagationPolicies configv1alpha1.ConfigPropagationPolicyList
+
+	if err := clientAdapter.client.List(requestContext, &configPropagationPolicies); err != nil {
+		if meta.IsNoMatchError(err) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	return configPropagationPolicies.Policies(), nil
+}
+
+// NewLabelSelector converts match labels and requirements into a Kubernetes label selector.
+func NewLabelSelector(matchLabels map[string]string, selectorRequirements []LabelSelectorRequirement) (labels.Selector, error) {
 	selector := labels.NewSelector()
 
 	for labelKey, labelValue := range matchLabels {
@@ -66,26 +173,15 @@ func (clientAdapter *controllerRuntimeClient) ListNamespacesBySelector(matchLabe
 		selector = selector.Add(*typedRequirement)
 	}
 
-	var namespaces corev1.NamespaceList
-
-	if err := clientAdapter.client.List(requestContext, &namespaces); err != nil {
-		return nil, err
-	}
-
-	var namespaceNames []string
-
-	for _, namespaceItem := range namespaces.Items {
-		if selector.Empty() || selector.Matches(labels.Set(namespaceItem.Labels)) {
-			namespaceNames = append(namespaceNames, namespaceItem.Name)
-		}
-	}
-
-	return namespaceNames, nil
+	return selector, nil
 }
 
 // UpsertConfigMap creates or updates a target ConfigMap with the provided data and metadata.
-func (clientAdapter *controllerRuntimeClient) UpsertConfigMap(namespace, name string, data map[string]string, labelsMap, annotations map[string]string) error {
-	requestContext := context.Background()
+// Updates only touch the labels and annotations the controller owns, so metadata added by other tools survives,
+// and are sent as a merge patch so concurrent edits to other keys are not overwritten.
+func (clientAdapter *controllerRuntimeClient) UpsertConfigMap(requestContext context.Context, namespace, name string, data map[string]string, binaryData map[string][]byte, labelsMap, annotations map[string]string) error {
+	requestContext, cancel := clientAdapter.callContext(requestContext)
+	defer cancel()
 
 	var existingConfigMap corev1.ConfigMap
 
@@ -99,88 +195,145 @@ func (clientAdapter *controllerRuntimeClient) UpsertConfigMap(namespace, name st
 		configMap.Namespace = namespace
 		configMap.Name = name
 		configMap.Data = copyStringMap(data)
-		configMap.Labels = copyStringMap(labelsMap)
-		configMap.Annotations = copyStringMap(annotations)
+		configMap.BinaryData = copyBinaryMap(binaryData)
+		configMap.Labels, configMap.Annotations = core.MergeOwnedMetadata(nil, nil, labelsMap, annotations)
 
 		return clientAdapter.client.Create(requestContext, &configMap)
 	}
 
-	existingConfigMap.Data = copyStringMap(data)
+	updatedConfigMap := existingConfigMap.DeepCopy()
+	updatedConfigMap.Data = copyStringMap(data)
+	updatedConfigMap.BinaryData = copyBinaryMap(binaryData)
+	updatedConfigMap.Labels, updatedConfigMap.Annotations = core.MergeOwnedMetadata(existingConfigMap.Labels, existingConfigMap.Annotations, labelsMap, annotations)
 
-	if existingConfigMap.Labels == nil {
-		existingConfigMap.Labels = map[string]string{}
-	}
+	return clientAdapter.client.Patch(requestContext, updatedConfigMap, client.MergeFrom(&existingConfigMap))
+}
 
-	if existingConfigMap.Annotations == nil {
-		existingConfigMap.Annotations = map[string]string{}
-	}
+// GetTargetConfigMap returns the current target data, binaryData, metadata, and existence flag.
+func (clientAdapter *controllerRuntimeClient) GetTargetConfigMap(requestContext context.Context, namespace, name string) (map[string]string, map[string][]byte, map[string]string, map[string]string, bool, error) {
+	requestContext, cancel := clientAdapter.callContext(requestContext)
+	defer cancel()
 
-	for key := range existingConfigMap.Labels {
-		delete(existingConfigMap.Labels, key)
-	}
+	var configMap corev1.ConfigMap
+
+	if err := clientAdapter.client.Get(requestContext, types.NamespacedName{Namespace: namespace, Name: name}, &configMap); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil, nil, nil, false, nil
+		}
 
-	for key := range existingConfigMap.Annotations {
-		delete(existingConfigMap.Annotations, key)
+		return nil, nil, nil, nil, false, err
 	}
 
-	for key, value := range labelsMap {
-		existingConfigMap.Labels[key] = value
+	return copyStringMap(configMap.Data), copyBinaryMap(configMap.BinaryData), copyStringMap(configMap.Labels), copyStringMap(configMap.Annotations), true, nil
+}
+
+// ListManagedTargets enumerates managed ConfigMaps written for the source or owned by the owner, whatever their name.
+// Both lookups are indexed cache queries; the client must carry the indexes installed by RegisterTargetIndexes unless
+// UnindexedTargetLookup is set.
+func (clientAdapter *controllerRuntimeClient) ListManagedTargets(requestContext context.Context, source, owner string) ([]ManagedTarget, error) {
+	requestContext, cancel := clientAdapter.callContext(requestContext)
+	defer cancel()
+
+	configMaps, err := clientAdapter.listManagedTargetConfigMaps(requestContext, source, owner)
+	if err != nil {
+		return nil, err
 	}
 
-	for key, value := range annotations {
-		existingConfigMap.Annotations[key] = value
+	seen := map[types.NamespacedName]struct{}{}
+	var targets []ManagedTarget
+
+	for _, configMap := range configMaps {
+		identifier := types.NamespacedName{Namespace: configMap.Namespace, Name: configMap.Name}
+		if _, duplicate := seen[identifier]; duplicate {
+			continue
+		}
+		seen[identifier] = struct{}{}
+
+		targets = append(targets, ManagedTarget{
+			Namespace: configMap.Namespace,
+			Name:      configMap.Name,
+			Owner:     configMap.Annotations[core.OwnerAnnotation],
+			OwnerUID:  configMap.Annotations[core.OwnerUIDAnnotation],
+		})
 	}
 
-	return clientAdapter.client.Update(requestContext, &existingConfigMap)
+	sort.Slice(targets, func(left, right int) bool {
+		if targets[left].Namespace != targets[right].Namespace {
+			return targets[left].Namespace < targets[right].Namespace
+		}
+		return targets[left].Name < targets[right].Name
+	})
+
+	return targets, nil
 }
 
-// GetTargetConfigMap returns the current target data, metadata, and existence flag.
-func (clientAdapter *controllerRuntimeClient) GetTargetConfigMap(namespace, name string) (map[string]string, map[string]string, map[string]string, bool, error) {
-	requestContext := context.Background()
+// listManagedTargetConfigMaps returns the managed ConfigMaps matching the source or the owner, possibly twice.
+func (clientAdapter *controllerRuntimeClient) listManagedTargetConfigMaps(requestContext context.Context, source, owner string) ([]corev1.ConfigMap, error) {
+	if clientAdapter.unindexedTargetLookup {
+		return clientAdapter.listManagedTargetConfigMapsByLabel(requestContext, source, owner)
+	}
 
-	var configMap corev1.ConfigMap
+	var configMaps []corev1.ConfigMap
 
-	if err := clientAdapter.client.Get(requestContext, types.NamespacedName{Namespace: namespace, Name: name}, &configMap); err != nil {
-		if apierrors.IsNotFound(err) {
-			return nil, nil, nil, false, nil
+	for _, lookup := range []struct{ index, value string }{{TargetSourceIndex, source}, {TargetOwnerIndex, owner}} {
+		if lookup.value == "" {
+			continue
 		}
 
-		return nil, nil, nil, false, err
-	}
+		var configMapList corev1.ConfigMapList
 
-	return copyStringMap(configMap.Data), copyStringMap(configMap.Labels), copyStringMap(configMap.Annotations), true, nil
-}
+		if err := clientAdapter.client.List(requestContext, &configMapList, client.MatchingFields{lookup.index: lookup.value}); err != nil {
+			return nil, err
+		}
 
-// ListManagedTargetNamespaces enumerates namespaces with managed ConfigMaps for the source.
-func (clientAdapter *controllerRuntimeClient) ListManagedTargetNamespaces(source string, name string) ([]string, error) {
-	requestContext := context.Background()
+		configMaps = append(configMaps, configMapList.Items...)
+	}
 
-	var configMapList corev1.ConfigMapList
+	return configMaps, nil
+}
 
-	if err := clientAdapter.client.List(requestContext, &configMapList, client.MatchingLabels{core.ManagedLabel: "true"}); err != nil {
+// listManagedTargetConfigMapsByLabel selects the managed ConfigMaps of the source and of the owner by their hash
+// labels, so the API server filters them. Targets written before the owner hash label existed are listed by
+// its absence and matched on their annotations; every target gets the label on its next write. Annotations
+// are compared in every case, as label values are truncated hashes.
+func (clientAdapter *controllerRuntimeClient) listManagedTargetConfigMapsByLabel(requestContext context.Context, source, owner string) ([]corev1.ConfigMap, error) {
+	legacySelector, err := NewLabelSelector(map[string]string{core.ManagedLabel: "true"}, []LabelSelectorRequirement{{Key: core.OwnerHashLabel, Operator: "DoesNotExist"}})
+	if err != nil {
 		return nil, err
 	}
 
-	var namespaces []string
+	lookups := []client.ListOption{client.MatchingLabelsSelector{Selector: legacySelector}}
+	if source != "" {
+		lookups = append(lookups, client.MatchingLabels{core.ManagedLabel: "true", core.SourceHashLabel: core.SourceLabelValue(source)})
+	}
+	if owner != "" {
+		lookups = append(lookups, client.MatchingLabels{core.ManagedLabel: "true", core.OwnerHashLabel: core.SourceLabelValue(owner)})
+	}
 
-	for _, configMap := range configMapList.Items {
-		if configMap.Name != name {
-			continue
-		}
+	var configMaps []corev1.ConfigMap
+	for _, lookup := range lookups {
+		var configMapList corev1.ConfigMapList
 
-		if configMap.Annotations[core.SourceAnnotation] != source {
-			continue
+		if err := clientAdapter.client.List(requestContext, &configMapList, lookup); err != nil {
+			return nil, err
 		}
 
-		namespaces = append(namespaces, configMap.Namespace)
+		for _, configMap := range configMapList.Items {
+			matchesSource := source != "" && configMap.Annotations[core.SourceAnnotation] == source
+			matchesOwner := owner != "" && configMap.Annotations[core.OwnerAnnotation] == owner
+			if matchesSource || matchesOwner {
+				configMaps = append(configMaps, configMap)
+			}
+		}
 	}
 
-	return namespaces, nil
+	return configMaps, nil
 }
 
 // DeleteConfigMap removes a target ConfigMap, ignoring not found errors.
-func (clientAdapter *controllerRuntimeClient) DeleteConfigMap(namespace, name string) error {
-	requestContext := context.Background()
+func (clientAdapter *controllerRuntimeClient) DeleteConfigMap(requestContext context.Context, namespace, name string) error {
+	requestContext, cancel := clientAdapter.callContext(requestContext)
+	defer cancel()
 
 	configMap := corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name}}
 
@@ -188,8 +341,9 @@ func (clientAdapter *controllerRuntimeClient) DeleteConfigMap(namespace, name st
 }
 
 // UpdateConfigMapMetadata rewrites the labels and annotations for a target ConfigMap.
-func (clientAdapter *controllerRuntimeClient) UpdateConfigMapMetadata(namespace, name string, labelsMap, annotations map[string]string) error {
-	requestContext := context.Background()
+func (clientAdapter *controllerRuntimeClient) UpdateConfigMapMetadata(requestContext context.Context, namespace, name string, labelsMap, annotations map[string]string) error {
+	requestContext, cancel := clientAdapter.callContext(requestContext)
+	defer cancel()
 
 	var configMap corev1.ConfigMap
 
@@ -233,3 +387,18 @@ func copyStringMap(source map[string]string) map[string]string {
 
 	return copied
 }
+
+// copyBinaryMap duplicates a binary map, including each value, so callers can mutate the result safely.
+func copyBinaryMap(source map[string][]byte) map[string][]byte {
+	if len(source) == 0 {
+		return nil
+	}
+
+	copied := make(map[string][]byte, len(source))
+
+	for key, value := range source {
+		copied[key] = append([]byte(nil), value...)
+	}
+
+	return copied
+}
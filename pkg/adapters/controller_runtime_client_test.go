@@ -0,0 +1,259 @@
+package adapters
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	configv1alpha1 "configpropagation/pkg/api/v1alpha1"
+	"configpropagation/pkg/core"
+)
+
+// kubeClientImplementations builds every KubeClient implementation over its own fake API server holding the objects,
+// so each behavioral test runs against both the update-based and the server-side apply adapter.
+func kubeClientImplementations(t *testing.T, objects ...client.Object) map[string]KubeClient {
+	t.Helper()
+
+	updateObjects := make([]client.Object, 0, len(objects))
+	applyObjects := make([]client.Object, 0, len(objects))
+	unindexedObjects := make([]client.Object, 0, len(objects))
+	for _, object := range objects {
+		updateObjects = append(updateObjects, object.DeepCopyObject().(client.Object))
+		applyObjects = append(applyObjects, object.DeepCopyObject().(client.Object))
+		unindexedObjects = append(unindexedObjects, object.DeepCopyObject().(client.Object))
+	}
+
+	return map[string]KubeClient{
+		"update": NewControllerRuntimeClient(withTargetIndexes(fake.NewClientBuilder()).WithScheme(clientgoscheme.Scheme).WithObjects(updateObjects...).Build(), ClientOptions{}),
+		"apply":  NewServerSideApplyClient(newApplyFakeClient(t, applyObjects...), ClientOptions{}),
+		// Without indexes, as a CLI talking to the API server directly sees the cluster.
+		"unindexed": NewControllerRuntimeClient(fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).WithObjects(unindexedObjects...).Build(), ClientOptions{UnindexedTargetLookup: true}),
+	}
+}
+
+func TestKubeClientRoundTripsBinaryData(t *testing.T) {
+	source := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "src", Name: "cfg"},
+		Data:       map[string]string{"a": "1"},
+		BinaryData: map[string][]byte{"ca.der": {0x30, 0x82}},
+	}
+
+	for implementation, clientAdapter := range kubeClientImplementations(t, source) {
+		t.Run(implementation, func(t *testing.T) {
+			data, binaryData, err := clientAdapter.GetSourceConfigMap(context.Background(), "src", "cfg")
+			if err != nil {
+				t.Fatalf("get source: %v", err)
+			}
+			if !reflect.DeepEqual(data, source.Data) || !reflect.DeepEqual(binaryData, source.BinaryData) {
+				t.Fatalf("unexpected source content %+v %+v", data, binaryData)
+			}
+
+			if err := clientAdapter.UpsertConfigMap(context.Background(), "team", "cfg", data, binaryData, nil, nil); err != nil {
+				t.Fatalf("create target: %v", err)
+			}
+
+			updatedBinary := map[string][]byte{"ca.der": {0x30, 0x83}}
+			if err := clientAdapter.UpsertConfigMap(context.Background(), "team", "cfg", data, updatedBinary, nil, nil); err != nil {
+				t.Fatalf("update target: %v", err)
+			}
+
+			targetData, targetBinaryData, _, _, found, err := clientAdapter.GetTargetConfigMap(context.Background(), "team", "cfg")
+			if err != nil || !found {
+				t.Fatalf("get target: found=%v err=%v", found, err)
+			}
+			if !reflect.DeepEqual(targetData, data) || !reflect.DeepEqual(targetBinaryData, updatedBinary) {
+				t.Fatalf("unexpected target content %+v %+v", targetData, targetBinaryData)
+			}
+
+			if err := clientAdapter.UpsertConfigMap(context.Background(), "team", "cfg", data, nil, nil, nil); err != nil {
+				t.Fatalf("clear binaryData: %v", err)
+			}
+			if _, targetBinaryData, _, _, _, _ := clientAdapter.GetTargetConfigMap(context.Background(), "team", "cfg"); len(targetBinaryData) != 0 {
+				t.Fatalf("expected binaryData removed from target, got %+v", targetBinaryData)
+			}
+		})
+	}
+}
+
+func TestKubeClientGetNamespaceMetadata(t *testing.T) {
+	namespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+		Name:        "payments",
+		Labels:      map[string]string{"tier": "gold"},
+		Annotations: map[string]string{"owner": "team-a"},
+	}}
+
+	for implementation, clientAdapter := range kubeClientImplementations(t, namespace) {
+		t.Run(implementation, func(t *testing.T) {
+			namespaceLabels, namespaceAnnotations, err := clientAdapter.GetNamespaceMetadata(context.Background(), "payments")
+			if err != nil || !reflect.DeepEqual(namespaceLabels, namespace.Labels) || !reflect.DeepEqual(namespaceAnnotations, namespace.Annotations) {
+				t.Fatalf("unexpected metadata %+v %+v %v", namespaceLabels, namespaceAnnotations, err)
+			}
+
+			if _, _, err := clientAdapter.GetNamespaceMetadata(context.Background(), "missing"); err == nil {
+				t.Fatalf("expected an error for a missing namespace")
+			}
+		})
+	}
+}
+
+func TestKubeClientUpsertKeepsForeignMetadata(t *testing.T) {
+	for implementation, clientAdapter := range kubeClientImplementations(t) {
+		t.Run(implementation, func(t *testing.T) {
+			managedLabels := map[string]string{core.ManagedLabel: "true"}
+			if err := clientAdapter.UpsertConfigMap(context.Background(), "team", "cfg", map[string]string{"a": "1"}, nil, managedLabels, map[string]string{core.HashAnnotation: "h1", core.SourcesAnnotation: "x/y"}); err != nil {
+				t.Fatalf("create target: %v", err)
+			}
+
+			// Simulate Argo CD and Reloader annotating the copy.
+			_, _, liveLabels, liveAnnotations, _, _ := clientAdapter.GetTargetConfigMap(context.Background(), "team", "cfg")
+			liveLabels["argocd.argoproj.io/instance"] = "apps"
+			liveAnnotations["reloader.stakater.com/match"] = "true"
+			if err := clientAdapter.UpdateConfigMapMetadata(context.Background(), "team", "cfg", liveLabels, liveAnnotations); err != nil {
+				t.Fatalf("annotate target: %v", err)
+			}
+
+			if err := clientAdapter.UpsertConfigMap(context.Background(), "team", "cfg", map[string]string{"a": "2"}, nil, managedLabels, map[string]string{core.HashAnnotation: "h2"}); err != nil {
+				t.Fatalf("update target: %v", err)
+			}
+
+			targetData, _, targetLabels, targetAnnotations, _, err := clientAdapter.GetTargetConfigMap(context.Background(), "team", "cfg")
+			if err != nil || targetData["a"] != "2" {
+				t.Fatalf("expected data update, got %+v %v", targetData, err)
+			}
+
+			if targetLabels["argocd.argoproj.io/instance"] != "apps" || targetLabels[core.ManagedLabel] != "true" {
+				t.Fatalf("unexpected labels %+v", targetLabels)
+			}
+
+			if targetAnnotations["reloader.stakater.com/match"] != "true" || targetAnnotations[core.HashAnnotation] != "h2" {
+				t.Fatalf("unexpected annotations %+v", targetAnnotations)
+			}
+
+			if _, stale := targetAnnotations[core.SourcesAnnotation]; stale {
+				t.Fatalf("expected dropped managed annotation to be removed, got %+v", targetAnnotations)
+			}
+		})
+	}
+}
+
+// withTargetIndexes registers the ConfigMap indexes ListManagedTargets queries, as RegisterTargetIndexes does on a manager.
+func withTargetIndexes(builder *fake.ClientBuilder) *fake.ClientBuilder {
+	return builder.
+		WithIndex(&corev1.ConfigMap{}, TargetSourceIndex, targetSourceIndexValues).
+		WithIndex(&corev1.ConfigMap{}, TargetOwnerIndex, targetOwnerIndexValues)
+}
+
+func TestKubeClientManagedTargetsAndDelete(t *testing.T) {
+	objects := []client.Object{
+		// Written by default/cp for its previous sourceRef.
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "team-b", Name: "old", Labels: map[string]string{core.ManagedLabel: "true"}, Annotations: map[string]string{core.SourceAnnotation: "platform/old", core.OwnerAnnotation: "default/cp"}}},
+		// Written from the same source by another ConfigPropagation.
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "team-c", Name: "app", Labels: map[string]string{core.ManagedLabel: "true"}, Annotations: map[string]string{core.SourceAnnotation: "platform/app", core.OwnerAnnotation: "other/cp"}}},
+		// Detached copies are no longer managed.
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "team-d", Name: "app", Annotations: map[string]string{core.SourceAnnotation: "platform/app", core.OwnerAnnotation: "default/cp"}}},
+	}
+
+	for implementation, clientAdapter := range kubeClientImplementations(t, objects...) {
+		t.Run(implementation, func(t *testing.T) {
+			managedLabels := map[string]string{core.ManagedLabel: "true"}
+			managedAnnotations := map[string]string{core.SourceAnnotation: "platform/app", core.OwnerAnnotation: "default/cp", core.OwnerUIDAnnotation: "uid-1"}
+			if err := clientAdapter.UpsertConfigMap(context.Background(), "team-a", "app", map[string]string{"a": "1"}, nil, managedLabels, managedAnnotations); err != nil {
+				t.Fatalf("create target: %v", err)
+			}
+
+			targets, err := clientAdapter.ListManagedTargets(context.Background(), "platform/app", "default/cp")
+			want := []ManagedTarget{
+				{Namespace: "team-a", Name: "app", Owner: "default/cp", OwnerUID: "uid-1"},
+				{Namespace: "team-b", Name: "old", Owner: "default/cp"},
+				{Namespace: "team-c", Name: "app", Owner: "other/cp"},
+			}
+			if err != nil || !reflect.DeepEqual(targets, want) {
+				t.Fatalf("unexpected managed targets %+v %v", targets, err)
+			}
+
+			if targets, _ := clientAdapter.ListManagedTargets(context.Background(), "platform/app", ""); len(targets) != 2 {
+				t.Fatalf("expected a source-only lookup to skip other sources, got %+v", targets)
+			}
+
+			if err := clientAdapter.DeleteConfigMap(context.Background(), "team-a", "app"); err != nil {
+				t.Fatalf("delete target: %v", err)
+			}
+			if err := clientAdapter.DeleteConfigMap(context.Background(), "team-a", "app"); err != nil {
+				t.Fatalf("deleting a missing target must succeed: %v", err)
+			}
+
+			if _, _, _, _, found, _ := clientAdapter.GetTargetConfigMap(context.Background(), "team-a", "app"); found {
+				t.Fatalf("expected target to be deleted")
+			}
+		})
+	}
+}
+
+func TestKubeClientBoundsEachCallByTheCallTimeout(t *testing.T) {
+	// The interceptor stands in for an API server that never answers: it returns only once the call's context ends.
+	hangingGet := func(requestContext context.Context, _ client.WithWatch, _ client.ObjectKey, _ client.Object, _ ...client.GetOption) error {
+		<-requestContext.Done()
+		return requestContext.Err()
+	}
+	hangingClient := fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).WithInterceptorFuncs(interceptor.Funcs{Get: hangingGet}).Build()
+
+	clientAdapter := NewControllerRuntimeClient(hangingClient, ClientOptions{CallTimeout: 10 * time.Millisecond})
+
+	_, _, _, _, _, err := clientAdapter.GetTargetConfigMap(context.Background(), "team", "cfg")
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected the call to time out, got %v", err)
+	}
+	if reason := ClassifyError(err); reason != core.ReasonTransientError {
+		t.Fatalf("expected a timed-out call to be transient, got %q", reason)
+	}
+
+	cancelledContext, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	unboundedAdapter := NewControllerRuntimeClient(hangingClient, ClientOptions{})
+	if _, _, err := unboundedAdapter.GetSourceConfigMap(cancelledContext, "src", "cfg"); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected the caller's cancellation to reach the API call, got %v", err)
+	}
+}
+
+func TestListPoliciesToleratesMissingPolicyCRD(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := configv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatalf("add configpropagation scheme: %v", err)
+	}
+
+	maxTargets := int32(10)
+	policy := &configv1alpha1.ConfigPropagationPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "platform"},
+		Spec:       configv1alpha1.ConfigPropagationPolicySpec{MaxTargets: &maxTargets},
+	}
+
+	lister := NewControllerRuntimeClient(fake.NewClientBuilder().WithScheme(scheme).WithObjects(policy).Build(), ClientOptions{}).(PolicyLister)
+
+	policies, err := lister.ListPolicies(context.Background())
+	if err != nil || len(policies) != 1 || policies[0].Name != "platform" || *policies[0].Spec.MaxTargets != 10 {
+		t.Fatalf("expected the platform policy, got %+v, %v", policies, err)
+	}
+
+	withoutCRD := fake.NewClientBuilder().WithScheme(scheme).WithInterceptorFuncs(interceptor.Funcs{
+		List: func(context.Context, client.WithWatch, client.ObjectList, ...client.ListOption) error {
+			return &meta.NoKindMatchError{GroupKind: configv1alpha1.GroupVersion.WithKind("ConfigPropagationPolicy").GroupKind()}
+		},
+	}).Build()
+
+	policies, err = NewControllerRuntimeClient(withoutCRD, ClientOptions{}).(PolicyLister).ListPolicies(context.Background())
+	if err != nil || policies != nil {
+		t.Fatalf("expected no policies without the CRD, got %+v, %v", policies, err)
+	}
+}
@@ -0,0 +1,46 @@
+package adapters
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"configpropagation/pkg/core"
+)
+
+// ClassifyError maps an API error to the out-of-sync reason reported for the affected target.
+// Anything that is not a recognised permanent failure is treated as transient.
+func ClassifyError(err error) string {
+	switch {
+	case err == nil:
+		return ""
+	case errors.Is(err, context.DeadlineExceeded), apierrors.IsTimeout(err), apierrors.IsServerTimeout(err):
+		// Calls cut short by the per-call timeout or an overloaded API server are retried with backoff.
+		return core.ReasonTransientError
+	case apierrors.IsForbidden(err), apierrors.IsUnauthorized(err):
+		return core.ReasonForbidden
+	case apierrors.IsNotFound(err):
+		// Writes into a namespace that was deleted between listing and upserting surface as NotFound.
+		return core.ReasonNamespaceNotFound
+	case apierrors.IsConflict(err), apierrors.IsAlreadyExists(err):
+		return core.ReasonConflict
+	case apierrors.IsRequestEntityTooLargeError(err):
+		return core.ReasonTooLarge
+	case apierrors.IsInvalid(err) && strings.Contains(err.Error(), "Too long"):
+		return core.ReasonTooLarge
+	default:
+		return core.ReasonTransientError
+	}
+}
+
+// IsFieldManagerConflict reports whether a server-side apply failed because another field manager owns a field.
+func IsFieldManagerConflict(err error) bool {
+	if !apierrors.IsConflict(err) {
+		return false
+	}
+
+	return apierrors.HasStatusCause(err, metav1.CauseTypeFieldManagerConflict)
+}
@@ -0,0 +1,57 @@
+package adapters
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	"configpropagation/pkg/core"
+)
+
+func TestClassifyError(t *testing.T) {
+	configMaps := schema.GroupResource{Resource: "configmaps"}
+	cases := map[string]struct {
+		err  error
+		want string
+	}{
+		"nil":           {err: nil, want: ""},
+		"forbidden":     {err: apierrors.NewForbidden(configMaps, "cfg", fmt.Errorf("rbac")), want: core.ReasonForbidden},
+		"not found":     {err: apierrors.NewNotFound(schema.GroupResource{Resource: "namespaces"}, "gone"), want: core.ReasonNamespaceNotFound},
+		"conflict":      {err: apierrors.NewConflict(configMaps, "cfg", fmt.Errorf("stale")), want: core.ReasonConflict},
+		"entity large":  {err: apierrors.NewRequestEntityTooLargeError("limit"), want: core.ReasonTooLarge},
+		"invalid large": {err: apierrors.NewInvalid(schema.GroupKind{Kind: "ConfigMap"}, "cfg", field.ErrorList{field.TooLong(field.NewPath(""), "", 1048576)}), want: core.ReasonTooLarge},
+		"other invalid": {err: apierrors.NewInvalid(schema.GroupKind{Kind: "ConfigMap"}, "cfg", field.ErrorList{field.Required(field.NewPath("data"), "")}), want: core.ReasonTransientError},
+		"plain":         {err: fmt.Errorf("connection reset"), want: core.ReasonTransientError},
+		"call deadline": {err: fmt.Errorf("get cfg: %w", context.DeadlineExceeded), want: core.ReasonTransientError},
+		"api timeout":   {err: apierrors.NewTimeoutError("slow", 1), want: core.ReasonTransientError},
+		"server busy":   {err: apierrors.NewServerTimeout(configMaps, "update", 1), want: core.ReasonTransientError},
+	}
+
+	for name, testCase := range cases {
+		if got := ClassifyError(testCase.err); got != testCase.want {
+			t.Fatalf("%s: want %q got %q", name, testCase.want, got)
+		}
+	}
+}
+
+func TestIsFieldManagerConflict(t *testing.T) {
+	applyConflict := apierrors.NewApplyConflict([]metav1.StatusCause{{Type: metav1.CauseTypeFieldManagerConflict, Field: ".data.log"}}, "conflict")
+	staleConflict := apierrors.NewConflict(schema.GroupResource{Resource: "configmaps"}, "cfg", fmt.Errorf("stale"))
+
+	if !IsFieldManagerConflict(applyConflict) {
+		t.Fatalf("expected apply conflict to be recognised")
+	}
+
+	if IsFieldManagerConflict(staleConflict) || IsFieldManagerConflict(nil) {
+		t.Fatalf("expected only field manager conflicts to be recognised")
+	}
+
+	if ClassifyError(applyConflict) != core.ReasonConflict {
+		t.Fatalf("expected forced apply conflicts to classify as Conflict")
+	}
+}
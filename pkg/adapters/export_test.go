@@ -0,0 +1,4 @@
+package adapters
+
+// NewApplyFakeClient lets the reconciler tests in package adapters_test write through the applyServer.
+var NewApplyFakeClient = newApplyFakeClient
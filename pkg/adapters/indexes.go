@@ -0,0 +1,56 @@
+package adapters
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"configpropagation/pkg/core"
+)
+
+const (
+	// TargetSourceIndex maps the source annotation of managed ConfigMaps to the targets written from that source.
+	TargetSourceIndex = "metadata.annotations.source"
+	// TargetOwnerIndex maps the owner annotation of managed ConfigMaps to the targets written by that ConfigPropagation.
+	TargetOwnerIndex = "metadata.annotations.owner"
+)
+
+// RegisterTargetIndexes installs the ConfigMap field indexers ListManagedTargets queries, so finding the targets
+// of one source is a cache lookup instead of a scan over every managed ConfigMap in the cluster.
+func RegisterTargetIndexes(requestContext context.Context, indexer client.FieldIndexer) error {
+	if err := indexer.IndexField(requestContext, &corev1.ConfigMap{}, TargetSourceIndex, targetSourceIndexValues); err != nil {
+		return fmt.Errorf("index %s: %w", TargetSourceIndex, err)
+	}
+
+	if err := indexer.IndexField(requestContext, &corev1.ConfigMap{}, TargetOwnerIndex, targetOwnerIndexValues); err != nil {
+		return fmt.Errorf("index %s: %w", TargetOwnerIndex, err)
+	}
+
+	return nil
+}
+
+// targetSourceIndexValues returns the source of a managed ConfigMap; unmanaged ConfigMaps are not indexed.
+func targetSourceIndexValues(object client.Object) []string {
+	return managedAnnotationIndexValues(object, core.SourceAnnotation)
+}
+
+// targetOwnerIndexValues returns the owner of a managed ConfigMap; unmanaged ConfigMaps are not indexed.
+func targetOwnerIndexValues(object client.Object) []string {
+	return managedAnnotationIndexValues(object, core.OwnerAnnotation)
+}
+
+// managedAnnotationIndexValues indexes a managed ConfigMap by one of its annotations.
+func managedAnnotationIndexValues(object client.Object, annotation string) []string {
+	if object.GetLabels()[core.ManagedLabel] != "true" {
+		return nil
+	}
+
+	value := object.GetAnnotations()[annotation]
+	if value == "" {
+		return nil
+	}
+
+	return []string{value}
+}
@@ -0,0 +1,203 @@
+package adapters
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	"configpropagation/pkg/core"
+)
+
+// indexedConfigMapReader serves ConfigMap lists from a client-go indexer, the store behind the manager's cache,
+// so benchmarks measure indexed lookups instead of the fake client's full scan. Only List is implemented.
+type indexedConfigMapReader struct {
+	client.Client
+	indexer cache.Indexer
+}
+
+// newIndexedConfigMapReader stores the ConfigMaps under the same indexes RegisterTargetIndexes installs.
+func newIndexedConfigMapReader(b *testing.B, configMaps []*corev1.ConfigMap) *indexedConfigMapReader {
+	b.Helper()
+
+	indexFunc := func(indexValues client.IndexerFunc) cache.IndexFunc {
+		return func(object interface{}) ([]string, error) { return indexValues(object.(client.Object)), nil }
+	}
+
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{
+		TargetSourceIndex: indexFunc(targetSourceIndexValues),
+		TargetOwnerIndex:  indexFunc(targetOwnerIndexValues),
+	})
+
+	for _, configMap := range configMaps {
+		if err := indexer.Add(configMap); err != nil {
+			b.Fatalf("index %s/%s: %v", configMap.Namespace, configMap.Name, err)
+		}
+	}
+
+	return &indexedConfigMapReader{indexer: indexer}
+}
+
+func (reader *indexedConfigMapReader) List(_ context.Context, list client.ObjectList, options ...client.ListOption) error {
+	listOptions := (&client.ListOptions{}).ApplyOptions(options)
+	configMapList := list.(*corev1.ConfigMapList)
+
+	objects := reader.indexer.List()
+	if listOptions.FieldSelector != nil {
+		requirement := listOptions.FieldSelector.Requirements()[0]
+
+		var err error
+		if objects, err = reader.indexer.ByIndex(requirement.Field, requirement.Value); err != nil {
+			return err
+		}
+	}
+
+	configMapList.Items = configMapList.Items[:0]
+	for _, object := range objects {
+		configMap := object.(*corev1.ConfigMap)
+		if listOptions.LabelSelector != nil && !listOptions.LabelSelector.Matches(labels.Set(configMap.Labels)) {
+			continue
+		}
+
+		// The manager's cache deep-copies every listed object the same way.
+		configMapList.Items = append(configMapList.Items, *configMap.DeepCopy())
+	}
+
+	return nil
+}
+
+// listManagedTargetsByScan is the previous lookup: list every managed ConfigMap and filter by source in Go.
+func listManagedTargetsByScan(reader client.Reader, source string) ([]ManagedTarget, error) {
+	var configMapList corev1.ConfigMapList
+	if err := reader.List(context.Background(), &configMapList, client.MatchingLabels{core.ManagedLabel: "true"}); err != nil {
+		return nil, err
+	}
+
+	var targets []ManagedTarget
+	for _, configMap := range configMapList.Items {
+		if configMap.Annotations[core.SourceAnnotation] == source {
+			targets = append(targets, ManagedTarget{Namespace: configMap.Namespace, Name: configMap.Name, Owner: configMap.Annotations[core.OwnerAnnotation]})
+		}
+	}
+
+	return targets, nil
+}
+
+func TestTargetIndexValuesSkipUnmanagedConfigMaps(t *testing.T) {
+	managed := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{core.ManagedLabel: "true"}, Annotations: map[string]string{core.SourceAnnotation: "platform/app", core.OwnerAnnotation: "default/cp"}}}
+	if values := targetSourceIndexValues(managed); len(values) != 1 || values[0] != "platform/app" {
+		t.Fatalf("unexpected source index values %v", values)
+	}
+	if values := targetOwnerIndexValues(managed); len(values) != 1 || values[0] != "default/cp" {
+		t.Fatalf("unexpected owner index values %v", values)
+	}
+
+	detached := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Annotations: managed.Annotations}}
+	if values := targetSourceIndexValues(detached); values != nil {
+		t.Fatalf("expected unmanaged ConfigMaps to stay out of the index, got %v", values)
+	}
+}
+
+func TestUnindexedLookupSelectsTargetsByHashLabels(t *testing.T) {
+	target := func(namespace, source, owner string, labelled bool) *corev1.ConfigMap {
+		configMap := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{
+			Namespace:   namespace,
+			Name:        "app",
+			Labels:      map[string]string{core.ManagedLabel: "true"},
+			Annotations: map[string]string{core.SourceAnnotation: source, core.OwnerAnnotation: owner},
+		}}
+		if labelled {
+			configMap.Labels[core.SourceHashLabel] = core.SourceLabelValue(source)
+			configMap.Labels[core.OwnerHashLabel] = core.SourceLabelValue(owner)
+		}
+		return configMap
+	}
+
+	objects := []client.Object{
+		target("team-a", "platform/app", "default/cp", true),
+		// Written by default/cp for its previous sourceRef.
+		target("team-b", "platform/old", "default/cp", true),
+		// Written before the hash labels existed.
+		target("team-c", "platform/app", "other/cp", false),
+		target("team-d", "platform/unrelated", "other/cp", true),
+	}
+
+	var listed []string
+	kubeClient := fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).WithObjects(objects...).WithInterceptorFuncs(interceptor.Funcs{
+		List: func(requestContext context.Context, kubeClient client.WithWatch, list client.ObjectList, options ...client.ListOption) error {
+			if err := kubeClient.List(requestContext, list, options...); err != nil {
+				return err
+			}
+			for _, configMap := range list.(*corev1.ConfigMapList).Items {
+				listed = append(listed, configMap.Namespace)
+			}
+			return nil
+		},
+	}).Build()
+
+	clientAdapter := NewControllerRuntimeClient(kubeClient, ClientOptions{UnindexedTargetLookup: true})
+	targets, err := clientAdapter.ListManagedTargets(context.Background(), "platform/app", "default/cp")
+	want := []ManagedTarget{
+		{Namespace: "team-a", Name: "app", Owner: "default/cp"},
+		{Namespace: "team-b", Name: "app", Owner: "default/cp"},
+		{Namespace: "team-c", Name: "app", Owner: "other/cp"},
+	}
+	if err != nil || !reflect.DeepEqual(targets, want) {
+		t.Fatalf("unexpected managed targets %+v %v", targets, err)
+	}
+
+	for _, namespace := range listed {
+		if namespace == "team-d" {
+			t.Fatalf("expected the API server to filter out targets of other sources and owners, listed %v", listed)
+		}
+	}
+}
+
+// BenchmarkListManagedTargets looks up the 50 targets of one source among 5000 managed ConfigMaps
+// written from 100 sources.
+func BenchmarkListManagedTargets(b *testing.B) {
+	const sources, namespaces = 100, 50
+
+	configMaps := make([]*corev1.ConfigMap, 0, sources*namespaces)
+	for sourceIndex := 0; sourceIndex < sources; sourceIndex++ {
+		for namespaceIndex := 0; namespaceIndex < namespaces; namespaceIndex++ {
+			configMaps = append(configMaps, &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace:   fmt.Sprintf("team-%d", namespaceIndex),
+					Name:        fmt.Sprintf("config-%d", sourceIndex),
+					Labels:      map[string]string{core.ManagedLabel: "true"},
+					Annotations: map[string]string{core.SourceAnnotation: fmt.Sprintf("platform/config-%d", sourceIndex), core.OwnerAnnotation: fmt.Sprintf("platform/cp-%d", sourceIndex)},
+				},
+				Data: map[string]string{"settings.yaml": "log: info\nreplicas: 3\n"},
+			})
+		}
+	}
+
+	reader := newIndexedConfigMapReader(b, configMaps)
+	clientAdapter := NewControllerRuntimeClient(reader, ClientOptions{})
+
+	b.Run("scan", func(b *testing.B) {
+		for iteration := 0; iteration < b.N; iteration++ {
+			if targets, err := listManagedTargetsByScan(reader, "platform/config-42"); err != nil || len(targets) != namespaces {
+				b.Fatalf("unexpected result: %d targets, %v", len(targets), err)
+			}
+		}
+	})
+
+	b.Run("indexed", func(b *testing.B) {
+		for iteration := 0; iteration < b.N; iteration++ {
+			if targets, err := clientAdapter.ListManagedTargets(context.Background(), "platform/config-42", "platform/cp-42"); err != nil || len(targets) != namespaces {
+				b.Fatalf("unexpected result: %d targets, %v", len(targets), err)
+			}
+		}
+	})
+}
@@ -1,22 +1,61 @@
 package adapters
 
+import (
+	"context"
+
+	"configpropagation/pkg/core"
+)
+
 // KubeClient defines the minimal interactions the reconciler needs.
+// Every call honors the cancellation and deadline of its context.
 type KubeClient interface {
-	// GetSourceConfigMap returns the data from the source ConfigMap or nil if not found.
-	GetSourceConfigMap(namespace, name string) (map[string]string, error)
+	// GetSourceConfigMap returns the data and binaryData from the source ConfigMap or nil if not found.
+	GetSourceConfigMap(requestContext context.Context, namespace, name string) (data map[string]string, binaryData map[string][]byte, err error)
 	// ListNamespacesBySelector returns namespaces names matching the given selector.
-	ListNamespacesBySelector(matchLabels map[string]string, exprs []LabelSelectorRequirement) ([]string, error)
+	ListNamespacesBySelector(requestContext context.Context, matchLabels map[string]string, exprs []LabelSelectorRequirement) ([]string, error)
+	// GetNamespaceMetadata returns the labels and annotations of a namespace, used to render per-namespace templates.
+	GetNamespaceMetadata(requestContext context.Context, name string) (labels map[string]string, annotations map[string]string, err error)
 	// UpsertConfigMap creates or updates the target ConfigMap with given data and metadata.
-	UpsertConfigMap(namespace, name string, data map[string]string, labels, annotations map[string]string) error
+	UpsertConfigMap(requestContext context.Context, namespace, name string, data map[string]string, binaryData map[string][]byte, labels, annotations map[string]string) error
 	// GetTargetConfigMap returns existing target metadata for drift detection.
 	// found=false indicates it does not exist.
-	GetTargetConfigMap(namespace, name string) (data map[string]string, labels map[string]string, annotations map[string]string, found bool, err error)
-	// ListManagedTargetNamespaces returns namespaces of targets managed for a given source (ns/name string) and configmap name.
-	ListManagedTargetNamespaces(source string, name string) ([]string, error)
+	GetTargetConfigMap(requestContext context.Context, namespace, name string) (data map[string]string, binaryData map[string][]byte, labels map[string]string, annotations map[string]string, found bool, err error)
+	// ListManagedTargets returns the managed target ConfigMaps written for a given source, or owned by a given
+	// ConfigPropagation (both ns/name strings), so targets left behind by a sourceRef change are found too.
+	ListManagedTargets(requestContext context.Context, source, owner string) ([]ManagedTarget, error)
 	// DeleteConfigMap deletes a target ConfigMap.
-	DeleteConfigMap(namespace, name string) error
+	DeleteConfigMap(requestContext context.Context, namespace, name string) error
 	// UpdateConfigMapMetadata updates labels/annotations on a target (used to detach).
-	UpdateConfigMapMetadata(namespace, name string, labels, annotations map[string]string) error
+	UpdateConfigMapMetadata(requestContext context.Context, namespace, name string, labels, annotations map[string]string) error
+}
+
+// ConfigMapApplier is implemented by adapters that write with server-side apply.
+// With force=false, fields owned by another field manager are not taken over; the write fails with an
+// error for which IsFieldManagerConflict holds.
+type ConfigMapApplier interface {
+	ApplyConfigMap(requestContext context.Context, namespace, name string, data map[string]string, binaryData map[string][]byte, labels, annotations map[string]string, force bool) error
+}
+
+// PolicyLister is implemented by adapters that can read the cluster's ConfigPropagationPolicies.
+type PolicyLister interface {
+	// ListPolicies returns every ConfigPropagationPolicy, or none when the policy CRD is not installed.
+	ListPolicies(requestContext context.Context) ([]core.Policy, error)
+}
+
+// SourceMetadataReader is implemented by adapters that can read the annotations of a source ConfigMap, so
+// its sharing opt-in is checked before its data is read.
+type SourceMetadataReader interface {
+	// GetSourceAnnotations returns the annotations of the ConfigMap; found=false indicates it does not exist.
+	GetSourceAnnotations(requestContext context.Context, namespace, name string) (annotations map[string]string, found bool, err error)
+}
+
+// ManagedTarget identifies a managed target ConfigMap and the ConfigPropagation that wrote it.
+// Owner is empty for targets written before ownership was recorded.
+type ManagedTarget struct {
+	Namespace string
+	Name      string
+	Owner     string
+	OwnerUID  string
 }
 
 // LabelSelectorRequirement mirrors a subset of core.LabelSelectorReq to avoid import cycles.
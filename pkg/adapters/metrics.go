@@ -5,6 +5,8 @@ import (
 
 	"github.com/prometheus/client_golang/prometheus"
 	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	"configpropagation/pkg/core"
 )
 
 const (
@@ -18,12 +20,16 @@ const (
 type MetricsRecorder interface {
 	// AddPropagations increments the propagation counter for the provided action.
 	AddPropagations(action string, count int)
-	// ObserveTargets records the most recent total and out-of-sync counts.
-	ObserveTargets(total, outOfSync int)
+	// ObserveTargets records the most recent total and out-of-sync counts of one ConfigPropagation.
+	ObserveTargets(name core.NamespacedName, total, outOfSync int)
+	// ForgetTargets drops the target counts of a ConfigPropagation that no longer exists.
+	ForgetTargets(name core.NamespacedName)
 	// ObserveReconcileDuration records the reconciliation duration.
 	ObserveReconcileDuration(duration time.Duration)
 	// IncError increments the error counter for the provided stage.
 	IncError(stage string)
+	// IncDrift increments the counter of targets whose live data diverged from the recorded hash.
+	IncDrift()
 }
 
 // NewNoopMetricsRecorder returns a MetricsRecorder that performs no-ops.
@@ -37,7 +43,10 @@ type noopMetricsRecorder struct{}
 func (noopMetricsRecorder) AddPropagations(string, int) {}
 
 // ObserveTargets is a no-op for the noopMetricsRecorder.
-func (noopMetricsRecorder) ObserveTargets(int, int) {}
+func (noopMetricsRecorder) ObserveTargets(core.NamespacedName, int, int) {}
+
+// ForgetTargets is a no-op for the noopMetricsRecorder.
+func (noopMetricsRecorder) ForgetTargets(core.NamespacedName) {}
 
 // ObserveReconcileDuration is a no-op for the noopMetricsRecorder.
 func (noopMetricsRecorder) ObserveReconcileDuration(time.Duration) {}
@@ -45,6 +54,9 @@ func (noopMetricsRecorder) ObserveReconcileDuration(time.Duration) {}
 // IncError is a no-op for the noopMetricsRecorder.
 func (noopMetricsRecorder) IncError(string) {}
 
+// IncDrift is a no-op for the noopMetricsRecorder.
+func (noopMetricsRecorder) IncDrift() {}
+
 type prometheusMetricsRecorder struct{}
 
 var (
@@ -53,21 +65,28 @@ var (
 		Help: "Number of propagation actions by result.",
 	}, []string{"action"})
 
-	targetsGauge = prometheus.NewGauge(prometheus.GaugeOpts{
+	// Target gauges are labelled per ConfigPropagation so concurrent reconciles of different objects do not
+	// overwrite each other's values.
+	targetsGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
 		Name: "configpropagator_targets_gauge",
 		Help: "Latest number of target namespaces evaluated per reconcile.",
-	})
+	}, []string{"namespace", "name"})
 
-	outOfSyncGauge = prometheus.NewGauge(prometheus.GaugeOpts{
+	outOfSyncGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
 		Name: "configpropagator_out_of_sync_gauge",
 		Help: "Latest number of target namespaces still pending sync.",
-	})
+	}, []string{"namespace", "name"})
 
 	errorsCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
 		Name: "configpropagator_errors_total",
 		Help: "Total number of reconcile errors by stage.",
 	}, []string{"stage"})
 
+	driftCounter = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "configpropagator_drift_detected_total",
+		Help: "Number of managed targets whose data was modified outside the controller.",
+	})
+
 	reconcileHistogram = prometheus.NewHistogram(prometheus.HistogramOpts{
 		Name:    "configpropagator_updates_seconds",
 		Help:    "Histogram of reconciliation durations.",
@@ -77,7 +96,7 @@ var (
 
 // init registers the metrics collectors with the controller-runtime registry.
 func init() {
-	ctrlmetrics.Registry.MustRegister(propagationCounter, targetsGauge, outOfSyncGauge, errorsCounter, reconcileHistogram)
+	ctrlmetrics.Registry.MustRegister(propagationCounter, targetsGauge, outOfSyncGauge, errorsCounter, driftCounter, reconcileHistogram)
 }
 
 // NewPrometheusMetricsRecorder constructs a MetricsRecorder backed by Prometheus metrics.
@@ -91,9 +110,15 @@ func (*prometheusMetricsRecorder) AddPropagations(action string, count int) {
 }
 
 // ObserveTargets records the latest target counts for the Prometheus implementation.
-func (*prometheusMetricsRecorder) ObserveTargets(total, outOfSync int) {
-	targetsGauge.Set(float64(total))
-	outOfSyncGauge.Set(float64(outOfSync))
+func (*prometheusMetricsRecorder) ObserveTargets(name core.NamespacedName, total, outOfSync int) {
+	targetsGauge.WithLabelValues(name.Namespace, name.Name).Set(float64(total))
+	outOfSyncGauge.WithLabelValues(name.Namespace, name.Name).Set(float64(outOfSync))
+}
+
+// ForgetTargets deletes the target gauges of a ConfigPropagation for the Prometheus implementation.
+func (*prometheusMetricsRecorder) ForgetTargets(name core.NamespacedName) {
+	targetsGauge.DeleteLabelValues(name.Namespace, name.Name)
+	outOfSyncGauge.DeleteLabelValues(name.Namespace, name.Name)
 }
 
 // ObserveReconcileDuration records how long reconciliations take for the Prometheus implementation.
@@ -106,6 +131,11 @@ func (*prometheusMetricsRecorder) IncError(stage string) {
 	errorsCounter.WithLabelValues(stage).Inc()
 }
 
+// IncDrift increments the drift counter for the Prometheus implementation.
+func (*prometheusMetricsRecorder) IncDrift() {
+	driftCounter.Inc()
+}
+
 // Action constants exported for reuse in controllers.
 const (
 	MetricsActionCreate = actionCreate
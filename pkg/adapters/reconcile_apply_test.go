@@ -0,0 +1,74 @@
+package adapters_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"configpropagation/pkg/adapters"
+	"configpropagation/pkg/controllers/configpropagation"
+	"configpropagation/pkg/core"
+)
+
+func TestOverwriteRemovesKeysAddedByAnotherManager(t *testing.T) {
+	kubeClient := adapters.NewApplyFakeClient(t,
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "team-a", Labels: map[string]string{"team": "a"}}},
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "platform", Name: "app"}, Data: map[string]string{"k": "v"}},
+	)
+	reconciler := configpropagation.NewReconciler(adapters.NewServerSideApplyClient(kubeClient, adapters.ClientOptions{}), nil, nil)
+	key := configpropagation.Key{Namespace: "platform", Name: "cp", UID: "uid-1"}
+	spec := func() *core.ConfigPropagationSpec {
+		return &core.ConfigPropagationSpec{
+			SourceRef:         core.ObjectRef{Namespace: "platform", Name: "app"},
+			NamespaceSelector: &core.LabelSelector{MatchLabels: map[string]string{"team": "a"}},
+			ConflictPolicy:    core.ConflictOverwrite,
+		}
+	}
+	reconcile := func() {
+		t.Helper()
+
+		result, err := reconciler.Reconcile(context.Background(), key, spec())
+		if err != nil || len(result.OutOfSync) != 0 {
+			t.Fatalf("reconcile: %v %+v", err, result.OutOfSync)
+		}
+	}
+	target := func() *corev1.ConfigMap {
+		t.Helper()
+
+		var configMap corev1.ConfigMap
+		if err := kubeClient.Get(context.Background(), client.ObjectKey{Namespace: "team-a", Name: "app"}, &configMap); err != nil {
+			t.Fatalf("get target: %v", err)
+		}
+		return &configMap
+	}
+
+	reconcile()
+
+	// kubectl edit adds a key the controller never applied, so the API server would not prune it on apply.
+	edited := target()
+	edited.Data["extra"] = "x"
+	edited.BinaryData = map[string][]byte{"blob": {0x01}}
+	if err := kubeClient.Update(context.Background(), edited); err != nil {
+		t.Fatalf("edit target: %v", err)
+	}
+
+	reconcile()
+
+	repaired := target()
+	if !reflect.DeepEqual(repaired.Data, map[string]string{"k": "v"}) || len(repaired.BinaryData) != 0 {
+		t.Fatalf("expected foreign keys to be removed, got %+v %+v", repaired.Data, repaired.BinaryData)
+	}
+	if repaired.Annotations[core.HashAnnotation] != core.HashData(repaired.Data, repaired.BinaryData) {
+		t.Fatalf("expected the repaired target to match its hash, got %+v", repaired.Annotations)
+	}
+
+	// With the hash matching again, the next reconcile leaves the target alone.
+	reconcile()
+	if resourceVersion := target().ResourceVersion; resourceVersion != repaired.ResourceVersion {
+		t.Fatalf("expected no write once repaired, resourceVersion moved from %s to %s", repaired.ResourceVersion, resourceVersion)
+	}
+}
@@ -0,0 +1,96 @@
+package adapters
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"configpropagation/pkg/core"
+)
+
+// FieldManager is the field manager name used for server-side apply writes.
+const FieldManager = "configpropagation"
+
+// serverSideApplyClient writes targets with server-side apply and shares every read with controllerRuntimeClient.
+// The API server tracks which fields the controller owns, so metadata and data written by other managers is left alone.
+type serverSideApplyClient struct {
+	controllerRuntimeClient
+	fieldManager string
+}
+
+var _ ConfigMapApplier = &serverSideApplyClient{}
+
+// NewServerSideApplyClient returns a KubeClient that writes targets with server-side apply under FieldManager.
+func NewServerSideApplyClient(kubeClient client.Client, options ClientOptions) KubeClient {
+	return &serverSideApplyClient{controllerRuntimeClient: controllerRuntimeClient{client: kubeClient, callTimeout: options.CallTimeout, unindexedTargetLookup: options.UnindexedTargetLookup}, fieldManager: FieldManager}
+}
+
+// UpsertConfigMap applies the target and takes ownership of any conflicting fields.
+func (clientAdapter *serverSideApplyClient) UpsertConfigMap(requestContext context.Context, namespace, name string, data map[string]string, binaryData map[string][]byte, labelsMap, annotations map[string]string) error {
+	return clientAdapter.ApplyConfigMap(requestContext, namespace, name, data, binaryData, labelsMap, annotations, true)
+}
+
+// ApplyConfigMap sends the full desired target as an apply patch. Fields the controller applied before but
+// omits now are removed by the API server. Without force, fields owned by another manager fail the apply
+// with an error for which IsFieldManagerConflict holds. With force, data and binaryData keys another manager
+// added are removed as well, since the API server only prunes fields the applying manager owned and the target
+// would otherwise never match its hash. The ownership record is applied too, so detaching a target strips the
+// same keys whichever adapter wrote it.
+func (clientAdapter *serverSideApplyClient) ApplyConfigMap(requestContext context.Context, namespace, name string, data map[string]string, binaryData map[string][]byte, labelsMap, annotations map[string]string, force bool) error {
+	requestContext, cancel := clientAdapter.callContext(requestContext)
+	defer cancel()
+	labelsMap, annotations = core.MergeOwnedMetadata(nil, nil, labelsMap, annotations)
+
+	configMap := corev1.ConfigMap{
+		TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "ConfigMap"},
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   namespace,
+			Name:        name,
+			Labels:      labelsMap,
+			Annotations: annotations,
+		},
+		Data:       copyStringMap(data),
+		BinaryData: copyBinaryMap(binaryData),
+	}
+
+	patchOptions := []client.PatchOption{client.FieldOwner(clientAdapter.fieldManager)}
+	if force {
+		patchOptions = append(patchOptions, client.ForceOwnership)
+	}
+
+	if err := clientAdapter.client.Patch(requestContext, &configMap, client.Apply, patchOptions...); err != nil {
+		return err
+	}
+
+	if !force {
+		return nil
+	}
+
+	return clientAdapter.removeForeignKeys(requestContext, &configMap, data, binaryData)
+}
+
+// removeForeignKeys deletes the data and binaryData keys of the applied target that are not desired. The patch
+// is an ordinary update, which removes fields whoever owns them; it is guarded by the resourceVersion the apply
+// returned so keys added after the apply are left for the next reconcile.
+func (clientAdapter *serverSideApplyClient) removeForeignKeys(requestContext context.Context, applied *corev1.ConfigMap, data map[string]string, binaryData map[string][]byte) error {
+	pruned := applied.DeepCopy()
+	for key := range pruned.Data {
+		if _, desired := data[key]; !desired {
+			delete(pruned.Data, key)
+		}
+	}
+	for key := range pruned.BinaryData {
+		if _, desired := binaryData[key]; !desired {
+			delete(pruned.BinaryData, key)
+		}
+	}
+
+	if len(pruned.Data) == len(applied.Data) && len(pruned.BinaryData) == len(applied.BinaryData) {
+		return nil
+	}
+
+	patch := client.MergeFromWithOptions(applied, client.MergeFromWithOptimisticLock{})
+	return clientAdapter.client.Patch(requestContext, pruned, patch, client.FieldOwner(clientAdapter.fieldManager))
+}
@@ -0,0 +1,44 @@
+package adapters
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestServerSideApplyConflicts(t *testing.T) {
+	// Another manager owns the "log" key of the existing target.
+	existing := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "team", Name: "cfg"}, Data: map[string]string{"log": "debug"}}
+	clientAdapter := NewServerSideApplyClient(newApplyFakeClient(t, existing), ClientOptions{})
+	applier := clientAdapter.(ConfigMapApplier)
+
+	err := applier.ApplyConfigMap(context.Background(), "team", "cfg", map[string]string{"log": "info"}, nil, nil, nil, false)
+	if !IsFieldManagerConflict(err) {
+		t.Fatalf("expected a field manager conflict, got %v", err)
+	}
+
+	if data, _, _, _, _, _ := clientAdapter.GetTargetConfigMap(context.Background(), "team", "cfg"); data["log"] != "debug" {
+		t.Fatalf("expected the conflicting field to be left alone, got %+v", data)
+	}
+
+	// Agreeing on the value is not a conflict.
+	if err := applier.ApplyConfigMap(context.Background(), "team", "cfg", map[string]string{"log": "debug"}, nil, nil, nil, false); err != nil {
+		t.Fatalf("expected shared ownership of an identical value, got %v", err)
+	}
+
+	if err := applier.ApplyConfigMap(context.Background(), "team", "cfg", map[string]string{"log": "info"}, nil, nil, nil, true); err != nil {
+		t.Fatalf("forced apply: %v", err)
+	}
+
+	if data, _, _, _, _, _ := clientAdapter.GetTargetConfigMap(context.Background(), "team", "cfg"); data["log"] != "info" {
+		t.Fatalf("expected forced apply to take ownership, got %+v", data)
+	}
+}
+
+func TestOnlyServerSideApplyClientIsAnApplier(t *testing.T) {
+	if _, isApplier := NewControllerRuntimeClient(newApplyFakeClient(t), ClientOptions{}).(ConfigMapApplier); isApplier {
+		t.Fatalf("the update-based adapter must not advertise server-side apply")
+	}
+}
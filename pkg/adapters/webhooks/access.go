@@ -0,0 +1,73 @@
+package webhooks
+
+import (
+	"context"
+	"fmt"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	core "configpropagation/pkg/core"
+)
+
+// authorizeSources runs a SubjectAccessReview as the requesting user for get on every source ConfigMap the new
+// spec adds, so the controller's cluster-wide read access cannot copy a ConfigMap the user could not read.
+// Sources the old spec already referenced were authorized when they were added, which keeps edits by other
+// users and the controller's own finalizer updates working.
+func (validator *ConfigPropagationValidator) authorizeSources(requestContext context.Context, newSpec, oldSpec *core.ConfigPropagationSpec) error {
+	admissionRequest, err := admission.RequestFromContext(requestContext)
+	if err != nil {
+		// Only admission requests carry a user to review.
+		return nil
+	}
+
+	authorized := map[core.ObjectRef]bool{}
+	if oldSpec != nil {
+		for _, sourceLayer := range core.SourceLayers(oldSpec) {
+			authorized[core.ObjectRef{Namespace: sourceLayer.Namespace, Name: sourceLayer.Name}] = true
+		}
+	}
+
+	userInfo := admissionRequest.UserInfo
+	extra := make(map[string]authorizationv1.ExtraValue, len(userInfo.Extra))
+	for extraKey, extraValue := range userInfo.Extra {
+		extra[extraKey] = authorizationv1.ExtraValue(extraValue)
+	}
+
+	for _, sourceLayer := range core.SourceLayers(newSpec) {
+		source := core.ObjectRef{Namespace: sourceLayer.Namespace, Name: sourceLayer.Name}
+		if authorized[source] {
+			continue
+		}
+
+		review := &authorizationv1.SubjectAccessReview{Spec: authorizationv1.SubjectAccessReviewSpec{
+			User:   userInfo.Username,
+			UID:    userInfo.UID,
+			Groups: userInfo.Groups,
+			Extra:  extra,
+			ResourceAttributes: &authorizationv1.ResourceAttributes{
+				Namespace: source.Namespace,
+				Verb:      "get",
+				Resource:  "configmaps",
+				Name:      source.Name,
+			},
+		}}
+
+		if err := validator.accessReviewer.Create(requestContext, review); err != nil {
+			return fmt.Errorf("review access to source %s/%s: %w", source.Namespace, source.Name, err)
+		}
+
+		if !review.Status.Allowed {
+			message := fmt.Sprintf("user %q may not get ConfigMap %s/%s, so it cannot be used as a source", userInfo.Username, source.Namespace, source.Name)
+			if review.Status.Reason != "" {
+				message = fmt.Sprintf("%s: %s", message, review.Status.Reason)
+			}
+
+			return fmt.Errorf("%s", message)
+		}
+
+		authorized[source] = true
+	}
+
+	return nil
+}
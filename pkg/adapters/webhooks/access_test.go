@@ -0,0 +1,84 @@
+package webhooks
+
+import (
+	"context"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	configv1alpha1 "configpropagation/pkg/api/v1alpha1"
+	core "configpropagation/pkg/core"
+)
+
+// reviewingClient builds a fake client that answers SubjectAccessReviews with decide, allowing everything when
+// decide is nil, and passes other creates through.
+func reviewingClient(builder *fake.ClientBuilder, decide func(review *authorizationv1.SubjectAccessReview) bool) client.WithWatch {
+	return builder.WithInterceptorFuncs(interceptor.Funcs{
+		Create: func(ctx context.Context, kubeClient client.WithWatch, object client.Object, options ...client.CreateOption) error {
+			review, ok := object.(*authorizationv1.SubjectAccessReview)
+			if !ok {
+				return kubeClient.Create(ctx, object, options...)
+			}
+
+			review.Status.Allowed = decide == nil || decide(review)
+			if !review.Status.Allowed {
+				review.Status.Reason = "no RBAC policy matched"
+			}
+
+			return nil
+		},
+	}).Build()
+}
+
+func TestAdmissionReviewsNewSourcesAsRequestingUser(t *testing.T) {
+	scheme := admissionScheme(t)
+
+	var reviewed []authorizationv1.SubjectAccessReviewSpec
+	kubeClient := reviewingClient(fake.NewClientBuilder().WithScheme(scheme), func(review *authorizationv1.SubjectAccessReview) bool {
+		reviewed = append(reviewed, review.Spec)
+		return review.Spec.ResourceAttributes.Namespace != "kube-system"
+	})
+
+	server := httptest.NewServer(admission.WithCustomValidator(scheme, &configv1alpha1.ConfigPropagation{}, NewConfigPropagationValidator(kubeClient)))
+	defer server.Close()
+
+	existing := propagation("team-a", "cp")
+	existing.Spec.Sources = []core.SourceSpec{{Namespace: "kube-system", Name: "cluster-info"}}
+
+	response := review(t, server, admissionv1.Create, existing, nil)
+	if response.Allowed || !strings.Contains(response.Result.Message, `user "alice" may not get ConfigMap kube-system/cluster-info`) {
+		t.Fatalf("expected the unreadable overlay to be rejected, got allowed=%v result=%+v", response.Allowed, response.Result)
+	}
+
+	want := authorizationv1.ResourceAttributes{Namespace: "kube-system", Verb: "get", Resource: "configmaps", Name: "cluster-info"}
+	last := reviewed[len(reviewed)-1]
+	if last.User != "alice" || len(last.Groups) != 2 || *last.ResourceAttributes != want {
+		t.Fatalf("expected the review to ask as the requesting user, got %+v", last)
+	}
+
+	// Sources the old object already had were reviewed when they were added, so only the new sourceRef is checked.
+	reviewed = nil
+	moved := existing.DeepCopy()
+	moved.Spec.SourceRef.Name = "other"
+
+	if response := review(t, server, admissionv1.Update, moved, existing); !response.Allowed {
+		t.Fatalf("expected the update to be admitted, got %+v", response.Result)
+	}
+
+	if len(reviewed) != 1 || reviewed[0].ResourceAttributes.Name != "other" {
+		t.Fatalf("expected only the new source to be reviewed, got %+v", reviewed)
+	}
+
+	// Direct calls carry no requesting user and are not reviewed.
+	reviewed = nil
+	if _, err := NewConfigPropagationValidator(kubeClient).ValidateCreate(context.Background(), existing); err != nil || len(reviewed) != 0 {
+		t.Fatalf("expected calls outside admission to skip the review, got err=%v reviews=%d", err, len(reviewed))
+	}
+}
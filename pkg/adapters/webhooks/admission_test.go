@@ -0,0 +1,252 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/envtest"
+	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
+	crwebhook "sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	configv1alpha1 "configpropagation/pkg/api/v1alpha1"
+	core "configpropagation/pkg/core"
+)
+
+const (
+	// mutatePath and validatePath are where controller-runtime serves the ConfigPropagation webhooks.
+	mutatePath   = "/mutate-configpropagator-platform-example-com-v1alpha1-configpropagation"
+	validatePath = "/validate-configpropagator-platform-example-com-v1alpha1-configpropagation"
+)
+
+// admissionScheme registers the types the webhooks decode.
+func admissionScheme(t *testing.T) *runtime.Scheme {
+	t.Helper()
+
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatalf("add core scheme: %v", err)
+	}
+
+	if err := configv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatalf("add configpropagation scheme: %v", err)
+	}
+
+	return scheme
+}
+
+// review posts an AdmissionReview for the objects to the handler and returns its response.
+func review(t *testing.T, server *httptest.Server, operation admissionv1.Operation, object, oldObject *configv1alpha1.ConfigPropagation) *admissionv1.AdmissionResponse {
+	t.Helper()
+
+	request := &admissionv1.AdmissionRequest{
+		UID:       "review",
+		Kind:      metav1.GroupVersionKind{Group: configv1alpha1.GroupVersion.Group, Version: configv1alpha1.GroupVersion.Version, Kind: "ConfigPropagation"},
+		Resource:  metav1.GroupVersionResource{Group: configv1alpha1.GroupVersion.Group, Version: configv1alpha1.GroupVersion.Version, Resource: "configpropagations"},
+		Namespace: object.Namespace,
+		Name:      object.Name,
+		Operation: operation,
+		UserInfo:  authenticationv1.UserInfo{Username: "alice", Groups: []string{"team-a", "system:authenticated"}},
+	}
+
+	for raw, source := range map[*runtime.RawExtension]*configv1alpha1.ConfigPropagation{&request.Object: object, &request.OldObject: oldObject} {
+		if source == nil {
+			continue
+		}
+
+		encoded, err := json.Marshal(source)
+		if err != nil {
+			t.Fatalf("encode object: %v", err)
+		}
+		raw.Raw = encoded
+	}
+
+	body, err := json.Marshal(admissionv1.AdmissionReview{
+		TypeMeta: metav1.TypeMeta{APIVersion: "admission.k8s.io/v1", Kind: "AdmissionReview"},
+		Request:  request,
+	})
+	if err != nil {
+		t.Fatalf("encode review: %v", err)
+	}
+
+	response, err := http.Post(server.URL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		t.Fatalf("post review: %v", err)
+	}
+	defer response.Body.Close()
+
+	var reviewed admissionv1.AdmissionReview
+	if err := json.NewDecoder(response.Body).Decode(&reviewed); err != nil {
+		t.Fatalf("decode review: %v", err)
+	}
+
+	return reviewed.Response
+}
+
+func TestAdmissionHandlerRunsGuardrailsWithOldObject(t *testing.T) {
+	scheme := admissionScheme(t)
+	validator := NewConfigPropagationValidator(reviewingClient(fake.NewClientBuilder().WithScheme(scheme), nil))
+
+	server := httptest.NewServer(admission.WithCustomValidator(scheme, &configv1alpha1.ConfigPropagation{}, validator))
+	defer server.Close()
+
+	existing := propagation("team-a", "cp")
+	moved := existing.DeepCopy()
+	moved.Spec.SourceRef.Name = "other"
+	moved.Spec.NamespaceSelector = &core.LabelSelector{}
+
+	response := review(t, server, admissionv1.Update, moved, existing)
+	if !response.Allowed || len(response.Warnings) != 2 {
+		t.Fatalf("expected the update to be admitted with two warnings, got allowed=%v warnings=%q", response.Allowed, response.Warnings)
+	}
+
+	t.Setenv(immutableSourceEnv, "true")
+	response = review(t, server, admissionv1.Update, moved, existing)
+	if response.Allowed || !strings.Contains(response.Result.Message, "sourceRef is immutable") {
+		t.Fatalf("expected the old object to be compared on update, got allowed=%v result=%+v", response.Allowed, response.Result)
+	}
+
+	if response := review(t, server, admissionv1.Create, moved, nil); !response.Allowed {
+		t.Fatalf("expected immutability to ignore creates, got %+v", response.Result)
+	}
+}
+
+func TestWebhookManifestsUseServedPaths(t *testing.T) {
+	manifest, err := os.ReadFile(filepath.Join("..", "..", "..", "configs", "webhook", "webhooks.yaml"))
+	if err != nil {
+		t.Fatalf("read webhook manifests: %v", err)
+	}
+
+	for _, path := range []string{mutatePath, validatePath} {
+		if !strings.Contains(string(manifest), "path: "+path+"\n") {
+			t.Fatalf("expected configs/webhook/webhooks.yaml to route to %s", path)
+		}
+	}
+}
+
+// warningRecorder collects the admission warnings returned by the API server.
+type warningRecorder struct {
+	mutex    sync.Mutex
+	warnings []string
+}
+
+func (recorder *warningRecorder) HandleWarningHeader(_ int, _ string, text string) {
+	recorder.mutex.Lock()
+	defer recorder.mutex.Unlock()
+
+	recorder.warnings = append(recorder.warnings, text)
+}
+
+// TestEnvtestWebhookEnforcesGuardrails installs the CRD and webhook manifests into a real API server and serves
+// the manager's webhooks, so the request travels the same path it does in a cluster.
+// It needs the envtest binaries; point KUBEBUILDER_ASSETS at them to run it.
+func TestEnvtestWebhookEnforcesGuardrails(t *testing.T) {
+	if os.Getenv("KUBEBUILDER_ASSETS") == "" {
+		t.Skip("KUBEBUILDER_ASSETS is not set; skipping envtest webhook test")
+	}
+
+	testEnvironment := &envtest.Environment{
+		CRDDirectoryPaths:     []string{filepath.Join("..", "..", "..", "configs", "crd")},
+		WebhookInstallOptions: envtest.WebhookInstallOptions{Paths: []string{filepath.Join("..", "..", "..", "configs", "webhook")}},
+	}
+
+	restConfig, err := testEnvironment.Start()
+	if err != nil {
+		t.Fatalf("start envtest: %v", err)
+	}
+	defer func() { _ = testEnvironment.Stop() }()
+
+	scheme := admissionScheme(t)
+	webhookOptions := testEnvironment.WebhookInstallOptions
+
+	manager, err := ctrl.NewManager(restConfig, ctrl.Options{
+		Scheme:        scheme,
+		Metrics:       metricsserver.Options{BindAddress: "0"},
+		WebhookServer: crwebhook.NewServer(crwebhook.Options{Host: webhookOptions.LocalServingHost, Port: webhookOptions.LocalServingPort, CertDir: webhookOptions.LocalServingCertDir}),
+	})
+	if err != nil {
+		t.Fatalf("create manager: %v", err)
+	}
+
+	if err := NewConfigPropagationValidator(manager.GetClient()).SetupWebhookWithManager(manager); err != nil {
+		t.Fatalf("set up webhook: %v", err)
+	}
+
+	managerContext, stopManager := context.WithCancel(context.Background())
+	defer stopManager()
+
+	go func() { _ = manager.Start(managerContext) }()
+
+	webhookAddress := net.JoinHostPort(webhookOptions.LocalServingHost, strconv.Itoa(webhookOptions.LocalServingPort))
+	deadline := time.Now().Add(10 * time.Second)
+	for {
+		connection, err := tls.DialWithDialer(&net.Dialer{Timeout: time.Second}, "tcp", webhookAddress, &tls.Config{InsecureSkipVerify: true})
+		if err == nil {
+			connection.Close()
+			break
+		}
+
+		if time.Now().After(deadline) {
+			t.Fatalf("webhook server did not start: %v", err)
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+
+	recorder := &warningRecorder{}
+	clientConfig := rest.CopyConfig(restConfig)
+	clientConfig.WarningHandler = recorder
+
+	kubeClient, err := client.New(clientConfig, client.Options{Scheme: scheme, WarningHandler: client.WarningHandlerOptions{SuppressWarnings: true}})
+	if err != nil {
+		t.Fatalf("create client: %v", err)
+	}
+
+	requestContext := context.Background()
+	if err := kubeClient.Create(requestContext, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "team-a"}}); err != nil {
+		t.Fatalf("create namespace: %v", err)
+	}
+
+	wideOpen := propagation("team-a", "cp")
+	wideOpen.Spec.NamespaceSelector = &core.LabelSelector{}
+	if err := kubeClient.Create(requestContext, wideOpen); err != nil {
+		t.Fatalf("expected a wide-open selector to be admitted with a warning: %v", err)
+	}
+
+	if len(recorder.warnings) != 1 || !strings.Contains(recorder.warnings[0], "selects every namespace") {
+		t.Fatalf("expected the selector warning to reach the client, got %q", recorder.warnings)
+	}
+
+	// The mutating webhook defaults the spec before validation sees it.
+	if wideOpen.Spec.Strategy == nil || wideOpen.Spec.Strategy.Type == "" {
+		t.Fatalf("expected the defaulting webhook to fill in the strategy, got %+v", wideOpen.Spec.Strategy)
+	}
+
+	t.Setenv(immutableSourceEnv, "true")
+	moved := wideOpen.DeepCopy()
+	moved.Spec.SourceRef.Name = "other"
+	if err := kubeClient.Update(requestContext, moved); err == nil || !strings.Contains(err.Error(), "sourceRef is immutable") {
+		t.Fatalf("expected the update to be compared with the stored object and rejected, got %v", err)
+	}
+}
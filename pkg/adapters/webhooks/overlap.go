@@ -0,0 +1,116 @@
+package webhooks
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+
+	"configpropagation/pkg/adapters"
+	configv1alpha1 "configpropagation/pkg/api/v1alpha1"
+	core "configpropagation/pkg/core"
+)
+
+// validateTargetOverlap rejects a ConfigPropagation that would write the same ConfigMap name into a namespace
+// selected by another ConfigPropagation, before either has written anything there. ConfigPropagations that are
+// being deleted do not count.
+func (validator *ConfigPropagationValidator) validateTargetOverlap(requestContext context.Context, configPropagation *configv1alpha1.ConfigPropagation) error {
+	var namespaces corev1.NamespaceList
+	if err := validator.reader.List(requestContext, &namespaces); err != nil {
+		return fmt.Errorf("list namespaces: %w", err)
+	}
+
+	var configPropagations configv1alpha1.ConfigPropagationList
+	if err := validator.reader.List(requestContext, &configPropagations); err != nil {
+		return fmt.Errorf("list ConfigPropagations: %w", err)
+	}
+
+	desiredTargets, err := selectedTargets(&configPropagation.Spec, namespaces.Items)
+	if err != nil {
+		return err
+	}
+
+	var overlaps []string
+
+	for index := range configPropagations.Items {
+		other := &configPropagations.Items[index]
+		if other.Namespace == configPropagation.Namespace && other.Name == configPropagation.Name {
+			continue
+		}
+
+		// A ConfigPropagation being deleted is commonly replaced by a new one; it writes nothing more.
+		if other.DeletionTimestamp != nil {
+			continue
+		}
+
+		otherTargets, err := selectedTargets(&other.Spec, namespaces.Items)
+		if err != nil {
+			continue
+		}
+
+		for target := range otherTargets {
+			if _, overlapping := desiredTargets[target]; overlapping {
+				overlaps = append(overlaps, fmt.Sprintf("%s (also written by %s/%s)", target, other.Namespace, other.Name))
+			}
+		}
+	}
+
+	if len(overlaps) == 0 {
+		return nil
+	}
+
+	sort.Strings(overlaps)
+	if len(overlaps) > maxReportedClaims {
+		overlaps = append(overlaps[:maxReportedClaims:maxReportedClaims], fmt.Sprintf("and %d more", len(overlaps)-maxReportedClaims))
+	}
+
+	return fmt.Errorf("targets overlap another ConfigPropagation when %s is enabled: %s", targetOverlapEnv, strings.Join(overlaps, ", "))
+}
+
+// selectedTargets returns the namespace/name of every target the spec writes among the namespaces.
+func selectedTargets(spec *core.ConfigPropagationSpec, namespaces []corev1.Namespace) (map[string]struct{}, error) {
+	if spec.NamespaceSelector == nil {
+		return nil, fmt.Errorf("namespaceSelector is required")
+	}
+
+	selector, err := adapters.NewLabelSelector(spec.NamespaceSelector.MatchLabels, selectorRequirements(spec.NamespaceSelector))
+	if err != nil {
+		return nil, err
+	}
+
+	targetNamer, err := core.NewTargetNamer(spec)
+	if err != nil {
+		return nil, err
+	}
+
+	targets := map[string]struct{}{}
+
+	for _, namespace := range namespaces {
+		if !selector.Matches(labels.Set(namespace.Labels)) {
+			continue
+		}
+
+		targetName, err := targetNamer.Name(namespace.Name)
+		if err != nil {
+			continue
+		}
+
+		targets[fmt.Sprintf("%s/%s", namespace.Name, targetName)] = struct{}{}
+	}
+
+	return targets, nil
+}
+
+// selectorRequirements translates the selector expressions into adapter requirements.
+func selectorRequirements(selector *core.LabelSelector) []adapters.LabelSelectorRequirement {
+	var requirements []adapters.LabelSelectorRequirement
+
+	for _, expression := range selector.MatchExpressions {
+		requirements = append(requirements, adapters.LabelSelectorRequirement{Key: expression.Key, Operator: expression.Operator, Values: expression.Values})
+	}
+
+	return requirements
+}
@@ -0,0 +1,88 @@
+package webhooks
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	configv1alpha1 "configpropagation/pkg/api/v1alpha1"
+	core "configpropagation/pkg/core"
+)
+
+func TestValidatorRejectsOverlappingTargetsWhenEnabled(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatalf("add core scheme: %v", err)
+	}
+	if err := configv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatalf("add configpropagation scheme: %v", err)
+	}
+
+	existing := propagation("team-a", "cp")
+	kubeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "pay-1", Labels: map[string]string{"team": "payments"}}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "search", Labels: map[string]string{"team": "search"}}},
+		existing,
+	).Build()
+	validator := NewConfigPropagationValidator(kubeClient)
+
+	contender := propagation("team-b", "cp")
+	if _, err := validator.ValidateCreate(context.Background(), contender); err != nil {
+		t.Fatalf("overlap must only be rejected when %s is enabled: %v", targetOverlapEnv, err)
+	}
+
+	t.Setenv(targetOverlapEnv, "true")
+
+	_, err := validator.ValidateCreate(context.Background(), contender)
+	if err == nil || !strings.Contains(err.Error(), "pay-1/app (also written by team-a/cp)") {
+		t.Fatalf("expected the overlapping target to be reported, got %v", err)
+	}
+
+	// Updating the existing CR does not collide with itself.
+	if _, err := validator.ValidateUpdate(context.Background(), existing, existing.DeepCopy()); err != nil {
+		t.Fatalf("expected an update of the existing CR to pass: %v", err)
+	}
+
+	renamed := propagation("team-b", "cp")
+	renamed.Spec.Target = &core.TargetSpec{NameTemplate: "{{ .SourceName }}-b"}
+	if _, err := validator.ValidateCreate(context.Background(), renamed); err != nil {
+		t.Fatalf("expected a distinct target name to pass: %v", err)
+	}
+
+	elsewhere := propagation("team-b", "cp")
+	elsewhere.Spec.NamespaceSelector = &core.LabelSelector{MatchLabels: map[string]string{"team": "search"}}
+	if _, err := validator.ValidateCreate(context.Background(), elsewhere); err != nil {
+		t.Fatalf("expected disjoint namespaces to pass: %v", err)
+	}
+}
+
+func TestValidatorIgnoresOverlapWithDeletingPropagation(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatalf("add core scheme: %v", err)
+	}
+	if err := configv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatalf("add configpropagation scheme: %v", err)
+	}
+
+	deleting := propagation("team-a", "cp")
+	deleting.Finalizers = []string{core.Finalizer}
+	deleting.DeletionTimestamp = &metav1.Time{Time: time.Now()}
+	kubeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "pay-1", Labels: map[string]string{"team": "payments"}}},
+		deleting,
+	).Build()
+	t.Setenv(targetOverlapEnv, "true")
+
+	// Replacing a CR deletes it first; its successor must not be rejected while the finalizer runs.
+	if _, err := NewConfigPropagationValidator(kubeClient).ValidateCreate(context.Background(), propagation("team-b", "cp")); err != nil {
+		t.Fatalf("expected overlap with a deleting CR to pass: %v", err)
+	}
+}
@@ -0,0 +1,212 @@
+package webhooks
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"configpropagation/pkg/adapters"
+	configv1alpha1 "configpropagation/pkg/api/v1alpha1"
+	core "configpropagation/pkg/core"
+)
+
+// maxReportedClaims bounds how many contested targets an admission error lists.
+const maxReportedClaims = 5
+
+// ConfigPropagationValidator validates ConfigPropagations on admission: the spec and policy guardrails of
+// ValidateConfigPropagation, a SubjectAccessReview of the requesting user for every new source, the cluster's
+// ConfigPropagationPolicies, plus a check that no selected target is already owned by another ConfigPropagation.
+// With REJECT_TARGET_OVERLAP enabled it also refuses specs that would write targets another spec writes.
+type ConfigPropagationValidator struct {
+	reader         client.Reader
+	clientAdapter  adapters.KubeClient
+	accessReviewer client.Writer
+}
+
+var _ admission.CustomValidator = &ConfigPropagationValidator{}
+
+// NewConfigPropagationValidator builds a validator that reads namespaces and targets through the client.
+func NewConfigPropagationValidator(kubeClient client.Client) *ConfigPropagationValidator {
+	return &ConfigPropagationValidator{reader: kubeClient, clientAdapter: adapters.NewControllerRuntimeClient(kubeClient, adapters.ClientOptions{}), accessReviewer: kubeClient}
+}
+
+// SetupWebhookWithManager registers defaulting for ConfigPropagations and this validator with the manager.
+func (validator *ConfigPropagationValidator) SetupWebhookWithManager(manager ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(manager).
+		For(&configv1alpha1.ConfigPropagation{}).
+		WithValidator(validator).
+		Complete()
+}
+
+// ValidateCreate implements admission.CustomValidator.
+func (validator *ConfigPropagationValidator) ValidateCreate(requestContext context.Context, object runtime.Object) (admission.Warnings, error) {
+	configPropagation, ok := object.(*configv1alpha1.ConfigPropagation)
+	if !ok {
+		return nil, fmt.Errorf("expected a ConfigPropagation, got %T", object)
+	}
+
+	warnings, err := ValidateConfigPropagation(&configPropagation.Spec, nil)
+	if err != nil {
+		return warnings, err
+	}
+
+	if err := validator.authorizeSources(requestContext, &configPropagation.Spec, nil); err != nil {
+		return warnings, err
+	}
+
+	if err := validator.validatePolicies(requestContext, configPropagation); err != nil {
+		return warnings, err
+	}
+
+	return warnings, validator.validateTargets(requestContext, configPropagation)
+}
+
+// ValidateUpdate implements admission.CustomValidator.
+func (validator *ConfigPropagationValidator) ValidateUpdate(requestContext context.Context, oldObject, newObject runtime.Object) (admission.Warnings, error) {
+	oldConfigPropagation, ok := oldObject.(*configv1alpha1.ConfigPropagation)
+	if !ok {
+		return nil, fmt.Errorf("expected a ConfigPropagation, got %T", oldObject)
+	}
+
+	newConfigPropagation, ok := newObject.(*configv1alpha1.ConfigPropagation)
+	if !ok {
+		return nil, fmt.Errorf("expected a ConfigPropagation, got %T", newObject)
+	}
+
+	// Deleting objects only get their finalizer removed; never block that.
+	if !newConfigPropagation.DeletionTimestamp.IsZero() {
+		return nil, nil
+	}
+
+	warnings, err := ValidateConfigPropagation(&newConfigPropagation.Spec, &oldConfigPropagation.Spec)
+	if err != nil {
+		return warnings, err
+	}
+
+	if err := validator.authorizeSources(requestContext, &newConfigPropagation.Spec, &oldConfigPropagation.Spec); err != nil {
+		return warnings, err
+	}
+
+	if err := validator.validatePolicies(requestContext, newConfigPropagation); err != nil {
+		return warnings, err
+	}
+
+	return warnings, validator.validateTargets(requestContext, newConfigPropagation)
+}
+
+// ValidateDelete implements admission.CustomValidator.
+func (validator *ConfigPropagationValidator) ValidateDelete(context.Context, runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+// validatePolicies rejects a ConfigPropagation that breaks a ConfigPropagationPolicy. Selected namespaces are
+// only counted when some policy sets maxTargets.
+func (validator *ConfigPropagationValidator) validatePolicies(requestContext context.Context, configPropagation *configv1alpha1.ConfigPropagation) error {
+	policyLister, ok := validator.clientAdapter.(adapters.PolicyLister)
+	if !ok {
+		return nil
+	}
+
+	policies, err := policyLister.ListPolicies(requestContext)
+	if err != nil {
+		return fmt.Errorf("list ConfigPropagationPolicies: %w", err)
+	}
+
+	targetCount := -1
+	if core.PoliciesLimitTargets(policies) {
+		selector := configPropagation.Spec.NamespaceSelector
+		targetNamespaces, err := validator.clientAdapter.ListNamespacesBySelector(requestContext, selector.MatchLabels, selectorRequirements(selector))
+		if err != nil {
+			return fmt.Errorf("list target namespaces: %w", err)
+		}
+
+		targetCount = len(targetNamespaces)
+	}
+
+	return core.EvaluatePolicies(policies, configPropagation.Namespace, &configPropagation.Spec, targetCount)
+}
+
+// validateTargets runs the target ownership check and, when enabled, the overlap check.
+func (validator *ConfigPropagationValidator) validateTargets(requestContext context.Context, configPropagation *configv1alpha1.ConfigPropagation) error {
+	if err := validator.validateTargetOwnership(requestContext, configPropagation); err != nil {
+		return err
+	}
+
+	if parseBoolEnv(os.Getenv(targetOverlapEnv)) {
+		return validator.validateTargetOverlap(requestContext, configPropagation)
+	}
+
+	return nil
+}
+
+// validateTargetOwnership rejects a ConfigPropagation whose selected targets already exist as managed copies
+// owned by a different ConfigPropagation. As in the controller, owner UIDs are compared when both are recorded,
+// so a ConfigPropagation recreated under the same name does not inherit its predecessor's targets. Targets
+// without an owner predate ownership tracking and are not contested.
+func (validator *ConfigPropagationValidator) validateTargetOwnership(requestContext context.Context, configPropagation *configv1alpha1.ConfigPropagation) error {
+	spec := &configPropagation.Spec
+	owner := fmt.Sprintf("%s/%s", configPropagation.Namespace, configPropagation.Name)
+
+	targetNamer, err := core.NewTargetNamer(spec)
+	if err != nil {
+		return err
+	}
+
+	targetNamespaces, err := validator.clientAdapter.ListNamespacesBySelector(requestContext, spec.NamespaceSelector.MatchLabels, selectorRequirements(spec.NamespaceSelector))
+	if err != nil {
+		return fmt.Errorf("list target namespaces: %w", err)
+	}
+
+	sort.Strings(targetNamespaces)
+
+	var claims []string
+
+	for _, targetNamespace := range targetNamespaces {
+		targetName, err := targetNamer.Name(targetNamespace)
+		if err != nil {
+			continue
+		}
+
+		_, _, targetLabels, targetAnnotations, found, err := validator.clientAdapter.GetTargetConfigMap(requestContext, targetNamespace, targetName)
+		if err != nil {
+			return fmt.Errorf("get target %s/%s: %w", targetNamespace, targetName, err)
+		}
+
+		if !found || targetLabels[core.ManagedLabel] != "true" {
+			continue
+		}
+
+		targetOwner, targetOwnerUID := targetAnnotations[core.OwnerAnnotation], targetAnnotations[core.OwnerUIDAnnotation]
+		switch {
+		case targetOwnerUID != "" && configPropagation.UID != "":
+			if targetOwnerUID == string(configPropagation.UID) {
+				continue
+			}
+		case targetOwner == "" || targetOwner == owner:
+			continue
+		}
+
+		if targetOwner == owner {
+			targetOwner = "a previous " + owner
+		}
+		claims = append(claims, fmt.Sprintf("%s/%s (owned by %s)", targetNamespace, targetName, targetOwner))
+	}
+
+	if len(claims) == 0 {
+		return nil
+	}
+
+	reported := claims
+	if len(reported) > maxReportedClaims {
+		reported = append(reported[:maxReportedClaims:maxReportedClaims], fmt.Sprintf("and %d more", len(claims)-maxReportedClaims))
+	}
+
+	return fmt.Errorf("targets already owned by another ConfigPropagation: %s", strings.Join(reported, ", "))
+}
@@ -0,0 +1,143 @@
+package webhooks
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	configv1alpha1 "configpropagation/pkg/api/v1alpha1"
+	core "configpropagation/pkg/core"
+)
+
+// ownedTarget is a managed copy of platform/app written by the given owner.
+func ownedTarget(namespace, owner string) *corev1.ConfigMap {
+	return &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{
+		Namespace:   namespace,
+		Name:        "app",
+		Labels:      map[string]string{core.ManagedLabel: "true"},
+		Annotations: map[string]string{core.SourceAnnotation: "platform/app", core.OwnerAnnotation: owner},
+	}}
+}
+
+// propagation selects every namespace labelled team=payments.
+func propagation(namespace, name string) *configv1alpha1.ConfigPropagation {
+	return &configv1alpha1.ConfigPropagation{
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+		Spec: core.ConfigPropagationSpec{
+			SourceRef:         core.ObjectRef{Namespace: "platform", Name: "app"},
+			NamespaceSelector: &core.LabelSelector{MatchLabels: map[string]string{"team": "payments"}},
+		},
+	}
+}
+
+func TestValidatorRejectsTargetsOwnedByAnotherPropagation(t *testing.T) {
+	objects := []client.Object{
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "pay-1", Labels: map[string]string{"team": "payments"}}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "pay-2", Labels: map[string]string{"team": "payments"}}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "search"}},
+		ownedTarget("pay-1", "team-a/cp"),
+		ownedTarget("search", "team-b/cp"),
+	}
+	validator := NewConfigPropagationValidator(fake.NewClientBuilder().WithScheme(admissionScheme(t)).WithObjects(objects...).Build())
+
+	_, err := validator.ValidateCreate(context.Background(), propagation("team-b", "cp"))
+	if err == nil || !strings.Contains(err.Error(), "pay-1/app (owned by team-a/cp)") {
+		t.Fatalf("expected the contested target to be reported, got %v", err)
+	}
+
+	if _, err := validator.ValidateCreate(context.Background(), propagation("team-a", "cp")); err != nil {
+		t.Fatalf("the owner itself must pass: %v", err)
+	}
+
+	// Renaming the target away from the contested name resolves the claim.
+	renamed := propagation("team-b", "cp")
+	renamed.Spec.Target = &core.TargetSpec{Name: "app-b"}
+	if _, err := validator.ValidateUpdate(context.Background(), propagation("team-b", "cp"), renamed); err != nil {
+		t.Fatalf("expected renamed targets to pass: %v", err)
+	}
+
+	deleting := propagation("team-b", "cp")
+	deleting.DeletionTimestamp = &metav1.Time{Time: time.Unix(1, 0)}
+	if _, err := validator.ValidateUpdate(context.Background(), propagation("team-b", "cp"), deleting); err != nil {
+		t.Fatalf("a deleting propagation must not be blocked: %v", err)
+	}
+
+	invalid := propagation("team-c", "cp")
+	invalid.Spec.NamespaceSelector = nil
+	if _, err := validator.ValidateCreate(context.Background(), invalid); err == nil {
+		t.Fatalf("expected spec validation to run first")
+	}
+}
+
+func TestValidatorComparesOwnerUIDs(t *testing.T) {
+	previous := ownedTarget("pay-1", "team-a/cp")
+	previous.Annotations[core.OwnerUIDAnnotation] = "uid-1"
+	objects := []client.Object{
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "pay-1", Labels: map[string]string{"team": "payments"}}},
+		previous,
+	}
+	validator := NewConfigPropagationValidator(fake.NewClientBuilder().WithScheme(admissionScheme(t)).WithObjects(objects...).Build())
+
+	owner := propagation("team-a", "cp")
+	owner.UID = "uid-1"
+	if _, err := validator.ValidateUpdate(context.Background(), owner, owner); err != nil {
+		t.Fatalf("the owner itself must pass: %v", err)
+	}
+
+	// A ConfigPropagation recreated under the same name does not own its predecessor's targets.
+	recreated := propagation("team-a", "cp")
+	recreated.UID = "uid-2"
+	_, err := validator.ValidateCreate(context.Background(), recreated)
+	if err == nil || !strings.Contains(err.Error(), "pay-1/app (owned by a previous team-a/cp)") {
+		t.Fatalf("expected the predecessor's target to be reported, got %v", err)
+	}
+
+	// Without a UID on the request only the owner name can be compared.
+	if _, err := validator.ValidateCreate(context.Background(), propagation("team-a", "cp")); err != nil {
+		t.Fatalf("expected the owner name to be trusted without a UID: %v", err)
+	}
+}
+
+func TestValidatorEnforcesConfigPropagationPolicies(t *testing.T) {
+	maxTargets := int32(1)
+	objects := []client.Object{
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "pay-1", Labels: map[string]string{"team": "payments"}}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "pay-2", Labels: map[string]string{"team": "payments"}}},
+		&configv1alpha1.ConfigPropagationPolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: "payments"},
+			Spec: configv1alpha1.ConfigPropagationPolicySpec{
+				AllowedSourceNamespaces: []string{"platform"},
+				SelectorRules:           []core.SelectorRule{{Namespaces: []string{"team-*"}, AllowedLabelKeys: []string{"team"}}},
+				MaxTargets:              &maxTargets,
+			},
+		},
+	}
+	validator := NewConfigPropagationValidator(fake.NewClientBuilder().WithScheme(admissionScheme(t)).WithObjects(objects...).Build())
+
+	_, err := validator.ValidateCreate(context.Background(), propagation("team-a", "cp"))
+	if err == nil || err.Error() != "violates ConfigPropagationPolicy payments: selects 2 namespaces, more than maxTargets 1" {
+		t.Fatalf("expected maxTargets to count the selected namespaces, got %v", err)
+	}
+
+	narrowed := propagation("team-a", "cp")
+	narrowed.Spec.NamespaceSelector.MatchLabels["kubernetes.io/metadata.name"] = "pay-1"
+	if _, err := validator.ValidateCreate(context.Background(), narrowed); err == nil || !strings.Contains(err.Error(), "namespaceSelector key kubernetes.io/metadata.name is not allowed") {
+		t.Fatalf("expected selector rules to apply, got %v", err)
+	}
+
+	moved := propagation("team-a", "cp")
+	moved.Spec.SourceRef.Namespace = "kube-system"
+	if _, err := validator.ValidateUpdate(context.Background(), propagation("team-a", "cp"), moved); err == nil || !strings.Contains(err.Error(), "source namespace kube-system is not in allowedSourceNamespaces") {
+		t.Fatalf("expected updates to be checked against policies, got %v", err)
+	}
+
+	if _, err := validator.ValidateCreate(context.Background(), propagation("default", "cp")); err == nil || !strings.Contains(err.Error(), "no selectorRules entry allows ConfigPropagations in namespace default") {
+		t.Fatalf("expected namespaces without a rule to be rejected, got %v", err)
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"os"
 
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
 	core "configpropagation/pkg/core"
 )
 
@@ -14,29 +16,40 @@ const (
 	// immutableSourceEnv toggles immutability enforcement for the sourceRef
 	// field on updates.
 	immutableSourceEnv = "ENFORCE_SOURCE_IMMUTABILITY"
+	// targetOverlapEnv toggles rejection of ConfigPropagations whose selected
+	// namespaces and target names overlap those of an existing one.
+	targetOverlapEnv = "REJECT_TARGET_OVERLAP"
 )
 
 // ValidateConfigPropagation evaluates the new spec against validation rules and
 // optional policy guardrails. The old spec should be provided for update
-// operations; pass nil on create.
-func ValidateConfigPropagation(newSpec, oldSpec *core.ConfigPropagationSpec) error {
+// operations; pass nil on create. Guardrails that are not enforced still
+// report their violations as admission warnings.
+func ValidateConfigPropagation(newSpec, oldSpec *core.ConfigPropagationSpec) (admission.Warnings, error) {
 	if err := core.ValidateSpec(newSpec); err != nil {
-		return err
+		return nil, err
 	}
 
-	if parseBoolEnv(os.Getenv(strictSelectorEnv)) {
-		if isSelectorWideOpen(newSpec.NamespaceSelector) {
-			return fmt.Errorf("namespaceSelector must specify matchLabels or matchExpressions when %s is enabled", strictSelectorEnv)
+	var warnings admission.Warnings
+
+	if isSelectorWideOpen(newSpec.NamespaceSelector) {
+		if parseBoolEnv(os.Getenv(strictSelectorEnv)) {
+			return nil, fmt.Errorf("namespaceSelector must specify matchLabels or matchExpressions when %s is enabled", strictSelectorEnv)
 		}
+
+		warnings = append(warnings, "namespaceSelector has no matchLabels or matchExpressions and selects every namespace in the cluster")
 	}
 
-	if parseBoolEnv(os.Getenv(immutableSourceEnv)) && oldSpec != nil {
-		if oldSpec.SourceRef.Namespace != newSpec.SourceRef.Namespace || oldSpec.SourceRef.Name != newSpec.SourceRef.Name {
-			return fmt.Errorf("sourceRef is immutable when %s is enabled", immutableSourceEnv)
+	if oldSpec != nil && (oldSpec.SourceRef.Namespace != newSpec.SourceRef.Namespace || oldSpec.SourceRef.Name != newSpec.SourceRef.Name) {
+		if parseBoolEnv(os.Getenv(immutableSourceEnv)) {
+			return nil, fmt.Errorf("sourceRef is immutable when %s is enabled", immutableSourceEnv)
 		}
+
+		warnings = append(warnings, fmt.Sprintf("changing sourceRef from %s/%s to %s/%s rewrites every target and prunes copies of the old source",
+			oldSpec.SourceRef.Namespace, oldSpec.SourceRef.Name, newSpec.SourceRef.Namespace, newSpec.SourceRef.Name))
 	}
 
-	return nil
+	return warnings, nil
 }
 
 // isSelectorWideOpen reports whether the selector places no constraints on namespaces.
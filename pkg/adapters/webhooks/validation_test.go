@@ -1,6 +1,7 @@
 package webhooks
 
 import (
+	"strings"
 	"testing"
 
 	core "configpropagation/pkg/core"
@@ -15,7 +16,7 @@ func TestValidateConfigPropagationPassesByDefault(t *testing.T) {
 		ResyncPeriodSeconds: int32Ptr(30),
 	}
 
-	if err := ValidateConfigPropagation(spec, nil); err != nil {
+	if _, err := ValidateConfigPropagation(spec, nil); err != nil {
 		t.Fatalf("expected validation to pass, got %v", err)
 	}
 }
@@ -28,7 +29,7 @@ func TestValidateConfigPropagationRejectsInvalidStrategy(t *testing.T) {
 		Strategy:          &core.UpdateStrategy{Type: "canary", BatchSize: &zero},
 	}
 
-	if err := ValidateConfigPropagation(spec, nil); err == nil {
+	if _, err := ValidateConfigPropagation(spec, nil); err == nil {
 		t.Fatalf("expected validation error for invalid strategy")
 	}
 }
@@ -40,12 +41,12 @@ func TestValidateConfigPropagationStrictSelectorGuard(t *testing.T) {
 		NamespaceSelector: &core.LabelSelector{},
 	}
 
-	if err := ValidateConfigPropagation(spec, nil); err == nil {
+	if _, err := ValidateConfigPropagation(spec, nil); err == nil {
 		t.Fatalf("expected guardrail to reject wide-open selector")
 	}
 
 	spec.NamespaceSelector.MatchLabels = map[string]string{"team": "a"}
-	if err := ValidateConfigPropagation(spec, nil); err != nil {
+	if _, err := ValidateConfigPropagation(spec, nil); err != nil {
 		t.Fatalf("expected selector with label to pass, got %v", err)
 	}
 }
@@ -61,16 +62,40 @@ func TestValidateConfigPropagationSourceImmutability(t *testing.T) {
 		NamespaceSelector: &core.LabelSelector{MatchLabels: map[string]string{"team": "a"}},
 	}
 
-	if err := ValidateConfigPropagation(newSpec, oldSpec); err == nil {
+	if _, err := ValidateConfigPropagation(newSpec, oldSpec); err == nil {
 		t.Fatalf("expected immutability guard to reject change")
 	}
 
 	newSpec.SourceRef.Name = "cfg"
-	if err := ValidateConfigPropagation(newSpec, oldSpec); err != nil {
+	if _, err := ValidateConfigPropagation(newSpec, oldSpec); err != nil {
 		t.Fatalf("expected immutability guard to allow unchanged source, got %v", err)
 	}
 }
 
+func TestValidateConfigPropagationWarnsAboutUnenforcedGuardrails(t *testing.T) {
+	oldSpec := &core.ConfigPropagationSpec{
+		SourceRef:         core.ObjectRef{Namespace: "src", Name: "cfg"},
+		NamespaceSelector: &core.LabelSelector{MatchLabels: map[string]string{"team": "a"}},
+	}
+	newSpec := &core.ConfigPropagationSpec{
+		SourceRef:         core.ObjectRef{Namespace: "src", Name: "cfg2"},
+		NamespaceSelector: &core.LabelSelector{},
+	}
+
+	warnings, err := ValidateConfigPropagation(newSpec, oldSpec)
+	if err != nil {
+		t.Fatalf("expected soft violations to be admitted, got %v", err)
+	}
+
+	if len(warnings) != 2 || !strings.Contains(warnings[0], "selects every namespace") || !strings.Contains(warnings[1], "src/cfg to src/cfg2") {
+		t.Fatalf("expected selector and sourceRef warnings, got %q", warnings)
+	}
+
+	if warnings, _ := ValidateConfigPropagation(oldSpec, oldSpec); len(warnings) != 0 {
+		t.Fatalf("expected no warnings for a constrained, unchanged spec, got %q", warnings)
+	}
+}
+
 func int32Ptr(v int32) *int32 {
 	return &v
 }
@@ -0,0 +1,152 @@
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"configpropagation/pkg/core"
+)
+
+// ConfigPropagationPolicySpec defines the cluster-wide restrictions on ConfigPropagations.
+type ConfigPropagationPolicySpec = core.ConfigPropagationPolicySpec
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:scope=Cluster,shortName=cpolicy
+
+// ConfigPropagationPolicy restricts every ConfigPropagation in the cluster; admission and reconciles enforce it.
+type ConfigPropagationPolicy struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec ConfigPropagationPolicySpec `json:"spec,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// ConfigPropagationPolicyList contains a list of ConfigPropagationPolicy.
+type ConfigPropagationPolicyList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ConfigPropagationPolicy `json:"items"`
+}
+
+// init registers the ConfigPropagationPolicy types with the scheme builder.
+func init() {
+	SchemeBuilder.Register(&ConfigPropagationPolicy{}, &ConfigPropagationPolicyList{})
+}
+
+// Policies converts the list into the named policies core.EvaluatePolicies reads.
+func (configPropagationPolicyList *ConfigPropagationPolicyList) Policies() []core.Policy {
+	policies := make([]core.Policy, 0, len(configPropagationPolicyList.Items))
+
+	for index := range configPropagationPolicyList.Items {
+		item := &configPropagationPolicyList.Items[index]
+		policies = append(policies, core.Policy{Name: item.Name, Spec: deepCopyPolicySpec(&item.Spec)})
+	}
+
+	return policies
+}
+
+// DeepCopyInto copies the receiver into out.
+func (configPropagationPolicy *ConfigPropagationPolicy) DeepCopyInto(out *ConfigPropagationPolicy) {
+	if configPropagationPolicy == nil || out == nil {
+		return
+	}
+	*out = *configPropagationPolicy
+	configPropagationPolicy.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	out.Spec = deepCopyPolicySpec(&configPropagationPolicy.Spec)
+}
+
+// DeepCopy creates a new deep copy of the receiver.
+func (configPropagationPolicy *ConfigPropagationPolicy) DeepCopy() *ConfigPropagationPolicy {
+	if configPropagationPolicy == nil {
+		return nil
+	}
+
+	out := new(ConfigPropagationPolicy)
+
+	configPropagationPolicy.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject returns a deep copy as a runtime.Object.
+func (configPropagationPolicy *ConfigPropagationPolicy) DeepCopyObject() runtime.Object {
+	if configPropagationPolicy == nil {
+		return nil
+	}
+
+	return configPropagationPolicy.DeepCopy()
+}
+
+// DeepCopyInto copies the receiver into out.
+func (configPropagationPolicyList *ConfigPropagationPolicyList) DeepCopyInto(out *ConfigPropagationPolicyList) {
+	if configPropagationPolicyList == nil || out == nil {
+		return
+	}
+	*out = *configPropagationPolicyList
+	configPropagationPolicyList.ListMeta.DeepCopyInto(&out.ListMeta)
+
+	if configPropagationPolicyList.Items != nil {
+		out.Items = make([]ConfigPropagationPolicy, len(configPropagationPolicyList.Items))
+
+		for index := range configPropagationPolicyList.Items {
+			configPropagationPolicyList.Items[index].DeepCopyInto(&out.Items[index])
+		}
+	}
+}
+
+// DeepCopy creates a new deep copy of the list.
+func (configPropagationPolicyList *ConfigPropagationPolicyList) DeepCopy() *ConfigPropagationPolicyList {
+	if configPropagationPolicyList == nil {
+		return nil
+	}
+
+	out := new(ConfigPropagationPolicyList)
+
+	configPropagationPolicyList.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject returns a deep copy of the list as a runtime.Object.
+func (configPropagationPolicyList *ConfigPropagationPolicyList) DeepCopyObject() runtime.Object {
+	if configPropagationPolicyList == nil {
+		return nil
+	}
+
+	return configPropagationPolicyList.DeepCopy()
+}
+
+// deepCopyPolicySpec creates a deep copy of the provided policy spec.
+func deepCopyPolicySpec(source *core.ConfigPropagationPolicySpec) core.ConfigPropagationPolicySpec {
+	copiedSpec := *source
+
+	if source.AllowedSourceNamespaces != nil {
+		copiedSpec.AllowedSourceNamespaces = append([]string(nil), source.AllowedSourceNamespaces...)
+	}
+
+	if source.SelectorRules != nil {
+		copiedSpec.SelectorRules = make([]core.SelectorRule, len(source.SelectorRules))
+		for index, rule := range source.SelectorRules {
+			copiedSpec.SelectorRules[index] = core.SelectorRule{
+				Namespaces:       append([]string(nil), rule.Namespaces...),
+				AllowedLabelKeys: append([]string(nil), rule.AllowedLabelKeys...),
+			}
+		}
+	}
+
+	if source.MaxTargets != nil {
+		maxTargetsCopy := *source.MaxTargets
+		copiedSpec.MaxTargets = &maxTargetsCopy
+	}
+
+	if source.AllowedStrategies != nil {
+		copiedSpec.AllowedStrategies = append([]string(nil), source.AllowedStrategies...)
+	}
+
+	if source.AllowPruneDisabled != nil {
+		allowPruneDisabledCopy := *source.AllowPruneDisabled
+		copiedSpec.AllowPruneDisabled = &allowPruneDisabledCopy
+	}
+
+	return copiedSpec
+}
@@ -1,56 +1,27 @@
 package v1alpha1
 
 import (
+	"errors"
 	"fmt"
+	"sort"
+	"strings"
 	"time"
 
 	"k8s.io/apimachinery/pkg/runtime"
 
-	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
-	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
 
 	"configpropagation/pkg/core"
 )
 
+// ConfigPropagation only defaults itself; validation lives in pkg/adapters/webhooks, which can read the cluster.
 var _ webhook.Defaulter = &ConfigPropagation{}
-var _ webhook.Validator = &ConfigPropagation{}
 var _ runtime.Object = &ConfigPropagation{}
 var _ runtime.Object = &ConfigPropagationList{}
 
 // Default implements webhook.Defaulter.
 func (configPropagation *ConfigPropagation) Default() { core.DefaultSpec(&configPropagation.Spec) }
 
-// SetupWebhookWithManager registers the webhook with the provided manager.
-func (configPropagation *ConfigPropagation) SetupWebhookWithManager(manager ctrl.Manager) error {
-	return ctrl.NewWebhookManagedBy(manager).
-		For(configPropagation).
-		Complete()
-}
-
-// ValidateCreate implements webhook.Validator.
-func (configPropagation *ConfigPropagation) ValidateCreate() (admission.Warnings, error) {
-	if err := core.ValidateSpec(&configPropagation.Spec); err != nil {
-		return nil, err
-	}
-
-	return nil, nil
-}
-
-// ValidateUpdate implements webhook.Validator.
-func (configPropagation *ConfigPropagation) ValidateUpdate(runtime.Object) (admission.Warnings, error) {
-	if err := core.ValidateSpec(&configPropagation.Spec); err != nil {
-		return nil, err
-	}
-
-	return nil, nil
-}
-
-// ValidateDelete implements webhook.Validator.
-func (configPropagation *ConfigPropagation) ValidateDelete() (admission.Warnings, error) {
-	return nil, nil
-}
-
 // ApplyRolloutStatus updates status fields after a reconcile using rollout progress.
 func (configPropagation *ConfigPropagation) ApplyRolloutStatus(result core.RolloutResult) {
 	currentTime := time.Now().UTC().Format(time.RFC3339)
@@ -68,6 +39,10 @@ func (configPropagation *ConfigPropagation) ApplyRolloutStatus(result core.Rollo
 	} else {
 		configPropagation.Status.OutOfSync = nil
 	}
+	configPropagation.Status.CleanupFailures = nil
+	if len(result.CleanupFailures) > 0 {
+		configPropagation.Status.CleanupFailures = append([]core.OutOfSyncItem(nil), result.CleanupFailures...)
+	}
 	readyCondition := core.Condition{
 		Type:               core.CondReady,
 		LastTransitionTime: currentTime,
@@ -84,7 +59,46 @@ func (configPropagation *ConfigPropagation) ApplyRolloutStatus(result core.Rollo
 		LastTransitionTime: currentTime,
 	}
 
-	if pendingCount > 0 {
+	failureCounts := map[string]int{}
+	failedCount := 0
+	for _, item := range result.OutOfSync {
+		if core.IsFailureReason(item.Reason) {
+			failureCounts[item.Reason]++
+			failedCount++
+		}
+	}
+	// Cleanup failures degrade the CR like failed writes but are not part of the selected namespaces.
+	for _, item := range result.CleanupFailures {
+		failureCounts[item.Reason]++
+	}
+	cleanupFailedCount := len(result.CleanupFailures)
+
+	if failedCount+cleanupFailedCount > 0 {
+		readyCondition.Status = "False"
+		readyCondition.Reason = "TargetErrors"
+		readyCondition.Message = fmt.Sprintf("propagated to %d/%d namespaces, %d failed", result.CompletedCount, result.TotalTargets, failedCount)
+		if cleanupFailedCount > 0 {
+			readyCondition.Message += fmt.Sprintf(", %d could not be cleaned up", cleanupFailedCount)
+		}
+
+		if remaining := pendingCount - failedCount; remaining > 0 {
+			progressingCondition.Status = "True"
+			progressingCondition.Reason = "RollingUpdate"
+			progressingCondition.Message = fmt.Sprintf("updating %d remaining namespaces", remaining)
+		} else if failedCount > 0 {
+			progressingCondition.Status = "False"
+			progressingCondition.Reason = "TargetErrors"
+			progressingCondition.Message = "remaining namespaces are failing; see outOfSync"
+		} else {
+			progressingCondition.Status = "False"
+			progressingCondition.Reason = "RolloutComplete"
+			progressingCondition.Message = "all target namespaces synchronized; see cleanupFailures"
+		}
+
+		degradedCondition.Status = "True"
+		degradedCondition.Reason = "TargetErrors"
+		degradedCondition.Message = fmt.Sprintf("%d namespaces failed: %s", failedCount+cleanupFailedCount, summarizeFailureCounts(failureCounts))
+	} else if pendingCount > 0 {
 		readyCondition.Status = "False"
 		readyCondition.Reason = "RollingUpdate"
 		readyCondition.Message = fmt.Sprintf("propagated to %d/%d namespaces (batch of %d)", result.CompletedCount, result.TotalTargets, len(result.Planned))
@@ -103,9 +117,95 @@ func (configPropagation *ConfigPropagation) ApplyRolloutStatus(result core.Rollo
 	}
 
 	configPropagation.Status.Conditions = []core.Condition{readyCondition, progressingCondition, degradedCondition}
+	configPropagation.Status.Rollout = deepCopyRolloutStatus(result.Rollout)
+	configPropagation.Status.MatchedKeys = deepCopySourceKeys(result.MatchedKeys)
+	configPropagation.Status.Plan = nil
+}
+
+// ApplyPausedStatus reports spec.paused through the Progressing condition. Ready and Degraded keep describing the
+// last reconcile, so a paused CR still shows how far its rollout got.
+func (configPropagation *ConfigPropagation) ApplyPausedStatus() {
+	pausedCondition := core.Condition{
+		Type:               core.CondProgressing,
+		Status:             "False",
+		Reason:             core.ReasonPaused,
+		Message:            "spec.paused is set; sources are not read and targets are not written",
+		LastTransitionTime: time.Now().UTC().Format(time.RFC3339),
+	}
+
+	for index, condition := range configPropagation.Status.Conditions {
+		if condition.Type == core.CondProgressing {
+			configPropagation.Status.Conditions[index] = pausedCondition
+			return
+		}
+	}
+
+	configPropagation.Status.Conditions = append(configPropagation.Status.Conditions, pausedCondition)
+}
+
+// IsReportedPaused reports whether the status already reflects spec.paused.
+func (configPropagation *ConfigPropagation) IsReportedPaused() bool {
+	for _, condition := range configPropagation.Status.Conditions {
+		if condition.Type == core.CondProgressing {
+			return condition.Reason == core.ReasonPaused
+		}
+	}
+
+	return false
+}
+
+// ApplyPlanStatus records a dry-run plan. Ready and Progressing stay False with the DryRun reason, since nothing
+// is written; the counts and outOfSync of the last real reconcile are kept.
+func (configPropagation *ConfigPropagation) ApplyPlanStatus(plan *core.PlanStatus) {
+	currentTime := time.Now().UTC().Format(time.RFC3339)
+
+	configPropagation.Status.LastSyncTime = currentTime
+	configPropagation.Status.Plan = deepCopyPlanStatus(plan)
+	configPropagation.Status.Conditions = []core.Condition{
+		{
+			Type:               core.CondReady,
+			Status:             "False",
+			Reason:             "DryRun",
+			Message:            "dry run: " + plan.Summary(),
+			LastTransitionTime: currentTime,
+		},
+		{
+			Type:               core.CondProgressing,
+			Status:             "False",
+			Reason:             "DryRun",
+			Message:            "spec.dryRun is set; targets are not written",
+			LastTransitionTime: currentTime,
+		},
+		{
+			Type:               core.CondDegraded,
+			Status:             "False",
+			Reason:             "Healthy",
+			Message:            "no reconcile errors detected",
+			LastTransitionTime: currentTime,
+		},
+	}
+}
+
+// summarizeFailureCounts renders per-reason failure counts in a stable order, e.g. "Forbidden=2, TransientError=1".
+func summarizeFailureCounts(failureCounts map[string]int) string {
+	reasons := make([]string, 0, len(failureCounts))
+	for reason := range failureCounts {
+		reasons = append(reasons, reason)
+	}
+
+	sort.Strings(reasons)
+
+	parts := make([]string, 0, len(reasons))
+	for _, reason := range reasons {
+		parts = append(parts, fmt.Sprintf("%s=%d", reason, failureCounts[reason]))
+	}
+
+	return strings.Join(parts, ", ")
 }
 
 // ApplyErrorStatus marks the resource as Degraded when reconciliation fails.
+// A ConfigPropagationPolicy violation is reported with the PolicyViolation reason, and a source that has not
+// opted in to this namespace additionally sets the SourceNotShareable condition.
 func (configPropagation *ConfigPropagation) ApplyErrorStatus(reconcileErr error) {
 	currentTime := time.Now().UTC().Format(time.RFC3339)
 	message := ""
@@ -113,30 +213,52 @@ func (configPropagation *ConfigPropagation) ApplyErrorStatus(reconcileErr error)
 		message = reconcileErr.Error()
 	}
 
+	readyReason, degradedReason, progressingMessage := "Error", "ReconcileError", "reconcile halted due to error"
+
+	var policyViolation *core.PolicyViolationError
+	var notShareable *core.SourceNotShareableError
+	switch {
+	case errors.As(reconcileErr, &policyViolation):
+		readyReason, degradedReason, progressingMessage = "PolicyViolation", "PolicyViolation", "reconcile halted by ConfigPropagationPolicy "+policyViolation.Policy
+	case errors.As(reconcileErr, &notShareable):
+		readyReason, degradedReason, progressingMessage = core.CondSourceNotShareable, core.CondSourceNotShareable, "reconcile halted until the source opts in to this namespace"
+	}
+
 	configPropagation.Status.LastSyncTime = currentTime
+	configPropagation.Status.Plan = nil
 	configPropagation.Status.Conditions = []core.Condition{
 		{
 			Type:               core.CondReady,
 			Status:             "False",
-			Reason:             "Error",
+			Reason:             readyReason,
 			Message:            message,
 			LastTransitionTime: currentTime,
 		},
 		{
 			Type:               core.CondProgressing,
 			Status:             "False",
-			Reason:             "Error",
-			Message:            "reconcile halted due to error",
+			Reason:             readyReason,
+			Message:            progressingMessage,
 			LastTransitionTime: currentTime,
 		},
 		{
 			Type:               core.CondDegraded,
 			Status:             "True",
-			Reason:             "ReconcileError",
+			Reason:             degradedReason,
 			Message:            message,
 			LastTransitionTime: currentTime,
 		},
 	}
+
+	if notShareable != nil {
+		configPropagation.Status.Conditions = append(configPropagation.Status.Conditions, core.Condition{
+			Type:               core.CondSourceNotShareable,
+			Status:             "True",
+			Reason:             "MissingOptIn",
+			Message:            message,
+			LastTransitionTime: currentTime,
+		})
+	}
 }
 
 // DeepCopyInto copies the receiver into out.
@@ -244,6 +366,10 @@ func deepCopySpec(source *core.ConfigPropagationSpec) core.ConfigPropagationSpec
 		copiedSpec.DataKeys = append([]string(nil), source.DataKeys...)
 	}
 
+	if source.ExcludeKeys != nil {
+		copiedSpec.ExcludeKeys = append([]string(nil), source.ExcludeKeys...)
+	}
+
 	if source.Strategy != nil {
 		strategyCopy := *source.Strategy
 
@@ -273,6 +399,52 @@ func deepCopySpec(source *core.ConfigPropagationSpec) core.ConfigPropagationSpec
 		copiedSpec.ResyncPeriodSeconds = nil
 	}
 
+	if source.Target != nil {
+		targetCopy := *source.Target
+		if source.Target.Labels != nil {
+			targetCopy.Labels = make(map[string]string, len(source.Target.Labels))
+			for key, value := range source.Target.Labels {
+				targetCopy.Labels[key] = value
+			}
+		}
+		if source.Target.Annotations != nil {
+			targetCopy.Annotations = make(map[string]string, len(source.Target.Annotations))
+			for key, value := range source.Target.Annotations {
+				targetCopy.Annotations[key] = value
+			}
+		}
+		copiedSpec.Target = &targetCopy
+	}
+
+	if source.Sources != nil {
+		copiedSpec.Sources = make([]core.SourceSpec, len(source.Sources))
+
+		for index, sourceLayer := range source.Sources {
+			copiedSpec.Sources[index] = sourceLayer
+			if sourceLayer.DataKeys != nil {
+				copiedSpec.Sources[index].DataKeys = append([]string(nil), sourceLayer.DataKeys...)
+			}
+			if sourceLayer.ExcludeKeys != nil {
+				copiedSpec.Sources[index].ExcludeKeys = append([]string(nil), sourceLayer.ExcludeKeys...)
+			}
+		}
+	}
+
+	if source.KeyMappings != nil {
+		copiedSpec.KeyMappings = append([]core.KeyMapping(nil), source.KeyMappings...)
+	}
+
+	if source.Template != nil {
+		templateCopy := *source.Template
+		if source.Template.Values != nil {
+			templateCopy.Values = make(map[string]string, len(source.Template.Values))
+			for key, value := range source.Template.Values {
+				templateCopy.Values[key] = value
+			}
+		}
+		copiedSpec.Template = &templateCopy
+	}
+
 	return copiedSpec
 }
 
@@ -291,5 +463,59 @@ func deepCopyStatus(source *core.ConfigPropagationStatus) core.ConfigPropagation
 		copiedStatus.OutOfSync = append([]core.OutOfSyncItem(nil), source.OutOfSync...)
 	}
 
+	if source.CleanupFailures != nil {
+		copiedStatus.CleanupFailures = append([]core.OutOfSyncItem(nil), source.CleanupFailures...)
+	}
+
+	copiedStatus.Rollout = deepCopyRolloutStatus(source.Rollout)
+	copiedStatus.MatchedKeys = deepCopySourceKeys(source.MatchedKeys)
+	copiedStatus.Plan = deepCopyPlanStatus(source.Plan)
+
 	return copiedStatus
 }
+
+// deepCopyPlanStatus creates a deep copy of a dry-run plan.
+func deepCopyPlanStatus(source *core.PlanStatus) *core.PlanStatus {
+	if source == nil {
+		return nil
+	}
+	copiedPlan := *source
+
+	if source.Targets != nil {
+		copiedPlan.Targets = append([]core.PlannedTarget(nil), source.Targets...)
+	}
+
+	return &copiedPlan
+}
+
+// deepCopyRolloutStatus creates a deep copy of the persisted rollout progress.
+func deepCopyRolloutStatus(source *core.RolloutStatus) *core.RolloutStatus {
+	if source == nil {
+		return nil
+	}
+	copiedRollout := *source
+
+	if source.CompletedNamespaces != nil {
+		copiedRollout.CompletedNamespaces = append([]string(nil), source.CompletedNamespaces...)
+	}
+
+	if source.CurrentBatch != nil {
+		copiedRollout.CurrentBatch = append([]string(nil), source.CurrentBatch...)
+	}
+
+	return &copiedRollout
+}
+
+// deepCopySourceKeys creates a deep copy of the matched keys reported per source.
+func deepCopySourceKeys(source []core.SourceKeys) []core.SourceKeys {
+	if source == nil {
+		return nil
+	}
+
+	copiedKeys := make([]core.SourceKeys, len(source))
+	for index, sourceKeys := range source {
+		copiedKeys[index] = core.SourceKeys{Source: sourceKeys.Source, Keys: append([]string(nil), sourceKeys.Keys...)}
+	}
+
+	return copiedKeys
+}
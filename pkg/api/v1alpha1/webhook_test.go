@@ -2,6 +2,7 @@ package v1alpha1
 
 import (
 	"fmt"
+	"strings"
 	"testing"
 
 	"configpropagation/pkg/core"
@@ -17,6 +18,9 @@ func TestApplyRolloutStatusImmediate(t *testing.T) {
 	if cp.Status.OutOfSync != nil {
 		t.Fatalf("expected no outOfSync entries, got %+v", cp.Status.OutOfSync)
 	}
+	if cp.Status.Rollout != nil {
+		t.Fatalf("expected no rollout progress for immediate strategy, got %+v", cp.Status.Rollout)
+	}
 	if len(cp.Status.Conditions) != 3 {
 		t.Fatalf("expected three conditions, got %+v", cp.Status.Conditions)
 	}
@@ -45,8 +49,16 @@ func TestApplyRolloutStatusRolling(t *testing.T) {
 			{Namespace: "ns-b", Reason: "PendingRollout"},
 			{Namespace: "ns-c", Reason: "PendingRollout"},
 		},
+		Rollout: &core.RolloutStatus{Hash: "h1", CompletedNamespaces: []string{"ns-x", "ns-y"}, CurrentBatch: []string{"batch"}, StartedAt: "2024-01-02T03:04:05Z"},
 	}
 	cp.ApplyRolloutStatus(result)
+	if cp.Status.Rollout == nil || cp.Status.Rollout.Hash != "h1" || len(cp.Status.Rollout.CompletedNamespaces) != 2 {
+		t.Fatalf("expected rollout progress to be persisted, got %+v", cp.Status.Rollout)
+	}
+	result.Rollout.CompletedNamespaces[0] = "mutated"
+	if cp.Status.Rollout.CompletedNamespaces[0] != "ns-x" {
+		t.Fatalf("expected rollout status to be copied, got %+v", cp.Status.Rollout)
+	}
 	if cp.Status.TargetCount != 5 || cp.Status.SyncedCount != 2 || cp.Status.OutOfSyncCount != 3 {
 		t.Fatalf("unexpected status counters for rolling: %+v", cp.Status)
 	}
@@ -67,6 +79,70 @@ func TestApplyRolloutStatusRolling(t *testing.T) {
 	}
 }
 
+func TestApplyRolloutStatusReportsTargetFailures(t *testing.T) {
+	cp := &ConfigPropagation{}
+	result := core.RolloutResult{
+		Planned:        []string{"ns-a", "ns-b", "ns-c", "ns-d"},
+		TotalTargets:   5,
+		CompletedCount: 2,
+		OutOfSync: []core.OutOfSyncItem{
+			{Namespace: "ns-b", Reason: core.ReasonForbidden, Message: "upsert ns-b/cfg: forbidden"},
+			{Namespace: "ns-c", Reason: core.ReasonForbidden, Message: "upsert ns-c/cfg: forbidden"},
+			{Namespace: "ns-d", Reason: core.ReasonTransientError, Message: "upsert ns-d/cfg: timeout"},
+		},
+	}
+	cp.ApplyRolloutStatus(result)
+	if cp.Status.OutOfSyncCount != 3 {
+		t.Fatalf("expected failures to be counted as out of sync, got %+v", cp.Status)
+	}
+	ready := conditionByType(t, cp.Status.Conditions, core.CondReady)
+	if ready.Status != "False" || ready.Reason != "TargetErrors" {
+		t.Fatalf("expected Ready False/TargetErrors, got %+v", ready)
+	}
+	progressing := conditionByType(t, cp.Status.Conditions, core.CondProgressing)
+	if progressing.Status != "False" || progressing.Reason != "TargetErrors" {
+		t.Fatalf("expected Progressing False/TargetErrors when only failures remain, got %+v", progressing)
+	}
+	degraded := conditionByType(t, cp.Status.Conditions, core.CondDegraded)
+	if degraded.Status != "True" || degraded.Reason != "TargetErrors" {
+		t.Fatalf("expected Degraded True/TargetErrors, got %+v", degraded)
+	}
+	if degraded.Message != "3 namespaces failed: Forbidden=2, TransientError=1" {
+		t.Fatalf("unexpected degraded summary: %q", degraded.Message)
+	}
+}
+
+func TestApplyRolloutStatusKeepsCleanupFailuresOutOfCounts(t *testing.T) {
+	cp := &ConfigPropagation{}
+	result := core.RolloutResult{
+		Planned:         []string{"ns-a"},
+		TotalTargets:    1,
+		CompletedCount:  1,
+		CleanupFailures: []core.OutOfSyncItem{{Namespace: "ns-old", Reason: core.ReasonForbidden, Message: "delete ns-old/cfg: forbidden"}},
+	}
+	cp.ApplyRolloutStatus(result)
+	if cp.Status.OutOfSyncCount != 0 || len(cp.Status.OutOfSync) != 0 || len(cp.Status.CleanupFailures) != 1 {
+		t.Fatalf("expected the cleanup failure to be reported apart from outOfSync, got %+v", cp.Status)
+	}
+	ready := conditionByType(t, cp.Status.Conditions, core.CondReady)
+	if ready.Status != "False" || ready.Message != "propagated to 1/1 namespaces, 0 failed, 1 could not be cleaned up" {
+		t.Fatalf("expected Ready False with the cleanup failure, got %+v", ready)
+	}
+	progressing := conditionByType(t, cp.Status.Conditions, core.CondProgressing)
+	if progressing.Status != "False" || progressing.Reason != "RolloutComplete" {
+		t.Fatalf("expected the rollout to be complete, got %+v", progressing)
+	}
+	degraded := conditionByType(t, cp.Status.Conditions, core.CondDegraded)
+	if degraded.Status != "True" || degraded.Message != "1 namespaces failed: Forbidden=1" {
+		t.Fatalf("expected Degraded for the cleanup failure, got %+v", degraded)
+	}
+
+	cp.ApplyRolloutStatus(core.RolloutResult{TotalTargets: 1, CompletedCount: 1})
+	if cp.Status.CleanupFailures != nil {
+		t.Fatalf("expected cleanup failures to be cleared once resolved, got %+v", cp.Status.CleanupFailures)
+	}
+}
+
 func TestApplyErrorStatusSetsDegraded(t *testing.T) {
 	cp := &ConfigPropagation{}
 	cp.ApplyErrorStatus(fmt.Errorf("boom"))
@@ -90,6 +166,61 @@ func TestApplyErrorStatusSetsDegraded(t *testing.T) {
 	}
 }
 
+func TestApplyErrorStatusReportsPolicyViolation(t *testing.T) {
+	cp := &ConfigPropagation{}
+	cp.ApplyErrorStatus(fmt.Errorf("policy check: %w", &core.PolicyViolationError{Policy: "platform", Violations: []string{"prune: false is not allowed"}}))
+
+	ready := conditionByType(t, cp.Status.Conditions, core.CondReady)
+	if ready.Reason != "PolicyViolation" || ready.Message != "policy check: violates ConfigPropagationPolicy platform: prune: false is not allowed" {
+		t.Fatalf("expected Ready to carry the violation, got %+v", ready)
+	}
+	degraded := conditionByType(t, cp.Status.Conditions, core.CondDegraded)
+	if degraded.Status != "True" || degraded.Reason != "PolicyViolation" {
+		t.Fatalf("expected Degraded True/PolicyViolation, got %+v", degraded)
+	}
+}
+
+func TestApplyErrorStatusReportsSourceNotShareable(t *testing.T) {
+	cp := &ConfigPropagation{}
+	cp.ApplyErrorStatus(&core.SourceNotShareableError{Source: core.ObjectRef{Namespace: "platform", Name: "base"}, Namespace: "team-a"})
+
+	if len(cp.Status.Conditions) != 4 {
+		t.Fatalf("expected the SourceNotShareable condition next to the usual three, got %+v", cp.Status.Conditions)
+	}
+	notShareable := conditionByType(t, cp.Status.Conditions, core.CondSourceNotShareable)
+	if notShareable.Status != "True" || notShareable.Reason != "MissingOptIn" || !strings.Contains(notShareable.Message, "platform/base") {
+		t.Fatalf("unexpected SourceNotShareable condition %+v", notShareable)
+	}
+	ready := conditionByType(t, cp.Status.Conditions, core.CondReady)
+	if ready.Status != "False" || ready.Reason != core.CondSourceNotShareable {
+		t.Fatalf("expected Ready False/SourceNotShareable, got %+v", ready)
+	}
+
+	cp.ApplyErrorStatus(fmt.Errorf("boom"))
+	if len(cp.Status.Conditions) != 3 {
+		t.Fatalf("expected the condition to clear on other errors, got %+v", cp.Status.Conditions)
+	}
+}
+
+func TestConfigPropagationPolicyListPoliciesCopiesSpecs(t *testing.T) {
+	maxTargets := int32(2)
+	list := &ConfigPropagationPolicyList{Items: []ConfigPropagationPolicy{{
+		Spec: ConfigPropagationPolicySpec{AllowedStrategies: []string{core.StrategyRolling}, MaxTargets: &maxTargets},
+	}}}
+	list.Items[0].Name = "platform"
+
+	policies := list.DeepCopy().Policies()
+	if len(policies) != 1 || policies[0].Name != "platform" || *policies[0].Spec.MaxTargets != 2 {
+		t.Fatalf("unexpected policies: %+v", policies)
+	}
+
+	*policies[0].Spec.MaxTargets = 9
+	policies[0].Spec.AllowedStrategies[0] = core.StrategyImmediate
+	if *list.Items[0].Spec.MaxTargets != 2 || list.Items[0].Spec.AllowedStrategies[0] != core.StrategyRolling {
+		t.Fatalf("expected policies to be copies, list changed to %+v", list.Items[0].Spec)
+	}
+}
+
 func conditionByType(t *testing.T, conditions []core.Condition, conditionType string) core.Condition {
 	t.Helper()
 	for _, condition := range conditions {
@@ -100,3 +231,38 @@ func conditionByType(t *testing.T, conditions []core.Condition, conditionType st
 	t.Fatalf("condition %s not found in %+v", conditionType, conditions)
 	return core.Condition{}
 }
+
+func TestApplyRolloutStatusCopiesMatchedKeys(t *testing.T) {
+	cp := &ConfigPropagation{}
+	matched := []core.SourceKeys{{Source: "platform/features", Keys: []string{"feature-a.yaml"}}}
+	cp.ApplyRolloutStatus(core.RolloutResult{MatchedKeys: matched})
+
+	matched[0].Keys[0] = "mutated"
+	if len(cp.Status.MatchedKeys) != 1 || cp.Status.MatchedKeys[0].Keys[0] != "feature-a.yaml" {
+		t.Fatalf("expected matched keys to be copied into status, got %+v", cp.Status.MatchedKeys)
+	}
+
+	cp.ApplyRolloutStatus(core.RolloutResult{})
+	if cp.Status.MatchedKeys != nil {
+		t.Fatalf("expected matched keys to clear, got %+v", cp.Status.MatchedKeys)
+	}
+}
+
+func TestApplyPausedStatusOnlyReplacesProgressing(t *testing.T) {
+	cp := &ConfigPropagation{}
+	cp.ApplyRolloutStatus(core.RolloutResult{TotalTargets: 2, CompletedCount: 2})
+	if cp.IsReportedPaused() {
+		t.Fatalf("expected a reconciled CR not to be reported paused")
+	}
+
+	cp.ApplyPausedStatus()
+
+	progressing := conditionByType(t, cp.Status.Conditions, core.CondProgressing)
+	if progressing.Status != "False" || progressing.Reason != core.ReasonPaused || !cp.IsReportedPaused() {
+		t.Fatalf("expected Progressing False/Paused, got %+v", progressing)
+	}
+
+	if ready := conditionByType(t, cp.Status.Conditions, core.CondReady); ready.Reason != "Reconciled" || len(cp.Status.Conditions) != 3 {
+		t.Fatalf("expected Ready to keep the last reconcile, got %+v", cp.Status.Conditions)
+	}
+}
@@ -0,0 +1,71 @@
+package configpropagation
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"configpropagation/pkg/core"
+)
+
+// applyingClient is a memoryKubeClient that writes through server-side apply and can refuse unforced applies.
+type applyingClient struct {
+	mutex sync.Mutex
+	*memoryKubeClient
+	foreignOwned bool
+	forced       []bool
+}
+
+func (client *applyingClient) ApplyConfigMap(requestContext context.Context, namespace, name string, data map[string]string, binaryData map[string][]byte, labels, annotations map[string]string, force bool) error {
+	client.mutex.Lock()
+	defer client.mutex.Unlock()
+
+	client.forced = append(client.forced, force)
+
+	if client.foreignOwned && !force {
+		return apierrors.NewApplyConflict([]metav1.StatusCause{{Type: metav1.CauseTypeFieldManagerConflict, Field: ".data.log"}}, "conflict with \"kubectl-edit\"")
+	}
+
+	return client.UpsertConfigMap(requestContext, namespace, name, data, binaryData, labels, annotations)
+}
+
+func TestServerSideApplyFollowsConflictPolicy(t *testing.T) {
+	for _, conflictPolicy := range []string{core.ConflictOverwrite, core.ConflictSkip} {
+		client := &applyingClient{memoryKubeClient: newMemoryKubeClient("team-a"), foreignOwned: true}
+		client.put("platform", "app", &memoryConfigMap{data: map[string]string{"log": "info"}})
+
+		spec := &core.ConfigPropagationSpec{
+			SourceRef:         core.ObjectRef{Namespace: "platform", Name: "app"},
+			NamespaceSelector: &core.LabelSelector{},
+			Strategy:          &core.UpdateStrategy{Type: core.StrategyImmediate},
+			ConflictPolicy:    conflictPolicy,
+		}
+
+		result, err := NewReconciler(client, nil, nil).Reconcile(context.Background(), Key{Namespace: "default", Name: "cp"}, spec)
+		if err != nil {
+			t.Fatalf("%s: reconcile: %v", conflictPolicy, err)
+		}
+
+		if len(client.forced) != 1 || client.forced[0] != (conflictPolicy == core.ConflictOverwrite) {
+			t.Fatalf("%s: expected force only under overwrite, got %v", conflictPolicy, client.forced)
+		}
+
+		_, written := client.configMaps[[2]string{"team-a", "app"}]
+		switch conflictPolicy {
+		case core.ConflictOverwrite:
+			if !written || len(result.OutOfSync) != 0 {
+				t.Fatalf("overwrite: expected forced write, got %+v", result.OutOfSync)
+			}
+		case core.ConflictSkip:
+			if written || len(result.OutOfSync) != 1 || result.OutOfSync[0].Reason != core.ReasonFieldManagerConflict {
+				t.Fatalf("skip: expected FieldManagerConflict, got %+v", result.OutOfSync)
+			}
+			if core.IsFailureReason(result.OutOfSync[0].Reason) {
+				t.Fatalf("skip: a deliberate skip must not count as a failure")
+			}
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package configpropagation
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+
+	"configpropagation/pkg/core"
+)
+
+// cancellingClient is a memoryKubeClient that cancels the reconcile once it has written a given number of targets,
+// the way a manager shutting down or losing leadership cancels the context of an in-flight reconcile.
+type cancellingClient struct {
+	mutex sync.Mutex
+	*memoryKubeClient
+	cancel      context.CancelFunc
+	writesLeft  int
+	writtenKeys []string
+}
+
+func (client *cancellingClient) UpsertConfigMap(requestContext context.Context, namespace, name string, data map[string]string, binaryData map[string][]byte, labels, annotations map[string]string) error {
+	client.mutex.Lock()
+	defer client.mutex.Unlock()
+
+	client.writtenKeys = append(client.writtenKeys, namespace+"/"+name)
+
+	client.writesLeft--
+	if client.writesLeft == 0 {
+		client.cancel()
+	}
+
+	return client.memoryKubeClient.UpsertConfigMap(requestContext, namespace, name, data, binaryData, labels, annotations)
+}
+
+func TestCancelledReconcileStopsWritingAndPruning(t *testing.T) {
+	requestContext, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	client := &cancellingClient{memoryKubeClient: newMemoryKubeClient("team-a", "team-b", "team-c"), cancel: cancel, writesLeft: 1}
+	client.put("platform", "app", &memoryConfigMap{data: map[string]string{"log": "info"}})
+	// A copy left in a namespace that is no longer selected; pruning it must wait for an uncancelled reconcile.
+	client.put("retired", "app", &memoryConfigMap{
+		data:        map[string]string{"log": "info"},
+		labels:      map[string]string{core.ManagedLabel: "true"},
+		annotations: map[string]string{core.SourceAnnotation: "platform/app", core.OwnerAnnotation: "default/cp"},
+	})
+
+	spec := &core.ConfigPropagationSpec{
+		SourceRef:         core.ObjectRef{Namespace: "platform", Name: "app"},
+		NamespaceSelector: &core.LabelSelector{},
+		Strategy:          &core.UpdateStrategy{Type: core.StrategyImmediate},
+	}
+
+	// A single worker makes the point of cancellation deterministic.
+	reconciler := NewReconciler(client, nil, nil)
+	reconciler.targetWorkers = 1
+
+	_, err := reconciler.Reconcile(requestContext, Key{Namespace: "default", Name: "cp"}, spec)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected the reconcile to report cancellation, got %v", err)
+	}
+
+	if want := []string{"team-a/app"}; !reflect.DeepEqual(client.writtenKeys, want) {
+		t.Fatalf("expected writes to stop after cancellation, wrote %v", client.writtenKeys)
+	}
+
+	if len(client.deletes) != 0 {
+		t.Fatalf("expected no prunes from a cancelled reconcile, got %v", client.deletes)
+	}
+
+	result, err := NewReconciler(client, nil, nil).Reconcile(context.Background(), Key{Namespace: "default", Name: "cp"}, spec)
+	if err != nil || len(result.OutOfSync) != 0 {
+		t.Fatalf("expected the next reconcile to finish the work, got %+v %v", result.OutOfSync, err)
+	}
+
+	if want := []string{"platform/app", "team-a/app", "team-b/app", "team-c/app"}; !reflect.DeepEqual(client.names(), want) {
+		t.Fatalf("expected every target written and the retired copy pruned, got %v", client.names())
+	}
+}
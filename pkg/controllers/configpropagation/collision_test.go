@@ -0,0 +1,84 @@
+package configpropagation
+
+import (
+	"context"
+	"testing"
+
+	"configpropagation/pkg/core"
+)
+
+func TestTargetOwnedByAnotherPropagationIsLeftAlone(t *testing.T) {
+	client := newMemoryKubeClient("team-a")
+	client.put("platform", "app", &memoryConfigMap{data: map[string]string{"log": "info"}})
+	client.put("platform", "app-v2", &memoryConfigMap{data: map[string]string{"log": "debug"}})
+
+	first := &core.ConfigPropagationSpec{
+		SourceRef:         core.ObjectRef{Namespace: "platform", Name: "app"},
+		NamespaceSelector: &core.LabelSelector{},
+		Strategy:          &core.UpdateStrategy{Type: core.StrategyImmediate},
+	}
+	if _, err := NewReconciler(client, nil, nil).Reconcile(context.Background(), Key{Namespace: "team-a", Name: "cp"}, first); err != nil {
+		t.Fatalf("reconcile first: %v", err)
+	}
+
+	// A second CR writes a different source under the same target name.
+	second := &core.ConfigPropagationSpec{
+		SourceRef:         core.ObjectRef{Namespace: "platform", Name: "app-v2"},
+		NamespaceSelector: &core.LabelSelector{},
+		Strategy:          &core.UpdateStrategy{Type: core.StrategyRolling},
+		Target:            &core.TargetSpec{Name: "app"},
+	}
+	eventRecorder := &capturingEventRecorder{}
+	result, err := NewReconciler(client, eventRecorder, nil).Reconcile(context.Background(), Key{Namespace: "team-b", Name: "cp"}, second)
+	if err != nil {
+		t.Fatalf("reconcile second: %v", err)
+	}
+
+	if len(result.OutOfSync) != 1 || result.OutOfSync[0].Reason != core.ReasonOwnedByOther || result.CompletedCount != 0 {
+		t.Fatalf("expected OwnedByOther, got %+v", result)
+	}
+
+	if !hasEvent(eventRecorder, eventReasonConfigClaimed, "Warning") {
+		t.Fatalf("expected a warning event, got %+v", eventRecorder.events)
+	}
+
+	target := client.configMaps[[2]string{"team-a", "app"}]
+	if target.data["log"] != "info" || target.annotations[core.OwnerAnnotation] != "team-a/cp" {
+		t.Fatalf("expected the first CR's target to be untouched, got %+v %+v", target.data, target.annotations)
+	}
+}
+
+func TestRecreatedPropagationLeavesPredecessorTargetsAlone(t *testing.T) {
+	client := newMemoryKubeClient("team-a")
+	client.put("platform", "app", &memoryConfigMap{data: map[string]string{"log": "info"}})
+	spec := &core.ConfigPropagationSpec{
+		SourceRef:         core.ObjectRef{Namespace: "platform", Name: "app"},
+		NamespaceSelector: &core.LabelSelector{},
+		Strategy:          &core.UpdateStrategy{Type: core.StrategyImmediate},
+	}
+
+	if _, err := NewReconciler(client, nil, nil).Reconcile(context.Background(), Key{Namespace: "default", Name: "cp", UID: "uid-1"}, spec); err != nil {
+		t.Fatalf("reconcile predecessor: %v", err)
+	}
+
+	// The predecessor was deleted without finalizing; its successor has the same name and a new source revision.
+	client.put("platform", "app", &memoryConfigMap{data: map[string]string{"log": "debug"}})
+	eventRecorder := &capturingEventRecorder{}
+	result, err := NewReconciler(client, eventRecorder, nil).Reconcile(context.Background(), Key{Namespace: "default", Name: "cp", UID: "uid-2"}, spec)
+	if err != nil {
+		t.Fatalf("reconcile successor: %v", err)
+	}
+
+	if len(result.OutOfSync) != 1 || result.OutOfSync[0].Reason != core.ReasonOwnedByOther || result.CompletedCount != 0 {
+		t.Fatalf("expected OwnedByOther, got %+v", result)
+	}
+
+	if !hasEvent(eventRecorder, eventReasonConfigClaimed, "Warning") {
+		t.Fatalf("expected a warning event, got %+v", eventRecorder.events)
+	}
+
+	target := client.configMaps[[2]string{"team-a", "app"}]
+	if target.data["log"] != "info" || target.annotations[core.OwnerUIDAnnotation] != "uid-1" {
+		t.Fatalf("expected the predecessor's target to be untouched, got %+v %+v", target.data, target.annotations)
+	}
+}
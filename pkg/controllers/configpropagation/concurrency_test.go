@@ -0,0 +1,88 @@
+package configpropagation
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	"configpropagation/pkg/adapters"
+	"configpropagation/pkg/core"
+)
+
+func TestConcurrentReconcilesOfDifferentKeys(t *testing.T) {
+	const propagations, rounds = 12, 6
+
+	namespaces := []string{"team-a", "team-b", "team-c", "team-d", "team-e", "team-f", "team-g"}
+	client := newMemoryKubeClient(namespaces...)
+	eventRecorder := &capturingEventRecorder{}
+	metricsRecorder := newCapturingMetricsRecorder()
+	reconciler := NewReconciler(client, eventRecorder, metricsRecorder)
+
+	specs := make([]*core.ConfigPropagationSpec, propagations)
+	for index := range specs {
+		sourceName := fmt.Sprintf("app-%02d", index)
+		client.put("platform", sourceName, &memoryConfigMap{data: map[string]string{"owner": sourceName}})
+
+		batchSize := int32(2)
+		specs[index] = &core.ConfigPropagationSpec{
+			SourceRef:         core.ObjectRef{Namespace: "platform", Name: sourceName},
+			NamespaceSelector: &core.LabelSelector{},
+			Strategy:          &core.UpdateStrategy{Type: core.StrategyRolling, BatchSize: &batchSize},
+		}
+	}
+
+	keyAt := func(index int) Key {
+		return Key{Namespace: "default", Name: fmt.Sprintf("cp-%02d", index), UID: fmt.Sprintf("uid-%02d", index)}
+	}
+
+	// Every round reconciles all keys at once, the way the manager does with MaxConcurrentReconciles > 1.
+	results := make([]core.RolloutResult, propagations)
+	for round := 0; round < rounds; round++ {
+		var waitGroup sync.WaitGroup
+		errs := make([]error, propagations)
+
+		for index := range specs {
+			waitGroup.Add(1)
+			go func(index int) {
+				defer waitGroup.Done()
+				results[index], errs[index] = reconciler.Reconcile(context.Background(), keyAt(index), specs[index])
+			}(index)
+		}
+
+		waitGroup.Wait()
+
+		for index, err := range errs {
+			if err != nil {
+				t.Fatalf("round %d: reconcile %s: %v", round, keyAt(index), err)
+			}
+		}
+	}
+
+	for index, result := range results {
+		if result.CompletedCount != len(namespaces) || len(result.OutOfSync) != 0 {
+			t.Fatalf("%s: expected a finished rollout, got %d completed and %+v", keyAt(index), result.CompletedCount, result.OutOfSync)
+		}
+
+		for _, namespace := range namespaces {
+			_, _, _, annotations, found, _ := client.GetTargetConfigMap(context.Background(), namespace, specs[index].SourceRef.Name)
+			if !found || annotations[core.OwnerAnnotation] != keyAt(index).String() {
+				t.Fatalf("%s: expected %s written and owned, got found=%v annotations=%v", keyAt(index), namespace, found, annotations)
+			}
+		}
+	}
+
+	if created := metricsRecorder.counts[adapters.MetricsActionCreate]; created != propagations*len(namespaces) {
+		t.Fatalf("expected every target created exactly once, got %d", created)
+	}
+
+	for index := range specs {
+		if err := reconciler.Finalize(context.Background(), keyAt(index), specs[index]); err != nil {
+			t.Fatalf("finalize %s: %v", keyAt(index), err)
+		}
+	}
+
+	if len(metricsRecorder.forgotten) != propagations {
+		t.Fatalf("expected the target gauges of every finalized propagation to be dropped, got %v", metricsRecorder.forgotten)
+	}
+}
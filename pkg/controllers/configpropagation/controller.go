@@ -1,18 +1,28 @@
 package configpropagation
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
 	"fmt"
 	"sort"
+	"strings"
 	"time"
 
 	"configpropagation/pkg/adapters"
 	"configpropagation/pkg/core"
 )
 
-// Key identifies a ConfigPropagation by namespace/name.
+// Key identifies a ConfigPropagation by namespace/name, and by UID once it has been read from the API server.
 type Key struct {
 	Namespace string
 	Name      string
+	UID       string
+}
+
+// String renders the key as namespace/name, the form recorded in the owner annotation.
+func (key Key) String() string {
+	return fmt.Sprintf("%s/%s", key.Namespace, key.Name)
 }
 
 // namespacedName converts the key into the core NamespacedName helper type.
@@ -25,16 +35,37 @@ const (
 	eventReasonConfigUpdated = "ConfigUpdated"
 	eventReasonConfigSkipped = "ConfigSkipped"
 	eventReasonConfigPruned  = "ConfigPruned"
+	eventReasonConfigDrifted = "ConfigDrifted"
 	eventReasonConfigError   = "ConfigError"
+	eventReasonConfigClaimed = "ConfigClaimed"
+
+	eventReasonPolicyViolation    = "PolicyViolation"
+	eventReasonSourceNotShareable = "SourceNotShareable"
 )
 
+// targetRetryKey identifies the retry state of one target namespace of a ConfigPropagation.
+type targetRetryKey struct {
+	propagation Key
+	namespace   string
+}
+
 // Reconciler wires the kube client and a simple work queue.
+// Reconcile and Finalize may run concurrently for different keys: the planner, backoff tracker and queue are
+// mutex-guarded, and the event and metrics recorders must be safe for concurrent use.
 type Reconciler struct {
 	clientAdapter   adapters.KubeClient
 	workQueue       *core.WorkQueue[Key]
 	rolloutPlanner  *core.RolloutPlanner
+	targetBackoff   *core.BackoffTracker[targetRetryKey]
 	eventRecorder   adapters.EventRecorder
 	metricsRecorder adapters.MetricsRecorder
+	targetWorkers   int
+	// requireSourceOptIn makes sources in other namespaces list the ConfigPropagation's namespace in
+	// core.AllowFromNamespacesAnnotation before they are read.
+	requireSourceOptIn bool
+	// planWholeSelection plans every selected namespace at once instead of the next rolling batch. It is only set
+	// on the reconciler Plan builds around a planningClient.
+	planWholeSelection bool
 }
 
 // OnCRChange enqueues a reconcile when the CR changes.
@@ -60,17 +91,42 @@ func NewReconciler(client adapters.KubeClient, eventRecorder adapters.EventRecor
 	if metricsRecorder == nil {
 		metricsRecorder = adapters.NewNoopMetricsRecorder()
 	}
+	retryBase, retryMax := core.DefaultRetryBounds()
+
 	return &Reconciler{
 		clientAdapter:   client,
 		workQueue:       core.NewWorkQueue[Key](),
 		rolloutPlanner:  core.NewRolloutPlanner(),
+		targetBackoff:   core.NewBackoffTracker[targetRetryKey](retryBase, retryMax, nil, nil),
 		eventRecorder:   eventRecorder,
 		metricsRecorder: metricsRecorder,
+		targetWorkers:   core.DefaultTargetWorkers(),
 	}
 }
 
+// RequireSourceOptIn sets whether sources in other namespaces must list the ConfigPropagation's namespace in their
+// allow-from-namespaces annotation before they are read.
+func (reconciler *Reconciler) RequireSourceOptIn(required bool) {
+	reconciler.requireSourceOptIn = required
+}
+
+// RestoreRollout seeds rolling progress persisted in status, typically after a restart or leader change.
+// Progress for a different hash than the one already tracked in memory is ignored as stale.
+func (reconciler *Reconciler) RestoreRollout(key Key, rollout *core.RolloutStatus) {
+	if rollout == nil || rollout.Hash == "" {
+		return
+	}
+
+	identifier := key.namespacedName()
+	if trackedHash, tracked := reconciler.rolloutPlanner.TrackedHash(identifier); tracked && trackedHash != rollout.Hash {
+		return
+	}
+
+	reconciler.rolloutPlanner.Restore(identifier, *rollout)
+}
+
 // Reconcile performs one loop for the next item in the queue.
-func (reconciler *Reconciler) Reconcile(key Key, spec *core.ConfigPropagationSpec) (core.RolloutResult, error) {
+func (reconciler *Reconciler) Reconcile(requestContext context.Context, key Key, spec *core.ConfigPropagationSpec) (core.RolloutResult, error) {
 	if spec == nil {
 		return core.RolloutResult{}, fmt.Errorf("spec is nil")
 	}
@@ -81,7 +137,7 @@ func (reconciler *Reconciler) Reconcile(key Key, spec *core.ConfigPropagationSpe
 	}
 
 	start := time.Now()
-	result, err := reconciler.reconcileImpl(key, spec)
+	result, err := reconciler.reconcileImpl(requestContext, key, spec)
 	duration := time.Since(start)
 
 	if err != nil {
@@ -89,52 +145,97 @@ func (reconciler *Reconciler) Reconcile(key Key, spec *core.ConfigPropagationSpe
 		return core.RolloutResult{}, err
 	}
 
-	reconciler.metricsRecorder.ObserveTargets(result.TotalTargets, len(result.OutOfSync))
+	reconciler.metricsRecorder.ObserveTargets(key.namespacedName(), result.TotalTargets, len(result.OutOfSync))
 	reconciler.metricsRecorder.ObserveReconcileDuration(duration)
 
 	return result, nil
 }
 
 // Internal implementation separated for testability and full coverage.
-func (reconciler *Reconciler) reconcileImpl(key Key, spec *core.ConfigPropagationSpec) (core.RolloutResult, error) {
-	sourceConfigData, err := reconciler.clientAdapter.GetSourceConfigMap(spec.SourceRef.Namespace, spec.SourceRef.Name)
+func (reconciler *Reconciler) reconcileImpl(requestContext context.Context, key Key, spec *core.ConfigPropagationSpec) (core.RolloutResult, error) {
+	policies, err := reconciler.listPolicies(requestContext, key)
 	if err != nil {
-		return core.RolloutResult{}, reconciler.recordError(key, "source_fetch", fmt.Sprintf("get source %s/%s", spec.SourceRef.Namespace, spec.SourceRef.Name), err)
+		return core.RolloutResult{}, err
+	}
+
+	// Check what the spec alone decides before reading sources; maxTargets waits for the namespace listing.
+	if err := reconciler.enforcePolicies(key, policies, spec, -1); err != nil {
+		return core.RolloutResult{}, err
 	}
 
-	effectiveData := computeEffective(sourceConfigData, spec.DataKeys)
+	content, err := reconciler.desiredContent(requestContext, key, spec)
+	if err != nil {
+		return core.RolloutResult{}, err
+	}
 
-	targetNamespaces, err := listTargets(reconciler.clientAdapter, spec.NamespaceSelector)
+	targetNamespaces, err := listTargets(requestContext, reconciler.clientAdapter, spec.NamespaceSelector)
 	if err != nil {
 		return core.RolloutResult{}, reconciler.recordError(key, "namespace_list", "list namespaces", err)
 	}
 
+	if err := reconciler.enforcePolicies(key, policies, spec, len(targetNamespaces)); err != nil {
+		return core.RolloutResult{}, err
+	}
+
 	sort.Strings(targetNamespaces)
+	reconciler.forgetDeselectedRetries(key, targetNamespaces)
+
+	targetNamer, err := core.NewTargetNamer(spec)
+	if err != nil {
+		return core.RolloutResult{}, err
+	}
+
+	targetNames, namingFailures := reconciler.renderTargetNames(key, targetNamer, targetNamespaces)
 
 	batchSize := int32(5)
 	if spec.Strategy.BatchSize != nil {
 		batchSize = *spec.Strategy.BatchSize
 	}
 
-	rolloutHash := core.HashData(effectiveData)
+	rolloutHash := content.rolloutHash
+	identifier := key.namespacedName()
 
-	plannedNamespaces, _ := planTargets(reconciler.rolloutPlanner, key, rolloutHash, targetNamespaces, spec.Strategy.Type, batchSize)
+	strategyType := spec.Strategy.Type
+	if reconciler.planWholeSelection {
+		// A plan covers every selected namespace, not only the next rolling batch.
+		strategyType = core.StrategyImmediate
+	}
 
-	syncSummary, err := reconciler.syncTargets(key, plannedNamespaces, spec.SourceRef.Name, effectiveData, rolloutHash, spec.SourceRef.Namespace, spec.ConflictPolicy)
-	if err != nil {
-		return core.RolloutResult{}, err
+	var reopenedNamespaces, backingOffNamespaces []string
+	candidateNamespaces := targetNamespaces
+	if strategyType == core.StrategyRolling {
+		if !reconciler.rolloutPlanner.Tracking(identifier, rolloutHash) {
+			// Nothing in memory for this content (fresh replica or new content): rebuild progress from the targets.
+			verifiedNamespaces := reconciler.verifiedTargets(requestContext, key, targetNamespaces, targetNames, content)
+			reconciler.rolloutPlanner.Restore(identifier, core.RolloutStatus{Hash: rolloutHash, CompletedNamespaces: verifiedNamespaces})
+		} else {
+			// Completed targets can be edited or deleted after their batch; re-verify them so drift is repaired
+			// or reported like in an immediate rollout.
+			reopenedNamespaces = reconciler.unverifiedCompletedTargets(requestContext, key, identifier, rolloutHash, targetNames, content)
+			reconciler.rolloutPlanner.Reopen(identifier, rolloutHash, reopenedNamespaces)
+		}
+
+		// Namespaces waiting out a retry delay do not take a batch slot, so namespaces that keep failing cannot
+		// stall the rollout; they are still visited below to report their backoff.
+		backingOffNamespaces = reconciler.backingOffTargets(key, identifier, rolloutHash, targetNamespaces)
+		candidateNamespaces = withoutNamespaces(targetNamespaces, backingOffNamespaces)
 	}
 
-	identifier := core.NamespacedName{Namespace: key.Namespace, Name: key.Name}
+	plannedNamespaces, _ := planTargets(reconciler.rolloutPlanner, key, rolloutHash, candidateNamespaces, strategyType, batchSize)
+	// Reopened targets were already rolled out, so they are synced now rather than waiting for a batch slot.
+	plannedNamespaces = appendMissing(plannedNamespaces, reopenedNamespaces)
+
+	syncSummary := reconciler.syncTargets(requestContext, key, appendMissing(plannedNamespaces, backingOffNamespaces), targetNames, content, spec.SourceRef, spec.ConflictPolicy)
 
-	outOfSyncItems := append([]core.OutOfSyncItem(nil), syncSummary.outOfSync...)
+	outOfSyncItems := append(namingFailures, syncSummary.outOfSync...)
 	outOfSyncSet := map[string]struct{}{}
 	for _, item := range outOfSyncItems {
 		outOfSyncSet[item.Namespace] = struct{}{}
 	}
 
 	completedTargetCount := 0
-	switch spec.Strategy.Type {
+	var rolloutStatus *core.RolloutStatus
+	switch strategyType {
 	case core.StrategyRolling:
 		completedTargetCount = reconciler.rolloutPlanner.MarkCompleted(identifier, rolloutHash, syncSummary.completed)
 		completedNamespaces := reconciler.rolloutPlanner.CompletedNamespaces(identifier, rolloutHash)
@@ -152,10 +253,12 @@ func (reconciler *Reconciler) reconcileImpl(key Key, spec *core.ConfigPropagatio
 			}
 			outOfSyncItems = append(outOfSyncItems, core.OutOfSyncItem{
 				Namespace: namespace,
-				Reason:    "PendingRollout",
+				Reason:    core.ReasonPendingRollout,
 				Message:   "namespace awaiting rollout batch",
 			})
 		}
+
+		rolloutStatus = reconciler.rolloutPlanner.Status(identifier, rolloutHash, plannedNamespaces)
 	default:
 		completedTargetCount = len(syncSummary.completed)
 		completedSet := make(map[string]struct{}, len(syncSummary.completed))
@@ -172,26 +275,62 @@ func (reconciler *Reconciler) reconcileImpl(key Key, spec *core.ConfigPropagatio
 			}
 			outOfSyncItems = append(outOfSyncItems, core.OutOfSyncItem{
 				Namespace: namespace,
-				Reason:    "PendingSync",
+				Reason:    core.ReasonPendingSync,
 				Message:   "namespace not synchronized",
 			})
 		}
 
 		reconciler.rolloutPlanner.Forget(identifier)
 	}
+	// Never prune on behalf of a cancelled reconcile; the next one starts from a fresh listing.
+	if err := requestContext.Err(); err != nil {
+		return core.RolloutResult{}, err
+	}
 	// Cleanup deselected namespaces per prune policy
-	if err := reconciler.cleanupDeselected(key, spec, targetNamespaces); err != nil {
+	cleanupFailures, err := reconciler.cleanupDeselected(requestContext, key, spec, targetNamer, targetNamespaces, targetNames)
+	if err != nil {
 		return core.RolloutResult{}, err
 	}
 	result := core.RolloutResult{
-		Planned:        plannedNamespaces,
-		TotalTargets:   len(targetNamespaces),
-		CompletedCount: completedTargetCount,
-		OutOfSync:      outOfSyncItems,
+		Planned:         plannedNamespaces,
+		TotalTargets:    len(targetNamespaces),
+		CompletedCount:  completedTargetCount,
+		OutOfSync:       outOfSyncItems,
+		CleanupFailures: cleanupFailures,
+		Rollout:         rolloutStatus,
+		RetryAfter:      syncSummary.retryAfter,
+		MatchedKeys:     content.matchedKeys,
 	}
 	return result, nil
 }
 
+// listPolicies reads the ConfigPropagationPolicies when the client adapter can; adapters that cannot see none.
+func (reconciler *Reconciler) listPolicies(requestContext context.Context, key Key) ([]core.Policy, error) {
+	policyLister, ok := reconciler.clientAdapter.(adapters.PolicyLister)
+	if !ok {
+		return nil, nil
+	}
+
+	policies, err := policyLister.ListPolicies(requestContext)
+	if err != nil {
+		return nil, reconciler.recordError(key, "policy_list", "list ConfigPropagationPolicies", err)
+	}
+
+	return policies, nil
+}
+
+// enforcePolicies halts the reconcile, before anything is written or pruned, when the spec breaks a policy.
+// A negative targetCount defers the maxTargets check until the selected namespaces are known.
+func (reconciler *Reconciler) enforcePolicies(key Key, policies []core.Policy, spec *core.ConfigPropagationSpec, targetCount int) error {
+	if err := core.EvaluatePolicies(policies, key.Namespace, spec, targetCount); err != nil {
+		reconciler.metricsRecorder.IncError("policy")
+		reconciler.eventRecorder.Warningf(key.namespacedName(), eventReasonPolicyViolation, "%v", err)
+		return err
+	}
+
+	return nil
+}
+
 // nilIfEmpty normalizes empty maps to nil so Kubernetes clients omit them.
 func nilIfEmpty[K comparable, V any](m map[K]V) map[K]V {
 	if len(m) == 0 {
@@ -200,101 +339,623 @@ func nilIfEmpty[K comparable, V any](m map[K]V) map[K]V {
 	return m
 }
 
-// computeEffective filters the source data down to the selected keys.
-func computeEffective(sourceData map[string]string, selectedKeys []string) map[string]string {
-	if sourceData == nil {
-		sourceData = map[string]string{}
+// desiredContent is the merged payload every target should carry.
+type desiredContent struct {
+	data       map[string]string
+	binaryData map[string][]byte
+	hash       string
+	// layered is set for multi-source specs, whose targets record where each key came from.
+	layered      bool
+	keySources   map[string]string
+	contributors []string
+	// matchedKeys lists the source keys selected from every filtered source, for status.
+	matchedKeys []core.SourceKeys
+	// template renders data per namespace when spec.template is enabled; hash then covers the rendered data.
+	template *core.DataTemplate
+	// targetLabels and targetAnnotations are the custom metadata from spec.target stamped on every target.
+	targetLabels      map[string]string
+	targetAnnotations map[string]string
+	// rolloutHash identifies a rollout: the data hash extended with template values and custom target metadata.
+	rolloutHash string
+}
+
+// desiredContent reads every source layer, filters it by its dataKeys and excludeKeys, renames it by the key mappings
+// and merges the layers in precedence order.
+func (reconciler *Reconciler) desiredContent(requestContext context.Context, key Key, spec *core.ConfigPropagationSpec) (desiredContent, error) {
+	keyMapper, err := core.NewKeyMapper(spec.KeyMappings)
+	if err != nil {
+		return desiredContent{}, reconciler.recordError(key, "key_mapping", "compile key mappings", err)
 	}
-	effective := map[string]string{}
 
-	if len(selectedKeys) == 0 {
-		for key, value := range sourceData {
-			effective[key] = value
+	sourceLayers := core.SourceLayers(spec)
+	layers := make([]core.SourceContent, 0, len(sourceLayers))
+	var matchedSources []core.SourceKeys
+
+	for _, sourceLayer := range sourceLayers {
+		if err := reconciler.checkSourceShareable(requestContext, key, core.ObjectRef{Namespace: sourceLayer.Namespace, Name: sourceLayer.Name}); err != nil {
+			return desiredContent{}, err
+		}
+
+		sourceConfigData, sourceBinaryData, err := reconciler.clientAdapter.GetSourceConfigMap(requestContext, sourceLayer.Namespace, sourceLayer.Name)
+		if err != nil {
+			return desiredContent{}, reconciler.recordError(key, "source_fetch", fmt.Sprintf("get source %s/%s", sourceLayer.Namespace, sourceLayer.Name), err)
+		}
+
+		keySelector, err := core.NewKeySelector(sourceLayer.DataKeys, sourceLayer.ExcludeKeys)
+		if err != nil {
+			return desiredContent{}, reconciler.recordError(key, "key_selection", fmt.Sprintf("compile key selection of source %s/%s", sourceLayer.Namespace, sourceLayer.Name), err)
+		}
+
+		effectiveData, effectiveBinaryData, matchedKeys, err := computeEffective(sourceConfigData, sourceBinaryData, keySelector, keyMapper)
+		if err != nil {
+			return desiredContent{}, reconciler.recordError(key, "key_mapping", fmt.Sprintf("map keys of source %s/%s", sourceLayer.Namespace, sourceLayer.Name), err)
+		}
+
+		if keySelector.Filtered() {
+			matchedSources = append(matchedSources, core.SourceKeys{Source: fmt.Sprintf("%s/%s", sourceLayer.Namespace, sourceLayer.Name), Keys: matchedKeys})
 		}
-		return effective
+
+		layers = append(layers, core.SourceContent{
+			Ref:        core.ObjectRef{Namespace: sourceLayer.Namespace, Name: sourceLayer.Name},
+			Data:       effectiveData,
+			BinaryData: effectiveBinaryData,
+		})
+	}
+
+	merged, err := core.MergeSources(spec.MergeMode, layers)
+	if err != nil {
+		return desiredContent{}, reconciler.recordError(key, "source_merge", "merge sources", err)
+	}
+
+	content := desiredContent{
+		data:         merged.Data,
+		binaryData:   merged.BinaryData,
+		hash:         core.HashData(merged.Data, merged.BinaryData),
+		layered:      len(spec.Sources) > 0,
+		keySources:   merged.KeySources,
+		contributors: merged.Contributors,
+		matchedKeys:  matchedSources,
+		template:     core.NewDataTemplate(spec.Template, merged.Data),
+	}
+
+	content.rolloutHash = content.hash
+	if content.template != nil {
+		content.rolloutHash = content.template.Fingerprint(content.hash)
 	}
 
-	for _, key := range selectedKeys {
-		value, exists := sourceData[key]
-		if exists {
-			effective[key] = value
+	if spec.Target != nil {
+		content.targetLabels = spec.Target.Labels
+		content.targetAnnotations = spec.Target.Annotations
+	}
+
+	content.rolloutHash = core.TargetMetadataFingerprint(content.rolloutHash, content.targetLabels, content.targetAnnotations)
+
+	return content, nil
+}
+
+// checkSourceShareable refuses to read a source in another namespace that has not opted in to the ConfigPropagation's
+// namespace, when the reconciler requires the opt-in. Missing sources are left to the read that follows.
+func (reconciler *Reconciler) checkSourceShareable(requestContext context.Context, key Key, source core.ObjectRef) error {
+	if !reconciler.requireSourceOptIn || source.Namespace == key.Namespace {
+		return nil
+	}
+
+	metadataReader, ok := reconciler.clientAdapter.(adapters.SourceMetadataReader)
+	if !ok {
+		return reconciler.recordError(key, "source_access", fmt.Sprintf("check sharing of source %s/%s", source.Namespace, source.Name), fmt.Errorf("client cannot read source annotations"))
+	}
+
+	sourceAnnotations, found, err := metadataReader.GetSourceAnnotations(requestContext, source.Namespace, source.Name)
+	if err != nil {
+		return reconciler.recordError(key, "source_fetch", fmt.Sprintf("get source %s/%s", source.Namespace, source.Name), err)
+	}
+
+	if found && !core.SourceShareableWith(source, sourceAnnotations, key.Namespace) {
+		notShareable := &core.SourceNotShareableError{Source: source, Namespace: key.Namespace}
+		reconciler.metricsRecorder.IncError("source_access")
+		reconciler.eventRecorder.Warningf(key.namespacedName(), eventReasonSourceNotShareable, "%v", notShareable)
+		return notShareable
+	}
+
+	return nil
+}
+
+// forNamespace returns the content written to one namespace, rendering value templates when enabled
+// so the target hash covers the rendered output.
+func (content desiredContent) forNamespace(namespace string, namespaceLabels, namespaceAnnotations map[string]string) (desiredContent, error) {
+	if content.template == nil {
+		return content, nil
+	}
+
+	renderedData, err := content.template.Render(namespace, namespaceLabels, namespaceAnnotations)
+	if err != nil {
+		return desiredContent{}, err
+	}
+
+	rendered := content
+	rendered.data = renderedData
+	rendered.hash = core.HashData(renderedData, content.binaryData)
+	rendered.template = nil
+
+	return rendered, nil
+}
+
+// labels returns the labels written on every target: the custom target labels, the managed label
+// and the hashed source and owner identifiers.
+func (content desiredContent) labels(key Key, sourceRef core.ObjectRef) map[string]string {
+	labels := make(map[string]string, len(content.targetLabels)+3)
+	for labelKey, labelValue := range content.targetLabels {
+		labels[labelKey] = labelValue
+	}
+
+	labels[core.ManagedLabel] = "true"
+	labels[core.SourceHashLabel] = core.SourceLabelValue(fmt.Sprintf("%s/%s", sourceRef.Namespace, sourceRef.Name))
+	labels[core.OwnerHashLabel] = core.SourceLabelValue(key.String())
+	return labels
+}
+
+// annotations returns the annotations written on every target of this content: the custom target annotations
+// and the managed annotations.
+func (content desiredContent) annotations(key Key, sourceRef core.ObjectRef) map[string]string {
+	annotations := make(map[string]string, len(content.targetAnnotations)+5)
+	for annotationKey, annotationValue := range content.targetAnnotations {
+		annotations[annotationKey] = annotationValue
+	}
+
+	annotations[core.SourceAnnotation] = fmt.Sprintf("%s/%s", sourceRef.Namespace, sourceRef.Name)
+	annotations[core.HashAnnotation] = content.hash
+	annotations[core.OwnerAnnotation] = key.String()
+	if key.UID != "" {
+		annotations[core.OwnerUIDAnnotation] = key.UID
+	}
+
+	if content.layered {
+		keySources, _ := json.Marshal(content.keySources)
+		annotations[core.SourcesAnnotation] = strings.Join(content.contributors, ",")
+		annotations[core.KeySourcesAnnotation] = string(keySources)
+	}
+
+	return annotations
+}
+
+// metadataCurrent reports whether a live target carries the custom target metadata of this content.
+func (content desiredContent) metadataCurrent(liveLabels, liveAnnotations map[string]string) bool {
+	return core.TargetMetadataCurrent(liveLabels, liveAnnotations, content.targetLabels, content.targetAnnotations)
+}
+
+// describeDrift lists the keys whose live value differs from the desired content, naming their source for layered specs.
+func (content desiredContent) describeDrift(liveData map[string]string, liveBinaryData map[string][]byte) string {
+	changedKeys := map[string]struct{}{}
+
+	for dataKey, value := range content.data {
+		if liveValue, exists := liveData[dataKey]; !exists || liveValue != value {
+			changedKeys[dataKey] = struct{}{}
+		}
+	}
+
+	for dataKey, value := range content.binaryData {
+		if liveValue, exists := liveBinaryData[dataKey]; !exists || !bytes.Equal(liveValue, value) {
+			changedKeys[dataKey] = struct{}{}
+		}
+	}
+
+	for dataKey := range liveData {
+		if _, desired := content.keySources[dataKey]; !desired {
+			changedKeys[dataKey] = struct{}{}
+		}
+	}
+
+	for dataKey := range liveBinaryData {
+		if _, desired := content.keySources[dataKey]; !desired {
+			changedKeys[dataKey] = struct{}{}
 		}
 	}
-	return effective
+
+	descriptions := make([]string, 0, len(changedKeys))
+	for dataKey := range changedKeys {
+		switch source, desired := content.keySources[dataKey]; {
+		case !desired:
+			descriptions = append(descriptions, fmt.Sprintf("%s (not propagated)", dataKey))
+		case content.layered:
+			descriptions = append(descriptions, fmt.Sprintf("%s (from %s)", dataKey, source))
+		default:
+			descriptions = append(descriptions, dataKey)
+		}
+	}
+
+	sort.Strings(descriptions)
+	return strings.Join(descriptions, ", ")
+}
+
+// computeEffective filters the source data and binaryData down to the selected keys,
+// then renames the remaining keys with the key mapper. The matched keys are reported by their source names.
+func computeEffective(sourceData map[string]string, sourceBinaryData map[string][]byte, keySelector *core.KeySelector, keyMapper *core.KeyMapper) (map[string]string, map[string][]byte, []string, error) {
+	effective, effectiveBinary, matchedKeys := keySelector.Select(sourceData, sourceBinaryData)
+
+	effective, effectiveBinary, err := keyMapper.Apply(effective, effectiveBinary)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	return effective, effectiveBinary, matchedKeys, nil
 }
 
 // listTargets returns the namespaces matching the provided selector via the adapter.
-func listTargets(clientAdapter adapters.KubeClient, selector *core.LabelSelector) ([]string, error) {
-	var selectorRequirements []adapters.LabelSelectorRequirement
+func listTargets(requestContext context.Context, clientAdapter adapters.KubeClient, selector *core.LabelSelector) ([]string, error) {
+	return clientAdapter.ListNamespacesBySelector(requestContext, nilIfEmpty(selector.MatchLabels), selectorRequirements(selector))
+}
+
+// selectorRequirements translates the selector expressions into adapter requirements.
+func selectorRequirements(selector *core.LabelSelector) []adapters.LabelSelectorRequirement {
+	var requirements []adapters.LabelSelectorRequirement
 
 	for _, expression := range selector.MatchExpressions {
 		requirement := adapters.LabelSelectorRequirement{Key: expression.Key, Operator: expression.Operator, Values: expression.Values}
-		selectorRequirements = append(selectorRequirements, requirement)
+		requirements = append(requirements, requirement)
 	}
 
-	return clientAdapter.ListNamespacesBySelector(nilIfEmpty(selector.MatchLabels), selectorRequirements)
+	return requirements
 }
 
 type syncOutcome struct {
-	completed []string
-	outOfSync []core.OutOfSyncItem
+	completed  []string
+	outOfSync  []core.OutOfSyncItem
+	retryAfter time.Duration
+}
+
+// backingOff records a target that is waiting out its retry delay.
+func (outcome *syncOutcome) backingOff(namespace string, state core.BackoffState) {
+	outcome.outOfSync = append(outcome.outOfSync, core.OutOfSyncItem{
+		Namespace: namespace,
+		Reason:    core.ReasonBackingOff,
+		Message:   fmt.Sprintf("next retry at %s after %d consecutive failures (last: %s)", state.NextRetry.UTC().Format(time.RFC3339), state.Failures, state.Detail),
+	})
+	outcome.scheduleRetry(state)
+}
+
+// failed records a target whose sync attempt failed and schedules its retry.
+func (outcome *syncOutcome) failed(item core.OutOfSyncItem, state core.BackoffState) {
+	outcome.outOfSync = append(outcome.outOfSync, item)
+	outcome.scheduleRetry(state)
+}
+
+// merge appends the outcome of another target and keeps the earliest pending retry of both.
+func (outcome *syncOutcome) merge(other syncOutcome) {
+	outcome.completed = append(outcome.completed, other.completed...)
+	outcome.outOfSync = append(outcome.outOfSync, other.outOfSync...)
+
+	if other.retryAfter > 0 {
+		outcome.scheduleRetry(core.BackoffState{Remaining: other.retryAfter})
+	}
+}
+
+// scheduleRetry keeps the earliest pending retry so the reconcile can be requeued for it.
+func (outcome *syncOutcome) scheduleRetry(state core.BackoffState) {
+	if outcome.retryAfter == 0 || state.Remaining < outcome.retryAfter {
+		outcome.retryAfter = state.Remaining
+	}
+}
+
+// renderTargetNames resolves the target ConfigMap name for every selected namespace.
+// Namespaces whose name cannot be rendered are reported and left out of the returned map.
+func (reconciler *Reconciler) renderTargetNames(key Key, targetNamer *core.TargetNamer, targetNamespaces []string) (map[string]string, []core.OutOfSyncItem) {
+	targetNames := make(map[string]string, len(targetNamespaces))
+	var failures []core.OutOfSyncItem
+
+	for _, targetNamespace := range targetNamespaces {
+		targetName, err := targetNamer.Name(targetNamespace)
+		if err != nil {
+			reconciler.metricsRecorder.IncError("target_name")
+			reconciler.eventRecorder.Warningf(key.namespacedName(), eventReasonConfigError, "namespace %s: %v", targetNamespace, err)
+			failures = append(failures, core.OutOfSyncItem{Namespace: targetNamespace, Reason: core.ReasonInvalidTargetName, Message: err.Error()})
+			continue
+		}
+
+		targetNames[targetNamespace] = targetName
+	}
+
+	return targetNames, failures
 }
 
-// syncTargets writes the desired ConfigMap data into each planned namespace.
-func (reconciler *Reconciler) syncTargets(key Key, plannedNamespaces []string, configMapName string, effectiveData map[string]string, contentHash string, sourceNamespace string, conflictPolicy string) (syncOutcome, error) {
+// syncTargets writes the desired ConfigMap data into each planned namespace, processing up to targetWorkers
+// namespaces concurrently. Outcomes are collected in planned order so status stays stable across reconciles.
+// Failures are isolated per namespace and reported as out-of-sync items so healthy namespaces still progress.
+// A failing namespace is retried with exponential backoff and reported as BackingOff until its delay elapses.
+// Namespaces without a rendered target name were already reported and are skipped.
+// A cancelled reconcile stops starting writes; namespaces it never visited are reported as pending.
+func (reconciler *Reconciler) syncTargets(requestContext context.Context, key Key, plannedNamespaces []string, targetNames map[string]string, content desiredContent, sourceRef core.ObjectRef, conflictPolicy string) syncOutcome {
+	labels := content.labels(key, sourceRef)
+	targetOutcomes := make([]syncOutcome, len(plannedNamespaces))
+
+	stop := func() bool { return requestContext.Err() != nil }
+	core.ForEachBounded(len(plannedNamespaces), reconciler.targetWorkers, stop, func(index int) {
+		targetNamespace := plannedNamespaces[index]
+		if configMapName, named := targetNames[targetNamespace]; named {
+			targetOutcomes[index] = reconciler.syncTarget(requestContext, key, targetNamespace, configMapName, content, labels, sourceRef, conflictPolicy)
+		}
+	})
+
 	outcome := syncOutcome{}
-	labels := map[string]string{core.ManagedLabel: "true"}
-	sourceConfigMap := fmt.Sprintf("%s/%s", sourceNamespace, configMapName)
+	for _, targetOutcome := range targetOutcomes {
+		outcome.merge(targetOutcome)
+	}
+
+	return outcome
+}
+
+// syncTarget writes the desired content into one target ConfigMap and reports its outcome.
+// It only touches state keyed by its own namespace, so calls for different namespaces may run concurrently.
+func (reconciler *Reconciler) syncTarget(requestContext context.Context, key Key, targetNamespace, configMapName string, content desiredContent, labels map[string]string, sourceRef core.ObjectRef, conflictPolicy string) syncOutcome {
+	outcome := syncOutcome{}
+	sourceConfigMap := fmt.Sprintf("%s/%s", sourceRef.Namespace, sourceRef.Name)
 
-	annotations := map[string]string{
-		core.SourceAnnotation: sourceConfigMap,
-		core.HashAnnotation:   contentHash,
+	retryKey := targetRetryKey{propagation: key, namespace: targetNamespace}
+	if state, waiting := reconciler.targetBackoff.Waiting(retryKey); waiting {
+		outcome.backingOff(targetNamespace, state)
+		return outcome
 	}
 
-	for _, targetNamespace := range plannedNamespaces {
-		_, targetLabels, targetAnnotations, targetFound, err := reconciler.clientAdapter.GetTargetConfigMap(targetNamespace, configMapName)
+	var namespaceLabels, namespaceAnnotations map[string]string
+	if content.template != nil {
+		var err error
+		namespaceLabels, namespaceAnnotations, err = reconciler.clientAdapter.GetNamespaceMetadata(requestContext, targetNamespace)
 		if err != nil {
-			return outcome, reconciler.recordError(key, "target_lookup", fmt.Sprintf("get target %s/%s", targetNamespace, configMapName), err)
+			failure := reconciler.recordTargetFailure(key, "namespace_lookup", targetNamespace, fmt.Sprintf("get namespace %s", targetNamespace), err)
+			outcome.failed(failure, reconciler.targetBackoff.Failure(retryKey, failure.Reason))
+			return outcome
 		}
+	}
 
-		managed := false
-		if targetFound {
-			if targetLabels != nil && targetLabels[core.ManagedLabel] == "true" {
-				managed = true
-			}
-			if targetAnnotations != nil && targetAnnotations[core.SourceAnnotation] == sourceConfigMap {
-				managed = true
+	// Template errors are reported without backoff: retrying cannot help until the source, spec or namespace changes.
+	targetContent, err := content.forNamespace(targetNamespace, namespaceLabels, namespaceAnnotations)
+	if err != nil {
+		reconciler.metricsRecorder.IncError("template")
+		reconciler.eventRecorder.Warningf(key.namespacedName(), eventReasonConfigError, "namespace %s: %v", targetNamespace, err)
+		outcome.outOfSync = append(outcome.outOfSync, core.OutOfSyncItem{Namespace: targetNamespace, Reason: core.ReasonTemplateError, Message: err.Error()})
+		return outcome
+	}
+
+	targetData, targetBinaryData, targetLabels, targetAnnotations, targetFound, err := reconciler.clientAdapter.GetTargetConfigMap(requestContext, targetNamespace, configMapName)
+	if err != nil {
+		failure := reconciler.recordTargetFailure(key, "target_lookup", targetNamespace, fmt.Sprintf("get target %s/%s", targetNamespace, configMapName), err)
+		outcome.failed(failure, reconciler.targetBackoff.Failure(retryKey, failure.Reason))
+		return outcome
+	}
+
+	managed := false
+	if targetFound {
+		if targetLabels != nil && targetLabels[core.ManagedLabel] == "true" {
+			managed = true
+		}
+		if targetAnnotations != nil && targetAnnotations[core.SourceAnnotation] == sourceConfigMap {
+			managed = true
+		}
+	}
+
+	// Two ConfigPropagations writing one target would overwrite each other forever; leave it to its owner.
+	// No backoff: retrying cannot help until one of the specs changes.
+	if targetFound && managed && ownedByOther(key, targetAnnotations[core.OwnerAnnotation], targetAnnotations[core.OwnerUIDAnnotation]) {
+		targetOwner := describeOwner(key, targetAnnotations[core.OwnerAnnotation], targetAnnotations[core.OwnerUIDAnnotation])
+		reconciler.recordCollision(key, targetNamespace, configMapName, targetOwner)
+		outcome.outOfSync = append(outcome.outOfSync, core.OutOfSyncItem{
+			Namespace: targetNamespace,
+			Reason:    core.ReasonOwnedByOther,
+			Message:   fmt.Sprintf("target %s/%s is managed by ConfigPropagation %s", targetNamespace, configMapName, targetOwner),
+		})
+		return outcome
+	}
+
+	if targetFound && !managed && conflictPolicy == core.ConflictSkip {
+		reconciler.recordSkip(key, targetNamespace, configMapName, "existing unmanaged ConfigMap (conflictPolicy=skip)")
+		outcome.outOfSync = append(outcome.outOfSync, core.OutOfSyncItem{
+			Namespace: targetNamespace,
+			Reason:    core.ReasonConflictPolicySkip,
+			Message:   "existing ConfigMap is unmanaged and conflictPolicy=skip",
+		})
+		return outcome
+	}
+
+	if targetFound && managed {
+		recordedHash := targetAnnotations[core.HashAnnotation]
+		liveHash := core.HashData(targetData, targetBinaryData)
+
+		if liveHash == targetContent.hash && recordedHash == targetContent.hash && targetContent.metadataCurrent(targetLabels, targetAnnotations) {
+			reconciler.recordSkip(key, targetNamespace, configMapName, "already up to date")
+			reconciler.targetBackoff.Success(retryKey)
+			outcome.completed = append(outcome.completed, targetNamespace)
+			return outcome
+		}
+
+		// Live data that matches neither what we last wrote nor what we want was edited by someone else.
+		if liveHash != recordedHash && liveHash != targetContent.hash {
+			driftedKeys := targetContent.describeDrift(targetData, targetBinaryData)
+			reconciler.recordDrift(key, targetNamespace, configMapName, conflictPolicy, driftedKeys)
+
+			if conflictPolicy == core.ConflictSkip {
+				outcome.outOfSync = append(outcome.outOfSync, core.OutOfSyncItem{
+					Namespace: targetNamespace,
+					Reason:    core.ReasonDrifted,
+					Message:   fmt.Sprintf("target data was modified outside the controller and conflictPolicy=skip: %s", driftedKeys),
+				})
+				return outcome
 			}
 		}
+	}
 
-		if targetFound && !managed && conflictPolicy == core.ConflictSkip {
-			reconciler.recordSkip(key, targetNamespace, configMapName, "existing unmanaged ConfigMap (conflictPolicy=skip)")
+	annotations := targetContent.annotations(key, sourceRef)
+	if err := reconciler.writeTarget(requestContext, targetNamespace, configMapName, targetContent, labels, annotations, conflictPolicy); err != nil {
+		if conflictPolicy == core.ConflictSkip && adapters.IsFieldManagerConflict(err) {
+			reconciler.recordSkip(key, targetNamespace, configMapName, "fields owned by another field manager (conflictPolicy=skip)")
 			outcome.outOfSync = append(outcome.outOfSync, core.OutOfSyncItem{
 				Namespace: targetNamespace,
-				Reason:    "ConflictPolicySkip",
-				Message:   "existing ConfigMap is unmanaged and conflictPolicy=skip",
+				Reason:    core.ReasonFieldManagerConflict,
+				Message:   fmt.Sprintf("conflictPolicy=skip and another field manager owns target fields: %v", err),
 			})
+			return outcome
+		}
+
+		failure := reconciler.recordTargetFailure(key, "upsert", targetNamespace, fmt.Sprintf("upsert %s/%s", targetNamespace, configMapName), err)
+		outcome.failed(failure, reconciler.targetBackoff.Failure(retryKey, failure.Reason))
+		return outcome
+	}
+
+	reconciler.targetBackoff.Success(retryKey)
+	outcome.completed = append(outcome.completed, targetNamespace)
+	if targetFound {
+		reconciler.recordUpdate(key, targetNamespace, configMapName)
+	} else {
+		reconciler.recordCreate(key, targetNamespace, configMapName)
+	}
+	return outcome
+}
+
+// writeTarget writes one target. Server-side apply adapters only take over fields owned by other
+// field managers under conflictPolicy=overwrite; other adapters always replace the managed content.
+func (reconciler *Reconciler) writeTarget(requestContext context.Context, namespace, name string, content desiredContent, labels, annotations map[string]string, conflictPolicy string) error {
+	if applier, ok := reconciler.clientAdapter.(adapters.ConfigMapApplier); ok {
+		return applier.ApplyConfigMap(requestContext, namespace, name, content.data, nilIfEmpty(content.binaryData), labels, annotations, conflictPolicy != core.ConflictSkip)
+	}
+
+	return reconciler.clientAdapter.UpsertConfigMap(requestContext, namespace, name, content.data, nilIfEmpty(content.binaryData), labels, annotations)
+}
+
+// verifiedTargets returns the namespaces whose managed target already carries the desired content.
+// Targets that cannot be read or rendered, or that another ConfigPropagation owns, are left unverified
+// so the sync loop retries and reports them.
+func (reconciler *Reconciler) verifiedTargets(requestContext context.Context, key Key, targetNamespaces []string, targetNames map[string]string, content desiredContent) []string {
+	var verifiedNamespaces []string
+
+	for _, targetNamespace := range targetNamespaces {
+		configMapName, named := targetNames[targetNamespace]
+		if !named {
 			continue
 		}
 
-		if targetFound && managed && targetAnnotations[core.HashAnnotation] == contentHash {
-			reconciler.recordSkip(key, targetNamespace, configMapName, "already up to date")
-			outcome.completed = append(outcome.completed, targetNamespace)
+		targetData, targetBinaryData, targetLabels, targetAnnotations, targetFound, err := reconciler.clientAdapter.GetTargetConfigMap(requestContext, targetNamespace, configMapName)
+		if err != nil || !targetFound || targetLabels[core.ManagedLabel] != "true" || ownedByOther(key, targetAnnotations[core.OwnerAnnotation], targetAnnotations[core.OwnerUIDAnnotation]) {
 			continue
 		}
 
-		if err := reconciler.clientAdapter.UpsertConfigMap(targetNamespace, configMapName, effectiveData, labels, annotations); err != nil {
-			return outcome, reconciler.recordError(key, "upsert", fmt.Sprintf("upsert %s/%s", targetNamespace, configMapName), err)
+		var namespaceLabels, namespaceAnnotations map[string]string
+		if content.template != nil {
+			namespaceLabels, namespaceAnnotations, err = reconciler.clientAdapter.GetNamespaceMetadata(requestContext, targetNamespace)
+			if err != nil {
+				continue
+			}
+		}
+
+		targetContent, err := content.forNamespace(targetNamespace, namespaceLabels, namespaceAnnotations)
+		if err != nil {
+			continue
 		}
 
-		outcome.completed = append(outcome.completed, targetNamespace)
-		if targetFound {
-			reconciler.recordUpdate(key, targetNamespace, configMapName)
-		} else {
-			reconciler.recordCreate(key, targetNamespace, configMapName)
+		if targetAnnotations[core.HashAnnotation] == targetContent.hash && core.HashData(targetData, targetBinaryData) == targetContent.hash && targetContent.metadataCurrent(targetLabels, targetAnnotations) {
+			verifiedNamespaces = append(verifiedNamespaces, targetNamespace)
+		}
+	}
+
+	return verifiedNamespaces
+}
+
+// unverifiedCompletedTargets returns the selected namespaces the rollout counts as completed whose target no longer
+// carries the desired content, such as targets edited or deleted by hand, or re-rendered templates.
+func (reconciler *Reconciler) unverifiedCompletedTargets(requestContext context.Context, key Key, identifier core.NamespacedName, rolloutHash string, targetNames map[string]string, content desiredContent) []string {
+	var completedNamespaces []string
+	for _, namespace := range reconciler.rolloutPlanner.CompletedNamespaces(identifier, rolloutHash) {
+		if _, named := targetNames[namespace]; named {
+			completedNamespaces = append(completedNamespaces, namespace)
+		}
+	}
+
+	verifiedNamespaces := map[string]struct{}{}
+	for _, namespace := range reconciler.verifiedTargets(requestContext, key, completedNamespaces, targetNames, content) {
+		verifiedNamespaces[namespace] = struct{}{}
+	}
+
+	var unverifiedNamespaces []string
+	for _, namespace := range completedNamespaces {
+		if _, verified := verifiedNamespaces[namespace]; !verified {
+			unverifiedNamespaces = append(unverifiedNamespaces, namespace)
+		}
+	}
+
+	return unverifiedNamespaces
+}
+
+// backingOffTargets returns the selected namespaces, not yet completed in the rollout, whose last sync failed and
+// whose retry delay has not elapsed.
+func (reconciler *Reconciler) backingOffTargets(key Key, identifier core.NamespacedName, rolloutHash string, targetNamespaces []string) []string {
+	completedNamespaces := map[string]struct{}{}
+	for _, namespace := range reconciler.rolloutPlanner.CompletedNamespaces(identifier, rolloutHash) {
+		completedNamespaces[namespace] = struct{}{}
+	}
+
+	var backingOffNamespaces []string
+	for _, namespace := range targetNamespaces {
+		if _, completed := completedNamespaces[namespace]; completed {
+			continue
+		}
+		if _, waiting := reconciler.targetBackoff.Waiting(targetRetryKey{propagation: key, namespace: namespace}); waiting {
+			backingOffNamespaces = append(backingOffNamespaces, namespace)
+		}
+	}
+
+	return backingOffNamespaces
+}
+
+// withoutNamespaces returns the namespaces that are not excluded, keeping their order.
+func withoutNamespaces(namespaces, excluded []string) []string {
+	if len(excluded) == 0 {
+		return namespaces
+	}
+
+	excludedSet := make(map[string]struct{}, len(excluded))
+	for _, namespace := range excluded {
+		excludedSet[namespace] = struct{}{}
+	}
+
+	remaining := make([]string, 0, len(namespaces))
+	for _, namespace := range namespaces {
+		if _, skip := excludedSet[namespace]; !skip {
+			remaining = append(remaining, namespace)
+		}
+	}
+
+	return remaining
+}
+
+// appendMissing appends the namespaces that are not in the list yet, keeping its order.
+func appendMissing(namespaces, additional []string) []string {
+	present := make(map[string]struct{}, len(namespaces))
+	for _, namespace := range namespaces {
+		present[namespace] = struct{}{}
+	}
+
+	for _, namespace := range additional {
+		if _, exists := present[namespace]; !exists {
+			namespaces = append(namespaces, namespace)
+			present[namespace] = struct{}{}
 		}
 	}
-	return outcome, nil
+
+	return namespaces
+}
+
+// forgetDeselectedRetries drops retry state for namespaces this ConfigPropagation no longer selects.
+func (reconciler *Reconciler) forgetDeselectedRetries(key Key, selectedNamespaces []string) {
+	selectedNamespaceSet := make(map[string]struct{}, len(selectedNamespaces))
+	for _, namespace := range selectedNamespaces {
+		selectedNamespaceSet[namespace] = struct{}{}
+	}
+
+	reconciler.targetBackoff.Retain(func(retryKey targetRetryKey) bool {
+		if retryKey.propagation != key {
+			return true
+		}
+
+		_, selected := selectedNamespaceSet[retryKey.namespace]
+		return selected
+	})
 }
 
 // planTargets delegates to the rollout planner to determine the next batch of namespaces.
@@ -303,9 +964,10 @@ func planTargets(rolloutPlanner *core.RolloutPlanner, key Key, rolloutHash strin
 	return rolloutPlanner.Plan(id, rolloutHash, strategy, batchSize, candidateNamespaces)
 }
 
-// cleanupDeselected removes or detaches targets in namespaces that were previously managed
-// but are no longer selected by the label selector.
-func (reconciler *Reconciler) cleanupDeselected(key Key, spec *core.ConfigPropagationSpec, currentlySelectedNamespaces []string) error {
+// cleanupDeselected removes or detaches targets this ConfigPropagation manages that are no longer desired:
+// namespaces that left the selection and targets whose rendered name changed. Per-namespace failures are
+// returned as out-of-sync items so one broken namespace does not block cleanup of the others.
+func (reconciler *Reconciler) cleanupDeselected(requestContext context.Context, key Key, spec *core.ConfigPropagationSpec, targetNamer *core.TargetNamer, currentlySelectedNamespaces []string, targetNames map[string]string) ([]core.OutOfSyncItem, error) {
 	shouldPrune := true
 	if spec.Prune != nil {
 		shouldPrune = *spec.Prune
@@ -313,9 +975,9 @@ func (reconciler *Reconciler) cleanupDeselected(key Key, spec *core.ConfigPropag
 
 	sourceIdentifier := fmt.Sprintf("%s/%s", spec.SourceRef.Namespace, spec.SourceRef.Name)
 
-	managedNamespaces, err := reconciler.clientAdapter.ListManagedTargetNamespaces(sourceIdentifier, spec.SourceRef.Name)
+	managedTargets, err := reconciler.clientAdapter.ListManagedTargets(requestContext, sourceIdentifier, key.String())
 	if err != nil {
-		return reconciler.recordError(key, "list_managed", "list managed targets", err)
+		return nil, reconciler.recordError(key, "list_managed", "list managed targets", err)
 	}
 	// Build set of selected
 	selectedNamespaceSet := map[string]struct{}{}
@@ -324,42 +986,123 @@ func (reconciler *Reconciler) cleanupDeselected(key Key, spec *core.ConfigPropag
 		selectedNamespaceSet[namespace] = struct{}{}
 	}
 
-	for _, namespace := range managedNamespaces {
-		if _, stillSelected := selectedNamespaceSet[namespace]; stillSelected {
+	var failures []core.OutOfSyncItem
+
+	for _, target := range managedTargets {
+		if err := requestContext.Err(); err != nil {
+			return failures, err
+		}
+
+		if !ownsTarget(key, spec, targetNamer, target) {
 			continue
 		}
 
+		namespace := target.Namespace
+		if _, stillSelected := selectedNamespaceSet[namespace]; stillSelected {
+			desiredName, named := targetNames[namespace]
+			// Keep the current target, and anything in a namespace whose name could not be rendered this time.
+			if !named || desiredName == target.Name {
+				continue
+			}
+		}
+
 		if shouldPrune {
-			if err := reconciler.clientAdapter.DeleteConfigMap(namespace, spec.SourceRef.Name); err != nil {
-				return reconciler.recordError(key, "prune", fmt.Sprintf("delete %s/%s", namespace, spec.SourceRef.Name), err)
+			if err := reconciler.clientAdapter.DeleteConfigMap(requestContext, namespace, target.Name); err != nil {
+				failures = append(failures, reconciler.recordTargetFailure(key, "prune", namespace, fmt.Sprintf("delete %s/%s", namespace, target.Name), err))
+				continue
 			}
-			reconciler.recordPrune(key, namespace, spec.SourceRef.Name)
+			reconciler.recordPrune(key, namespace, target.Name)
 		} else {
 			// Detach: remove managed markers but preserve any other metadata.
-			_, labels, annotations, found, err := reconciler.clientAdapter.GetTargetConfigMap(namespace, spec.SourceRef.Name)
+			_, _, labels, annotations, found, err := reconciler.clientAdapter.GetTargetConfigMap(requestContext, namespace, target.Name)
 			if err != nil {
-				return reconciler.recordError(key, "target_lookup", fmt.Sprintf("get target %s/%s", namespace, spec.SourceRef.Name), err)
+				failures = append(failures, reconciler.recordTargetFailure(key, "target_lookup", namespace, fmt.Sprintf("get target %s/%s", namespace, target.Name), err))
+				continue
 			}
 
 			if !found {
 				continue
 			}
 
-			delete(labels, core.ManagedLabel)
-			delete(annotations, core.SourceAnnotation)
-			delete(annotations, core.HashAnnotation)
+			labels, annotations = core.StripOwnedMetadata(labels, annotations)
+			labels, annotations = stripTargetMetadata(spec.Target, labels, annotations)
 
-			if err := reconciler.clientAdapter.UpdateConfigMapMetadata(namespace, spec.SourceRef.Name, labels, annotations); err != nil {
-				return reconciler.recordError(key, "detach", fmt.Sprintf("detach %s/%s", namespace, spec.SourceRef.Name), err)
+			if err := reconciler.clientAdapter.UpdateConfigMapMetadata(requestContext, namespace, target.Name, labels, annotations); err != nil {
+				failures = append(failures, reconciler.recordTargetFailure(key, "detach", namespace, fmt.Sprintf("detach %s/%s", namespace, target.Name), err))
+				continue
 			}
-			reconciler.recordSkip(key, namespace, spec.SourceRef.Name, "detached from management")
+			reconciler.recordSkip(key, namespace, target.Name, "detached from management")
 		}
 	}
-	return nil
+	return failures, nil
+}
+
+// ownedByOther reports whether the owner annotations of a managed target name a different ConfigPropagation.
+// As in ownsTarget, the owner UID is decisive when both sides know it, so a CR recreated under the same name does
+// not adopt its predecessor's targets. Targets without an owner predate ownership tracking and are adopted.
+func ownedByOther(key Key, targetOwner, targetOwnerUID string) bool {
+	if targetOwnerUID != "" && key.UID != "" {
+		return targetOwnerUID != key.UID
+	}
+
+	return targetOwner != "" && targetOwner != key.String()
+}
+
+// describeOwner names the owner of a contested target for events and status, marking a predecessor of the same
+// name by its UID.
+func describeOwner(key Key, targetOwner, targetOwnerUID string) string {
+	if targetOwner == key.String() {
+		return fmt.Sprintf("%s (previous UID %s)", targetOwner, targetOwnerUID)
+	}
+
+	return targetOwner
+}
+
+// stripTargetMetadata removes the custom target metadata of the spec from a detached target. It covers targets
+// whose ownership record does not list them; keys whose value was changed by someone else are kept.
+func stripTargetMetadata(target *core.TargetSpec, labels, annotations map[string]string) (map[string]string, map[string]string) {
+	if target == nil {
+		return labels, annotations
+	}
+
+	for labelKey, labelValue := range target.Labels {
+		if labels[labelKey] == labelValue {
+			delete(labels, labelKey)
+		}
+	}
+
+	for annotationKey, annotationValue := range target.Annotations {
+		if annotations[annotationKey] == annotationValue {
+			delete(annotations, annotationKey)
+		}
+	}
+
+	return labels, annotations
+}
+
+// ownsTarget reports whether a managed target was written by this ConfigPropagation.
+// The owner UID is decisive when both sides know it, so a recreated CR leaves its predecessor's targets alone.
+// Targets written before the owner annotation existed always carried the source name, or the current rendered name.
+func ownsTarget(key Key, spec *core.ConfigPropagationSpec, targetNamer *core.TargetNamer, target adapters.ManagedTarget) bool {
+	if target.OwnerUID != "" && key.UID != "" {
+		return target.OwnerUID == key.UID
+	}
+
+	if target.Owner != "" {
+		return target.Owner == key.String()
+	}
+
+	if target.Name == spec.SourceRef.Name {
+		return true
+	}
+
+	renderedName, err := targetNamer.Name(target.Namespace)
+	return err == nil && renderedName == target.Name
 }
 
 // Finalize performs full cleanup across all managed targets for this CR.
-func (reconciler *Reconciler) Finalize(key Key, spec *core.ConfigPropagationSpec) error {
+// Any namespace that could not be cleaned up fails finalization so the finalizer is retained.
+func (reconciler *Reconciler) Finalize(requestContext context.Context, key Key, spec *core.ConfigPropagationSpec) error {
 	if spec == nil {
 		return fmt.Errorf("spec is nil")
 	}
@@ -368,8 +1111,36 @@ func (reconciler *Reconciler) Finalize(key Key, spec *core.ConfigPropagationSpec
 	if err := core.ValidateSpec(spec); err != nil {
 		return err
 	}
+	targetNamer, err := core.NewTargetNamer(spec)
+	if err != nil {
+		return err
+	}
+
 	// Cleanup with empty selection set
-	return reconciler.cleanupDeselected(key, spec, []string{})
+	failures, err := reconciler.cleanupDeselected(requestContext, key, spec, targetNamer, []string{}, nil)
+	if err != nil {
+		return err
+	}
+
+	reconciler.forgetDeselectedRetries(key, nil)
+
+	if len(failures) > 0 {
+		return fmt.Errorf("cleanup failed in %d namespaces: %s", len(failures), summarizeFailures(failures))
+	}
+
+	reconciler.metricsRecorder.ForgetTargets(key.namespacedName())
+	return nil
+}
+
+// summarizeFailures renders failed items as a compact, stable namespace list.
+func summarizeFailures(failures []core.OutOfSyncItem) string {
+	descriptions := make([]string, 0, len(failures))
+	for _, failure := range failures {
+		descriptions = append(descriptions, fmt.Sprintf("%s (%s)", failure.Namespace, failure.Reason))
+	}
+
+	sort.Strings(descriptions)
+	return strings.Join(descriptions, ", ")
 }
 
 // recordCreate emits metrics and events for created ConfigMaps.
@@ -390,12 +1161,41 @@ func (reconciler *Reconciler) recordSkip(key Key, namespace, name, reason string
 	reconciler.eventRecorder.Normalf(key.namespacedName(), eventReasonConfigSkipped, "Skipped ConfigMap %s/%s: %s", namespace, name, reason)
 }
 
+// recordDrift emits metrics and events when a managed target's data diverged from the recorded hash.
+func (reconciler *Reconciler) recordDrift(key Key, namespace, name, conflictPolicy, driftedKeys string) {
+	reconciler.metricsRecorder.IncDrift()
+
+	action := "correcting"
+	if conflictPolicy == core.ConflictSkip {
+		action = "leaving in place (conflictPolicy=skip)"
+	}
+	reconciler.eventRecorder.Warningf(key.namespacedName(), eventReasonConfigDrifted, "Detected drift in ConfigMap %s/%s (%s), %s", namespace, name, driftedKeys, action)
+}
+
+// recordCollision emits metrics and events when a target is managed by another ConfigPropagation.
+func (reconciler *Reconciler) recordCollision(key Key, namespace, name, owner string) {
+	reconciler.metricsRecorder.IncError("ownership")
+	reconciler.eventRecorder.Warningf(key.namespacedName(), eventReasonConfigClaimed, "ConfigMap %s/%s is managed by ConfigPropagation %s, leaving it alone", namespace, name, owner)
+}
+
 // recordPrune emits metrics and events when a target is deleted during pruning.
 func (reconciler *Reconciler) recordPrune(key Key, namespace, name string) {
 	reconciler.metricsRecorder.AddPropagations(adapters.MetricsActionPrune, 1)
 	reconciler.eventRecorder.Normalf(key.namespacedName(), eventReasonConfigPruned, "Pruned ConfigMap %s/%s", namespace, name)
 }
 
+// recordTargetFailure records metrics and events for a single failed namespace and classifies it for status.
+func (reconciler *Reconciler) recordTargetFailure(key Key, stage, namespace, message string, err error) core.OutOfSyncItem {
+	reconciler.metricsRecorder.IncError(stage)
+	reconciler.eventRecorder.Warningf(key.namespacedName(), eventReasonConfigError, "%s: %v", message, err)
+
+	return core.OutOfSyncItem{
+		Namespace: namespace,
+		Reason:    adapters.ClassifyError(err),
+		Message:   fmt.Sprintf("%s: %v", message, err),
+	}
+}
+
 // recordError increments error metrics and wraps the provided error with context.
 func (reconciler *Reconciler) recordError(key Key, stage, message string, err error) error {
 	reconciler.metricsRecorder.IncError(stage)
@@ -3,43 +3,56 @@ package configpropagation
 import (
 	"configpropagation/pkg/adapters"
 	core "configpropagation/pkg/core"
+	"context"
+	"sync"
 	"testing"
 )
 
 // fakeDriftClient simulates existing targets with varying annotations/labels.
 type fakeDriftClient struct {
-	src map[string]map[string]map[string]string
-	ns  []string
-	// target annotations/labels pre-existing
+	mutex sync.Mutex
+	src   map[string]map[string]map[string]string
+	ns    []string
+	// target data/annotations/labels pre-existing
+	tgtData map[string]string
 	tgtAnn  map[string]string
 	tgtLbl  map[string]string
 	upserts int
 }
 
-func (f *fakeDriftClient) GetSourceConfigMap(ns, name string) (map[string]string, error) {
+func (f *fakeDriftClient) GetSourceConfigMap(_ context.Context, ns, name string) (map[string]string, map[string][]byte, error) {
 	if m, ok := f.src[ns]; ok {
 		if d, ok := m[name]; ok {
-			return d, nil
+			return d, nil, nil
 		}
 	}
-	return nil, nil
+	return nil, nil, nil
 }
-func (f *fakeDriftClient) ListNamespacesBySelector(_ map[string]string, _ []adapters.LabelSelectorRequirement) ([]string, error) {
+func (f *fakeDriftClient) ListNamespacesBySelector(_ context.Context, _ map[string]string, _ []adapters.LabelSelectorRequirement) ([]string, error) {
 	return f.ns, nil
 }
 
-func (f *fakeDriftClient) UpsertConfigMap(ns, name string, data map[string]string, labels, annotations map[string]string) error {
+func (f *fakeDriftClient) GetNamespaceMetadata(_ context.Context, name string) (map[string]string, map[string]string, error) {
+	return nil, nil, nil
+}
+
+func (f *fakeDriftClient) UpsertConfigMap(_ context.Context, ns, name string, data map[string]string, binaryData map[string][]byte, labels, annotations map[string]string) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
 	f.upserts++
 	return nil
 }
-func (f *fakeDriftClient) GetTargetConfigMap(namespace, name string) (map[string]string, map[string]string, map[string]string, bool, error) {
-	return nil, f.tgtLbl, f.tgtAnn, true, nil
+func (f *fakeDriftClient) GetTargetConfigMap(_ context.Context, namespace, name string) (map[string]string, map[string][]byte, map[string]string, map[string]string, bool, error) {
+	return f.tgtData, nil, f.tgtLbl, f.tgtAnn, true, nil
 }
-func (f *fakeDriftClient) ListManagedTargetNamespaces(source string, name string) ([]string, error) {
-	return []string{"a"}, nil
+func (f *fakeDriftClient) ListManagedTargets(_ context.Context, source, owner string) ([]adapters.ManagedTarget, error) {
+	return legacyTargets(source, []string{"a"}), nil
 }
-func (f *fakeDriftClient) DeleteConfigMap(namespace, name string) error { return nil }
-func (f *fakeDriftClient) UpdateConfigMapMetadata(namespace, name string, labels, annotations map[string]string) error {
+func (f *fakeDriftClient) DeleteConfigMap(_ context.Context, namespace, name string) error {
+	return nil
+}
+func (f *fakeDriftClient) UpdateConfigMapMetadata(_ context.Context, namespace, name string, labels, annotations map[string]string) error {
 	return nil
 }
 
@@ -49,7 +62,7 @@ func TestDriftOverwriteUpdates(t *testing.T) {
 	r := NewReconciler(f, nil, nil)
 	key := Key{Namespace: "default", Name: "cp"}
 	s := &core.ConfigPropagationSpec{SourceRef: core.ObjectRef{Namespace: "s", Name: "n"}, NamespaceSelector: &core.LabelSelector{}, ConflictPolicy: core.ConflictOverwrite, Strategy: &core.UpdateStrategy{Type: core.StrategyImmediate}}
-	if _, err := r.Reconcile(key, s); err != nil {
+	if _, err := r.Reconcile(context.Background(), key, s); err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
 	if f.upserts != 1 {
@@ -58,11 +71,12 @@ func TestDriftOverwriteUpdates(t *testing.T) {
 }
 
 func TestDriftSkipStillUpdatesManagedTargets(t *testing.T) {
-	f := &fakeDriftClient{src: map[string]map[string]map[string]string{"s": {"n": {"k": "v"}}}, ns: []string{"a"}, tgtAnn: map[string]string{core.HashAnnotation: "different"}, tgtLbl: map[string]string{core.ManagedLabel: "true"}}
+	previous := map[string]string{"k": "old"}
+	f := &fakeDriftClient{src: map[string]map[string]map[string]string{"s": {"n": {"k": "v"}}}, ns: []string{"a"}, tgtData: previous, tgtAnn: map[string]string{core.HashAnnotation: core.HashData(previous, nil)}, tgtLbl: map[string]string{core.ManagedLabel: "true"}}
 	r := NewReconciler(f, nil, nil)
 	key := Key{Namespace: "default", Name: "cp"}
 	s := &core.ConfigPropagationSpec{SourceRef: core.ObjectRef{Namespace: "s", Name: "n"}, NamespaceSelector: &core.LabelSelector{}, ConflictPolicy: core.ConflictSkip, Strategy: &core.UpdateStrategy{Type: core.StrategyImmediate}}
-	if _, err := r.Reconcile(key, s); err != nil {
+	if _, err := r.Reconcile(context.Background(), key, s); err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
 	if f.upserts != 1 {
@@ -77,7 +91,7 @@ func TestNoOpWhenHashesMatch(t *testing.T) {
 	key := Key{Namespace: "default", Name: "cp"}
 	s := &core.ConfigPropagationSpec{SourceRef: core.ObjectRef{Namespace: "s", Name: "n"}, NamespaceSelector: &core.LabelSelector{}, ConflictPolicy: core.ConflictOverwrite, Strategy: &core.UpdateStrategy{Type: core.StrategyImmediate}}
 	// First reconcile writes and sets hash
-	if _, err := r.Reconcile(key, s); err != nil {
+	if _, err := r.Reconcile(context.Background(), key, s); err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
 	if f.upserts != 1 {
@@ -85,8 +99,9 @@ func TestNoOpWhenHashesMatch(t *testing.T) {
 	}
 	// Simulate target now having matching hash by reusing same fake that returns found with same annotations set by previous call
 	// We approximate by setting tgtAnn to the source hash using core.HashData
-	f.tgtAnn[core.HashAnnotation] = core.HashData(map[string]string{"k": "v"})
-	if _, err := r.Reconcile(key, s); err != nil {
+	f.tgtData = map[string]string{"k": "v"}
+	f.tgtAnn[core.HashAnnotation] = core.HashData(f.tgtData, nil)
+	if _, err := r.Reconcile(context.Background(), key, s); err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
 	if f.upserts != 1 {
@@ -99,14 +114,14 @@ func TestSkipLeavesUnmanagedTargetsUntouched(t *testing.T) {
 	r := NewReconciler(f, nil, nil)
 	key := Key{Namespace: "default", Name: "cp"}
 	s := &core.ConfigPropagationSpec{SourceRef: core.ObjectRef{Namespace: "s", Name: "n"}, NamespaceSelector: &core.LabelSelector{}, ConflictPolicy: core.ConflictSkip, Strategy: &core.UpdateStrategy{Type: core.StrategyImmediate}}
-	result, err := r.Reconcile(key, s)
+	result, err := r.Reconcile(context.Background(), key, s)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
 	if f.upserts != 0 {
 		t.Fatalf("expected no upserts for unmanaged skip, got %d", f.upserts)
 	}
-	if len(result.OutOfSync) != 1 || result.OutOfSync[0].Namespace != "a" || result.OutOfSync[0].Reason != "ConflictPolicySkip" {
+	if len(result.OutOfSync) != 1 || result.OutOfSync[0].Namespace != "a" || result.OutOfSync[0].Reason != core.ReasonConflictPolicySkip {
 		t.Fatalf("expected outOfSync entry for unmanaged skip, got %+v", result.OutOfSync)
 	}
 }
@@ -116,10 +131,127 @@ func TestOverwriteAdoptsUnmanagedTarget(t *testing.T) {
 	r := NewReconciler(f, nil, nil)
 	key := Key{Namespace: "default", Name: "cp"}
 	s := &core.ConfigPropagationSpec{SourceRef: core.ObjectRef{Namespace: "s", Name: "n"}, NamespaceSelector: &core.LabelSelector{}, ConflictPolicy: core.ConflictOverwrite, Strategy: &core.UpdateStrategy{Type: core.StrategyImmediate}}
-	if _, err := r.Reconcile(key, s); err != nil {
+	if _, err := r.Reconcile(context.Background(), key, s); err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
 	if f.upserts != 1 {
 		t.Fatalf("expected upsert to adopt unmanaged target, got %d", f.upserts)
 	}
 }
+
+func TestContentDriftOverwriteCorrectsTarget(t *testing.T) {
+	desired := map[string]string{"k": "v"}
+	// The annotation claims the desired content, but someone edited the data in place.
+	f := &fakeDriftClient{src: map[string]map[string]map[string]string{"s": {"n": desired}}, ns: []string{"a"}, tgtData: map[string]string{"k": "hand-edited"}, tgtAnn: map[string]string{core.HashAnnotation: core.HashData(desired, nil)}, tgtLbl: map[string]string{core.ManagedLabel: "true"}}
+	eventRecorder := &capturingEventRecorder{}
+	metricsRecorder := newCapturingMetricsRecorder()
+	r := NewReconciler(f, eventRecorder, metricsRecorder)
+	key := Key{Namespace: "default", Name: "cp"}
+	s := &core.ConfigPropagationSpec{SourceRef: core.ObjectRef{Namespace: "s", Name: "n"}, NamespaceSelector: &core.LabelSelector{}, ConflictPolicy: core.ConflictOverwrite, Strategy: &core.UpdateStrategy{Type: core.StrategyImmediate}}
+	result, err := r.Reconcile(context.Background(), key, s)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if f.upserts != 1 {
+		t.Fatalf("expected drifted target to be corrected, got %d upserts", f.upserts)
+	}
+	if len(result.OutOfSync) != 0 || result.CompletedCount != 1 {
+		t.Fatalf("expected corrected target to count as synced, got %+v", result)
+	}
+	if metricsRecorder.drifts != 1 {
+		t.Fatalf("expected one drift metric, got %d", metricsRecorder.drifts)
+	}
+	if !hasEvent(eventRecorder, eventReasonConfigDrifted, "Warning") {
+		t.Fatalf("expected drift warning event, got %+v", eventRecorder.events)
+	}
+}
+
+func TestContentDriftSkipReportsTarget(t *testing.T) {
+	desired := map[string]string{"k": "v"}
+	f := &fakeDriftClient{src: map[string]map[string]map[string]string{"s": {"n": desired}}, ns: []string{"a"}, tgtData: map[string]string{"k": "hand-edited"}, tgtAnn: map[string]string{core.HashAnnotation: core.HashData(desired, nil)}, tgtLbl: map[string]string{core.ManagedLabel: "true"}}
+	eventRecorder := &capturingEventRecorder{}
+	metricsRecorder := newCapturingMetricsRecorder()
+	r := NewReconciler(f, eventRecorder, metricsRecorder)
+	key := Key{Namespace: "default", Name: "cp"}
+	s := &core.ConfigPropagationSpec{SourceRef: core.ObjectRef{Namespace: "s", Name: "n"}, NamespaceSelector: &core.LabelSelector{}, ConflictPolicy: core.ConflictSkip, Strategy: &core.UpdateStrategy{Type: core.StrategyImmediate}}
+	result, err := r.Reconcile(context.Background(), key, s)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if f.upserts != 0 {
+		t.Fatalf("expected drifted target to be left alone with skip, got %d upserts", f.upserts)
+	}
+	if len(result.OutOfSync) != 1 || result.OutOfSync[0].Reason != core.ReasonDrifted {
+		t.Fatalf("expected Drifted outOfSync entry, got %+v", result.OutOfSync)
+	}
+	if metricsRecorder.drifts != 1 {
+		t.Fatalf("expected one drift metric, got %d", metricsRecorder.drifts)
+	}
+	if !hasEvent(eventRecorder, eventReasonConfigDrifted, "Warning") {
+		t.Fatalf("expected drift warning event, got %+v", eventRecorder.events)
+	}
+}
+
+func TestStaleAnnotationWithMatchingDataIsNotDrift(t *testing.T) {
+	desired := map[string]string{"k": "v"}
+	f := &fakeDriftClient{src: map[string]map[string]map[string]string{"s": {"n": desired}}, ns: []string{"a"}, tgtData: map[string]string{"k": "v"}, tgtAnn: map[string]string{}, tgtLbl: map[string]string{core.ManagedLabel: "true"}}
+	metricsRecorder := newCapturingMetricsRecorder()
+	r := NewReconciler(f, nil, metricsRecorder)
+	key := Key{Namespace: "default", Name: "cp"}
+	s := &core.ConfigPropagationSpec{SourceRef: core.ObjectRef{Namespace: "s", Name: "n"}, NamespaceSelector: &core.LabelSelector{}, ConflictPolicy: core.ConflictSkip, Strategy: &core.UpdateStrategy{Type: core.StrategyImmediate}}
+	if _, err := r.Reconcile(context.Background(), key, s); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if f.upserts != 1 {
+		t.Fatalf("expected annotation refresh upsert, got %d", f.upserts)
+	}
+	if metricsRecorder.drifts != 0 {
+		t.Fatalf("expected no drift for matching data, got %d", metricsRecorder.drifts)
+	}
+}
+
+func hasEvent(recorder *capturingEventRecorder, reason, eventType string) bool {
+	for _, event := range recorder.events {
+		if event.reason == reason && event.eventType == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+func TestRollingRechecksCompletedTargetsForDrift(t *testing.T) {
+	for _, conflictPolicy := range []string{core.ConflictOverwrite, core.ConflictSkip} {
+		client := newMemoryKubeClient("a", "b")
+		client.put("src", "cfg", &memoryConfigMap{data: map[string]string{"k": "v"}})
+		r := NewReconciler(client, nil, nil)
+		key := Key{Namespace: "default", Name: "cp"}
+		s := rollingSpec(1)
+		s.ConflictPolicy = conflictPolicy
+
+		for batch := 0; batch < 2; batch++ {
+			if _, err := r.Reconcile(context.Background(), key, s); err != nil {
+				t.Fatalf("%s: reconcile: %v", conflictPolicy, err)
+			}
+		}
+
+		// a was completed in the first batch and is edited by hand once the rollout is done.
+		client.configMaps[[2]string{"a", "cfg"}].data = map[string]string{"k": "hand-edited"}
+
+		result, err := r.Reconcile(context.Background(), key, s)
+		if err != nil {
+			t.Fatalf("%s: reconcile: %v", conflictPolicy, err)
+		}
+
+		live := client.configMaps[[2]string{"a", "cfg"}].data["k"]
+		switch conflictPolicy {
+		case core.ConflictOverwrite:
+			if live != "v" || len(result.OutOfSync) != 0 {
+				t.Fatalf("overwrite: expected the completed target to be repaired, got %q %+v", live, result.OutOfSync)
+			}
+		case core.ConflictSkip:
+			if live != "hand-edited" || len(result.OutOfSync) != 1 || result.OutOfSync[0].Namespace != "a" || result.OutOfSync[0].Reason != core.ReasonDrifted {
+				t.Fatalf("skip: expected the completed target to be reported as drifted, got %q %+v", live, result.OutOfSync)
+			}
+		}
+	}
+}
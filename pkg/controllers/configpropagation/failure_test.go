@@ -0,0 +1,306 @@
+package configpropagation
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"configpropagation/pkg/adapters"
+	"configpropagation/pkg/core"
+)
+
+// fakeFailingClient fails writes for selected namespaces with the configured API error.
+type fakeFailingClient struct {
+	mutex        sync.Mutex
+	namespaces   []string
+	managed      []string
+	upsertErrors map[string]error
+	lookupErrors map[string]error
+	deleteErrors map[string]error
+	upserts      []string
+	deletes      []string
+	attempts     map[string]int
+}
+
+func (f *fakeFailingClient) GetSourceConfigMap(_ context.Context, namespace, name string) (map[string]string, map[string][]byte, error) {
+	return map[string]string{"k": "v"}, nil, nil
+}
+
+func (f *fakeFailingClient) ListNamespacesBySelector(context.Context, map[string]string, []adapters.LabelSelectorRequirement) ([]string, error) {
+	return append([]string(nil), f.namespaces...), nil
+}
+
+func (f *fakeFailingClient) GetNamespaceMetadata(_ context.Context, name string) (map[string]string, map[string]string, error) {
+	return nil, nil, nil
+}
+
+func (f *fakeFailingClient) UpsertConfigMap(_ context.Context, namespace, name string, data map[string]string, binaryData map[string][]byte, labels, annotations map[string]string) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if f.attempts == nil {
+		f.attempts = map[string]int{}
+	}
+	f.attempts[namespace]++
+	if err := f.upsertErrors[namespace]; err != nil {
+		return err
+	}
+	f.upserts = append(f.upserts, namespace)
+	return nil
+}
+
+func (f *fakeFailingClient) GetTargetConfigMap(_ context.Context, namespace, name string) (map[string]string, map[string][]byte, map[string]string, map[string]string, bool, error) {
+	if err := f.lookupErrors[namespace]; err != nil {
+		return nil, nil, nil, nil, false, err
+	}
+	return nil, nil, nil, nil, false, nil
+}
+
+func (f *fakeFailingClient) ListManagedTargets(_ context.Context, source, owner string) ([]adapters.ManagedTarget, error) {
+	return legacyTargets(source, f.managed), nil
+}
+
+func (f *fakeFailingClient) DeleteConfigMap(_ context.Context, namespace, name string) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if err := f.deleteErrors[namespace]; err != nil {
+		return err
+	}
+	f.deletes = append(f.deletes, namespace)
+	return nil
+}
+
+func (f *fakeFailingClient) UpdateConfigMapMetadata(_ context.Context, namespace, name string, labels, annotations map[string]string) error {
+	return nil
+}
+
+func TestFailedNamespacesAreIsolated(t *testing.T) {
+	configMaps := schema.GroupResource{Resource: "configmaps"}
+	client := &fakeFailingClient{
+		namespaces: []string{"a-denied", "b-ok", "c-gone", "d-flaky", "e-ok"},
+		managed:    []string{"orphan"},
+		upsertErrors: map[string]error{
+			"a-denied": apierrors.NewForbidden(configMaps, "cfg", fmt.Errorf("no RBAC")),
+			"c-gone":   apierrors.NewNotFound(schema.GroupResource{Resource: "namespaces"}, "c-gone"),
+		},
+		lookupErrors: map[string]error{"d-flaky": fmt.Errorf("connection reset")},
+	}
+	metricsRecorder := newCapturingMetricsRecorder()
+	reconciler := NewReconciler(client, nil, metricsRecorder)
+	spec := &core.ConfigPropagationSpec{
+		SourceRef:         core.ObjectRef{Namespace: "src", Name: "cfg"},
+		NamespaceSelector: &core.LabelSelector{},
+		Strategy:          &core.UpdateStrategy{Type: core.StrategyImmediate},
+	}
+
+	result, err := reconciler.Reconcile(context.Background(), Key{Namespace: "default", Name: "cp"}, spec)
+	if err != nil {
+		t.Fatalf("expected per-namespace failures not to fail the reconcile, got %v", err)
+	}
+
+	if !reflect.DeepEqual(client.upserts, []string{"b-ok", "e-ok"}) {
+		t.Fatalf("expected healthy namespaces to be written, got %v", client.upserts)
+	}
+	if !reflect.DeepEqual(client.deletes, []string{"orphan"}) {
+		t.Fatalf("expected cleanup to still run, got %v", client.deletes)
+	}
+
+	reasons := map[string]string{}
+	for _, item := range result.OutOfSync {
+		reasons[item.Namespace] = item.Reason
+	}
+	want := map[string]string{"a-denied": core.ReasonForbidden, "c-gone": core.ReasonNamespaceNotFound, "d-flaky": core.ReasonTransientError}
+	if !reflect.DeepEqual(reasons, want) {
+		t.Fatalf("unexpected failure classification: %+v", result.OutOfSync)
+	}
+	if result.CompletedCount != 2 {
+		t.Fatalf("expected two completed namespaces, got %+v", result)
+	}
+	if metricsRecorder.errors["upsert"] != 2 || metricsRecorder.errors["target_lookup"] != 1 {
+		t.Fatalf("expected errors recorded per stage, got %+v", metricsRecorder.errors)
+	}
+}
+
+func TestCleanupContinuesPastFailedNamespaces(t *testing.T) {
+	client := &fakeFailingClient{
+		managed:      []string{"a", "b", "c"},
+		deleteErrors: map[string]error{"b": fmt.Errorf("timeout")},
+	}
+	reconciler := NewReconciler(client, nil, nil)
+	spec := &core.ConfigPropagationSpec{SourceRef: core.ObjectRef{Namespace: "src", Name: "cfg"}, NamespaceSelector: &core.LabelSelector{}, Prune: boolPtr(true)}
+
+	failures, err := reconciler.cleanupDeselected(context.Background(), Key{Namespace: "default", Name: "cp"}, spec, mustTargetNamer(t, spec), nil, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(client.deletes, []string{"a", "c"}) {
+		t.Fatalf("expected other namespaces to be pruned, got %v", client.deletes)
+	}
+	if len(failures) != 1 || failures[0].Namespace != "b" || failures[0].Reason != core.ReasonTransientError {
+		t.Fatalf("expected failed prune to be reported, got %+v", failures)
+	}
+
+	if err := reconciler.Finalize(context.Background(), Key{Namespace: "default", Name: "cp"}, spec); err == nil {
+		t.Fatalf("expected finalize to fail while a namespace cannot be cleaned up")
+	}
+}
+
+func TestCleanupFailuresAreNotCountedOutOfSync(t *testing.T) {
+	client := &fakeFailingClient{
+		namespaces:   []string{"a"},
+		managed:      []string{"a", "gone"},
+		deleteErrors: map[string]error{"gone": apierrors.NewForbidden(schema.GroupResource{Resource: "configmaps"}, "cfg", fmt.Errorf("no RBAC"))},
+	}
+	spec := &core.ConfigPropagationSpec{SourceRef: core.ObjectRef{Namespace: "src", Name: "cfg"}, NamespaceSelector: &core.LabelSelector{}, Prune: boolPtr(true)}
+
+	result, err := NewReconciler(client, nil, nil).Reconcile(context.Background(), Key{Namespace: "default", Name: "cp"}, spec)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result.OutOfSync) != 0 || result.CompletedCount != result.TotalTargets {
+		t.Fatalf("expected the selected namespace to be in sync, got %+v", result)
+	}
+	if len(result.CleanupFailures) != 1 || result.CleanupFailures[0].Namespace != "gone" || result.CleanupFailures[0].Reason != core.ReasonForbidden {
+		t.Fatalf("expected the failed prune to be reported separately, got %+v", result.CleanupFailures)
+	}
+}
+
+func TestFailingNamespaceBacksOff(t *testing.T) {
+	client := &fakeFailingClient{
+		namespaces:   []string{"broken", "healthy"},
+		upsertErrors: map[string]error{"broken": apierrors.NewForbidden(schema.GroupResource{Resource: "configmaps"}, "cfg", fmt.Errorf("no RBAC"))},
+	}
+	reconciler := NewReconciler(client, nil, nil)
+
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	reconciler.targetBackoff = core.NewBackoffTracker[targetRetryKey](time.Second, time.Minute, func() time.Time { return now }, func() float64 { return 1 })
+
+	key := Key{Namespace: "default", Name: "cp"}
+	spec := func() *core.ConfigPropagationSpec {
+		return &core.ConfigPropagationSpec{
+			SourceRef:         core.ObjectRef{Namespace: "src", Name: "cfg"},
+			NamespaceSelector: &core.LabelSelector{},
+			Strategy:          &core.UpdateStrategy{Type: core.StrategyImmediate},
+		}
+	}
+
+	result, err := reconciler.Reconcile(context.Background(), key, spec())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result.OutOfSync) != 1 || result.OutOfSync[0].Reason != core.ReasonForbidden || result.RetryAfter != time.Second {
+		t.Fatalf("expected first failure to be classified and retried after 1s, got %+v", result)
+	}
+
+	// Before the delay elapses the broken namespace is not touched again.
+	result, err = reconciler.Reconcile(context.Background(), key, spec())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if client.attempts["broken"] != 1 {
+		t.Fatalf("expected backed-off namespace to be skipped, got %d attempts", client.attempts["broken"])
+	}
+	item := result.OutOfSync[0]
+	if item.Reason != core.ReasonBackingOff || !strings.Contains(item.Message, "2024-01-01T12:00:01Z") || !strings.Contains(item.Message, core.ReasonForbidden) {
+		t.Fatalf("expected BackingOff with next retry time, got %+v", item)
+	}
+	if client.attempts["healthy"] != 2 {
+		t.Fatalf("expected healthy namespace to keep syncing, got %d attempts", client.attempts["healthy"])
+	}
+
+	// Once the delay elapses the namespace is retried and the next delay doubles.
+	now = now.Add(time.Second)
+	result, _ = reconciler.Reconcile(context.Background(), key, spec())
+	if client.attempts["broken"] != 2 || result.RetryAfter != 2*time.Second {
+		t.Fatalf("expected retry with doubled delay, got attempts=%d result=%+v", client.attempts["broken"], result)
+	}
+
+	// A successful write clears the history.
+	now = now.Add(2 * time.Second)
+	delete(client.upsertErrors, "broken")
+	result, _ = reconciler.Reconcile(context.Background(), key, spec())
+	if len(result.OutOfSync) != 0 || result.RetryAfter != 0 {
+		t.Fatalf("expected recovered namespace to be in sync, got %+v", result)
+	}
+	if _, waiting := reconciler.targetBackoff.Waiting(targetRetryKey{propagation: key, namespace: "broken"}); waiting {
+		t.Fatalf("expected success to clear backoff")
+	}
+}
+
+func TestDeselectedNamespaceForgetsBackoff(t *testing.T) {
+	client := &fakeFailingClient{namespaces: []string{"broken"}, upsertErrors: map[string]error{"broken": fmt.Errorf("boom")}}
+	reconciler := NewReconciler(client, nil, nil)
+	key := Key{Namespace: "default", Name: "cp"}
+	spec := &core.ConfigPropagationSpec{SourceRef: core.ObjectRef{Namespace: "src", Name: "cfg"}, NamespaceSelector: &core.LabelSelector{}}
+
+	if _, err := reconciler.Reconcile(context.Background(), key, spec); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	retryKey := targetRetryKey{propagation: key, namespace: "broken"}
+	if _, waiting := reconciler.targetBackoff.Waiting(retryKey); !waiting {
+		t.Fatalf("expected failed namespace to back off")
+	}
+
+	client.namespaces = nil
+	if _, err := reconciler.Reconcile(context.Background(), key, spec); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, waiting := reconciler.targetBackoff.Waiting(retryKey); waiting {
+		t.Fatalf("expected deselected namespace to drop its backoff")
+	}
+}
+
+// refusingClient is a memoryKubeClient whose writes into one namespace are always forbidden.
+type refusingClient struct {
+	*memoryKubeClient
+	refusedNamespace string
+	refusals         int
+}
+
+func (client *refusingClient) UpsertConfigMap(requestContext context.Context, namespace, name string, data map[string]string, binaryData map[string][]byte, labels, annotations map[string]string) error {
+	if namespace == client.refusedNamespace {
+		client.refusals++
+		return apierrors.NewForbidden(schema.GroupResource{Resource: "configmaps"}, name, fmt.Errorf("no RBAC"))
+	}
+
+	return client.memoryKubeClient.UpsertConfigMap(requestContext, namespace, name, data, binaryData, labels, annotations)
+}
+
+func TestRollingSkipsBackingOffNamespacesWhenFillingABatch(t *testing.T) {
+	client := &refusingClient{memoryKubeClient: newMemoryKubeClient("a", "b", "c"), refusedNamespace: "a"}
+	client.put("src", "cfg", &memoryConfigMap{data: map[string]string{"k": "v"}})
+	reconciler := NewReconciler(client, nil, nil)
+
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	reconciler.targetBackoff = core.NewBackoffTracker[targetRetryKey](time.Minute, time.Hour, func() time.Time { return now }, func() float64 { return 1 })
+
+	key := Key{Namespace: "default", Name: "cp"}
+	var result core.RolloutResult
+	for _, wantBatch := range [][]string{{"a"}, {"b"}, {"c"}} {
+		var err error
+		if result, err = reconciler.Reconcile(context.Background(), key, rollingSpec(1)); err != nil {
+			t.Fatalf("reconcile: %v", err)
+		}
+		if !reflect.DeepEqual(result.Planned, wantBatch) {
+			t.Fatalf("expected batch %v, got %v", wantBatch, result.Planned)
+		}
+	}
+
+	if client.refusals != 1 {
+		t.Fatalf("expected the refused namespace to be tried once while backing off, got %d", client.refusals)
+	}
+	if result.CompletedCount != 2 || len(result.OutOfSync) != 1 || result.OutOfSync[0].Namespace != "a" || result.OutOfSync[0].Reason != core.ReasonBackingOff {
+		t.Fatalf("expected the rollout to finish around the backing-off namespace, got %+v", result)
+	}
+	if result.RetryAfter != time.Minute {
+		t.Fatalf("expected a requeue for the backing-off namespace, got %v", result.RetryAfter)
+	}
+}
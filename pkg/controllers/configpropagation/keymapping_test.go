@@ -0,0 +1,57 @@
+package configpropagation
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"configpropagation/pkg/core"
+)
+
+func TestKeyMappingsRenameBeforeHashing(t *testing.T) {
+	client := newMemoryKubeClient("team-a")
+	client.put("platform", "app", &memoryConfigMap{data: map[string]string{"prod.properties": "a=1", "dev.properties": "a=0"}})
+
+	spec := &core.ConfigPropagationSpec{
+		SourceRef:         core.ObjectRef{Namespace: "platform", Name: "app"},
+		NamespaceSelector: &core.LabelSelector{},
+		Strategy:          &core.UpdateStrategy{Type: core.StrategyImmediate},
+		DataKeys:          []string{"prod.properties"},
+		KeyMappings:       []core.KeyMapping{{From: "prod.properties", To: "application.properties"}},
+	}
+
+	if _, err := NewReconciler(client, nil, nil).Reconcile(context.Background(), Key{Namespace: "default", Name: "cp"}, spec); err != nil {
+		t.Fatalf("reconcile: %v", err)
+	}
+
+	target := client.configMaps[[2]string{"team-a", "app"}]
+	wantData := map[string]string{"application.properties": "a=1"}
+	if target == nil || !reflect.DeepEqual(target.data, wantData) {
+		t.Fatalf("expected renamed key in target, got %+v", target)
+	}
+
+	if target.annotations[core.HashAnnotation] != core.HashData(wantData, nil) {
+		t.Fatalf("expected hash of mapped content, got %q", target.annotations[core.HashAnnotation])
+	}
+}
+
+func TestKeyMappingCollisionFailsReconcile(t *testing.T) {
+	client := newMemoryKubeClient("team-a")
+	client.put("platform", "app", &memoryConfigMap{data: map[string]string{"prod-url": "a", "url": "b"}})
+
+	spec := &core.ConfigPropagationSpec{
+		SourceRef:         core.ObjectRef{Namespace: "platform", Name: "app"},
+		NamespaceSelector: &core.LabelSelector{},
+		KeyMappings:       []core.KeyMapping{{StripPrefix: "prod-"}},
+	}
+
+	_, err := NewReconciler(client, nil, nil).Reconcile(context.Background(), Key{Namespace: "default", Name: "cp"}, spec)
+	if err == nil || !strings.Contains(err.Error(), "both map to") {
+		t.Fatalf("expected mapping collision error, got %v", err)
+	}
+
+	if _, written := client.configMaps[[2]string{"team-a", "app"}]; written {
+		t.Fatalf("expected nothing to be written when mappings collide")
+	}
+}
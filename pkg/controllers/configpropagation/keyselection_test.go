@@ -0,0 +1,42 @@
+package configpropagation
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"configpropagation/pkg/core"
+)
+
+func TestKeyPatternsSelectAndReportMatchedKeys(t *testing.T) {
+	client := newMemoryKubeClient("team-a")
+	client.put("platform", "features", &memoryConfigMap{data: map[string]string{
+		"feature-a.yaml":      "a",
+		"feature-b.yaml":      "b",
+		"feature-draft.yaml":  "draft",
+		"settings.properties": "s",
+	}})
+
+	spec := &core.ConfigPropagationSpec{
+		SourceRef:         core.ObjectRef{Namespace: "platform", Name: "features"},
+		NamespaceSelector: &core.LabelSelector{},
+		Strategy:          &core.UpdateStrategy{Type: core.StrategyImmediate},
+		DataKeys:          []string{"feature-*.yaml"},
+		ExcludeKeys:       []string{"/.*-draft\\.yaml/"},
+	}
+
+	result, err := NewReconciler(client, nil, nil).Reconcile(context.Background(), Key{Namespace: "default", Name: "cp"}, spec)
+	if err != nil {
+		t.Fatalf("reconcile: %v", err)
+	}
+
+	target := client.configMaps[[2]string{"team-a", "features"}]
+	if target == nil || !reflect.DeepEqual(target.data, map[string]string{"feature-a.yaml": "a", "feature-b.yaml": "b"}) {
+		t.Fatalf("unexpected target %+v", target)
+	}
+
+	wantMatched := []core.SourceKeys{{Source: "platform/features", Keys: []string{"feature-a.yaml", "feature-b.yaml"}}}
+	if !reflect.DeepEqual(result.MatchedKeys, wantMatched) {
+		t.Fatalf("unexpected matched keys %+v", result.MatchedKeys)
+	}
+}
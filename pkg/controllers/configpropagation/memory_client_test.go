@@ -0,0 +1,167 @@
+package configpropagation
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"configpropagation/pkg/adapters"
+	"configpropagation/pkg/core"
+)
+
+// memoryConfigMap is a ConfigMap held by memoryKubeClient.
+type memoryConfigMap struct {
+	data        map[string]string
+	binaryData  map[string][]byte
+	labels      map[string]string
+	annotations map[string]string
+}
+
+// memoryKubeClient is an in-memory KubeClient that keeps every written ConfigMap, for end-to-end reconcile tests.
+type memoryKubeClient struct {
+	mutex                sync.Mutex
+	namespaces           []string
+	namespaceLabels      map[string]map[string]string
+	namespaceAnnotations map[string]map[string]string
+	configMaps           map[[2]string]*memoryConfigMap
+	deletes              [][2]string
+}
+
+// newMemoryKubeClient returns a client selecting the namespaces and holding no ConfigMaps.
+func newMemoryKubeClient(namespaces ...string) *memoryKubeClient {
+	return &memoryKubeClient{namespaces: namespaces, configMaps: map[[2]string]*memoryConfigMap{}}
+}
+
+// put stores a ConfigMap as if it had been created out of band.
+func (client *memoryKubeClient) put(namespace, name string, configMap *memoryConfigMap) {
+	client.configMaps[[2]string{namespace, name}] = configMap
+}
+
+// names returns the sorted namespace/name of every stored ConfigMap.
+func (client *memoryKubeClient) names() []string {
+	var names []string
+	for key := range client.configMaps {
+		names = append(names, key[0]+"/"+key[1])
+	}
+
+	sort.Strings(names)
+	return names
+}
+
+func (client *memoryKubeClient) GetSourceConfigMap(_ context.Context, namespace, name string) (map[string]string, map[string][]byte, error) {
+	client.mutex.Lock()
+	defer client.mutex.Unlock()
+
+	configMap, found := client.configMaps[[2]string{namespace, name}]
+	if !found {
+		return nil, nil, nil
+	}
+
+	return copyMap(configMap.data), configMap.binaryData, nil
+}
+
+func (client *memoryKubeClient) GetSourceAnnotations(_ context.Context, namespace, name string) (map[string]string, bool, error) {
+	client.mutex.Lock()
+	defer client.mutex.Unlock()
+
+	configMap, found := client.configMaps[[2]string{namespace, name}]
+	if !found {
+		return nil, false, nil
+	}
+
+	return copyMap(configMap.annotations), true, nil
+}
+
+func (client *memoryKubeClient) ListNamespacesBySelector(context.Context, map[string]string, []adapters.LabelSelectorRequirement) ([]string, error) {
+	client.mutex.Lock()
+	defer client.mutex.Unlock()
+
+	return append([]string(nil), client.namespaces...), nil
+}
+
+func (client *memoryKubeClient) GetNamespaceMetadata(_ context.Context, name string) (map[string]string, map[string]string, error) {
+	client.mutex.Lock()
+	defer client.mutex.Unlock()
+
+	return copyMap(client.namespaceLabels[name]), copyMap(client.namespaceAnnotations[name]), nil
+}
+
+func (client *memoryKubeClient) UpsertConfigMap(_ context.Context, namespace, name string, data map[string]string, binaryData map[string][]byte, labels, annotations map[string]string) error {
+	client.mutex.Lock()
+	defer client.mutex.Unlock()
+
+	var liveLabels, liveAnnotations map[string]string
+	if existing, found := client.configMaps[[2]string{namespace, name}]; found {
+		liveLabels, liveAnnotations = existing.labels, existing.annotations
+	}
+
+	mergedLabels, mergedAnnotations := core.MergeOwnedMetadata(liveLabels, liveAnnotations, labels, annotations)
+	client.put(namespace, name, &memoryConfigMap{data: copyMap(data), binaryData: binaryData, labels: mergedLabels, annotations: mergedAnnotations})
+	return nil
+}
+
+func (client *memoryKubeClient) GetTargetConfigMap(_ context.Context, namespace, name string) (map[string]string, map[string][]byte, map[string]string, map[string]string, bool, error) {
+	client.mutex.Lock()
+	defer client.mutex.Unlock()
+
+	configMap, found := client.configMaps[[2]string{namespace, name}]
+	if !found {
+		return nil, nil, nil, nil, false, nil
+	}
+
+	return copyMap(configMap.data), configMap.binaryData, copyMap(configMap.labels), copyMap(configMap.annotations), true, nil
+}
+
+func (client *memoryKubeClient) ListManagedTargets(_ context.Context, source, owner string) ([]adapters.ManagedTarget, error) {
+	client.mutex.Lock()
+	defer client.mutex.Unlock()
+
+	var targets []adapters.ManagedTarget
+	for key, configMap := range client.configMaps {
+		if configMap.labels[core.ManagedLabel] != "true" || (configMap.annotations[core.SourceAnnotation] != source && configMap.annotations[core.OwnerAnnotation] != owner) {
+			continue
+		}
+
+		targets = append(targets, adapters.ManagedTarget{Namespace: key[0], Name: key[1], Owner: configMap.annotations[core.OwnerAnnotation], OwnerUID: configMap.annotations[core.OwnerUIDAnnotation]})
+	}
+
+	sort.Slice(targets, func(left, right int) bool {
+		return targets[left].Namespace+"/"+targets[left].Name < targets[right].Namespace+"/"+targets[right].Name
+	})
+	return targets, nil
+}
+
+func (client *memoryKubeClient) DeleteConfigMap(_ context.Context, namespace, name string) error {
+	client.mutex.Lock()
+	defer client.mutex.Unlock()
+
+	client.deletes = append(client.deletes, [2]string{namespace, name})
+	delete(client.configMaps, [2]string{namespace, name})
+	return nil
+}
+
+func (client *memoryKubeClient) UpdateConfigMapMetadata(_ context.Context, namespace, name string, labels, annotations map[string]string) error {
+	client.mutex.Lock()
+	defer client.mutex.Unlock()
+
+	if configMap, found := client.configMaps[[2]string{namespace, name}]; found {
+		configMap.labels = copyMap(labels)
+		configMap.annotations = copyMap(annotations)
+	}
+
+	return nil
+}
+
+// copyMap duplicates a string map so stored state cannot be mutated by callers.
+func copyMap(source map[string]string) map[string]string {
+	if source == nil {
+		return nil
+	}
+
+	copied := make(map[string]string, len(source))
+	for key, value := range source {
+		copied[key] = value
+	}
+
+	return copied
+}
@@ -0,0 +1,312 @@
+package configpropagation
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"configpropagation/pkg/adapters"
+	"configpropagation/pkg/core"
+)
+
+// Plan runs the reconcile pipeline for the spec (effective data, target listing, drift checks and cleanup) against
+// the read methods of the client adapter and reports what it would change per target, across every selected
+// namespace even for rolling rollouts. Nothing is written, and the rollout progress, retry state, events and
+// metrics of regular reconciles are left untouched. Policy and source access violations are returned as errors,
+// as Reconcile would.
+func (reconciler *Reconciler) Plan(requestContext context.Context, key Key, spec *core.ConfigPropagationSpec) (*core.PlanStatus, error) {
+	return reconciler.plan(requestContext, key, spec, nil, true)
+}
+
+// PlanNextBatch plans only what the next reconcile would write: for a rolling rollout, the next batch after the
+// progress persisted in status.rollout. Namespaces left for later batches are counted as pending, and targets
+// that are backing off are planned as if they were retried.
+func (reconciler *Reconciler) PlanNextBatch(requestContext context.Context, key Key, spec *core.ConfigPropagationSpec, rollout *core.RolloutStatus) (*core.PlanStatus, error) {
+	return reconciler.plan(requestContext, key, spec, rollout, false)
+}
+
+// plan runs reconcileImpl on a read-only copy of the reconciler, seeded with the persisted rollout progress.
+func (reconciler *Reconciler) plan(requestContext context.Context, key Key, spec *core.ConfigPropagationSpec, rollout *core.RolloutStatus, wholeSelection bool) (*core.PlanStatus, error) {
+	if spec == nil {
+		return nil, fmt.Errorf("spec is nil")
+	}
+
+	core.DefaultSpec(spec)
+	if err := core.ValidateSpec(spec); err != nil {
+		return nil, err
+	}
+
+	planningClient := &planningClient{readClient: reconciler.clientAdapter}
+	planner := reconciler.readOnlyCopy(planningClient)
+	planner.planWholeSelection = wholeSelection
+	planner.RestoreRollout(key, rollout)
+
+	result, err := planner.reconcileImpl(requestContext, key, spec)
+	if err != nil {
+		return nil, err
+	}
+
+	plannedTargets := planningClient.plannedTargets()
+	for _, item := range append(result.OutOfSync, result.CleanupFailures...) {
+		plannedTargets = append(plannedTargets, core.PlannedTarget{Namespace: item.Namespace, Action: core.PlanSkip, Reason: item.Reason, Message: item.Message})
+	}
+
+	return core.NewPlan(plannedTargets, result.TotalTargets, result.CompletedCount), nil
+}
+
+// TargetDiff compares one selected target with the data the sources produce for its namespace.
+type TargetDiff struct {
+	Namespace string
+	Name      string
+	// Found is false when the target does not exist yet; Keys then lists every propagated key as missing.
+	Found bool
+	Keys  []core.KeyDiff
+	// Err is set when the target name, its rendered data or the target itself could not be read.
+	Err error
+}
+
+// Diff compares the data the sources produce, after key selection, key mappings and templates, with the live
+// data of the target in every selected namespace, in namespace order. Only read methods of the client adapter
+// are used, and no events or metrics are recorded.
+func (reconciler *Reconciler) Diff(requestContext context.Context, key Key, spec *core.ConfigPropagationSpec) ([]TargetDiff, error) {
+	if spec == nil {
+		return nil, fmt.Errorf("spec is nil")
+	}
+
+	core.DefaultSpec(spec)
+	if err := core.ValidateSpec(spec); err != nil {
+		return nil, err
+	}
+
+	reader := reconciler.readOnlyCopy(&planningClient{readClient: reconciler.clientAdapter})
+
+	content, err := reader.desiredContent(requestContext, key, spec)
+	if err != nil {
+		return nil, err
+	}
+
+	targetNamespaces, err := listTargets(requestContext, reader.clientAdapter, spec.NamespaceSelector)
+	if err != nil {
+		return nil, fmt.Errorf("list namespaces: %w", err)
+	}
+
+	sort.Strings(targetNamespaces)
+
+	targetNamer, err := core.NewTargetNamer(spec)
+	if err != nil {
+		return nil, err
+	}
+
+	differences := make([]TargetDiff, 0, len(targetNamespaces))
+	for _, targetNamespace := range targetNamespaces {
+		differences = append(differences, reader.diffTarget(requestContext, targetNamer, targetNamespace, content))
+	}
+
+	return differences, nil
+}
+
+// diffTarget compares the target in one namespace with its rendered content.
+func (reconciler *Reconciler) diffTarget(requestContext context.Context, targetNamer *core.TargetNamer, targetNamespace string, content desiredContent) TargetDiff {
+	difference := TargetDiff{Namespace: targetNamespace}
+
+	configMapName, err := targetNamer.Name(targetNamespace)
+	if err != nil {
+		difference.Err = err
+		return difference
+	}
+	difference.Name = configMapName
+
+	var namespaceLabels, namespaceAnnotations map[string]string
+	if content.template != nil {
+		namespaceLabels, namespaceAnnotations, err = reconciler.clientAdapter.GetNamespaceMetadata(requestContext, targetNamespace)
+		if err != nil {
+			difference.Err = fmt.Errorf("get namespace %s: %w", targetNamespace, err)
+			return difference
+		}
+	}
+
+	targetContent, err := content.forNamespace(targetNamespace, namespaceLabels, namespaceAnnotations)
+	if err != nil {
+		difference.Err = err
+		return difference
+	}
+
+	liveData, liveBinaryData, _, _, found, err := reconciler.clientAdapter.GetTargetConfigMap(requestContext, targetNamespace, configMapName)
+	if err != nil {
+		difference.Err = fmt.Errorf("get target %s/%s: %w", targetNamespace, configMapName, err)
+		return difference
+	}
+
+	difference.Found = found
+	difference.Keys = core.DiffData(liveData, liveBinaryData, targetContent.data, targetContent.binaryData)
+	return difference
+}
+
+// readOnlyCopy returns a reconciler that shares nothing mutable with this one: it reconciles through the given
+// planning client, with fresh rollout and retry state and without events or metrics.
+func (reconciler *Reconciler) readOnlyCopy(planningClient *planningClient) *Reconciler {
+	retryBase, retryMax := core.DefaultRetryBounds()
+
+	return &Reconciler{
+		clientAdapter:      planningClient,
+		workQueue:          core.NewWorkQueue[Key](),
+		rolloutPlanner:     core.NewRolloutPlanner(),
+		targetBackoff:      core.NewBackoffTracker[targetRetryKey](retryBase, retryMax, nil, nil),
+		eventRecorder:      adapters.NewNoopEventRecorder(),
+		metricsRecorder:    adapters.NewNoopMetricsRecorder(),
+		targetWorkers:      reconciler.targetWorkers,
+		requireSourceOptIn: reconciler.requireSourceOptIn,
+	}
+}
+
+// planningClient serves reads from the wrapped client and records writes as planned target actions instead of
+// performing them. Writes may be recorded concurrently by the target workers.
+type planningClient struct {
+	readClient adapters.KubeClient
+
+	lock    sync.Mutex
+	planned []core.PlannedTarget
+}
+
+var (
+	_ adapters.PolicyLister         = &planningClient{}
+	_ adapters.SourceMetadataReader = &planningClient{}
+)
+
+// plannedTargets returns the actions recorded so far.
+func (planningClient *planningClient) plannedTargets() []core.PlannedTarget {
+	planningClient.lock.Lock()
+	defer planningClient.lock.Unlock()
+
+	return append([]core.PlannedTarget(nil), planningClient.planned...)
+}
+
+// record appends one planned action.
+func (planningClient *planningClient) record(plannedTarget core.PlannedTarget) {
+	planningClient.lock.Lock()
+	defer planningClient.lock.Unlock()
+
+	planningClient.planned = append(planningClient.planned, plannedTarget)
+}
+
+// GetSourceConfigMap reads the source from the wrapped client.
+func (planningClient *planningClient) GetSourceConfigMap(requestContext context.Context, namespace, name string) (map[string]string, map[string][]byte, error) {
+	return planningClient.readClient.GetSourceConfigMap(requestContext, namespace, name)
+}
+
+// ListNamespacesBySelector lists namespaces through the wrapped client.
+func (planningClient *planningClient) ListNamespacesBySelector(requestContext context.Context, matchLabels map[string]string, selectorRequirements []adapters.LabelSelectorRequirement) ([]string, error) {
+	return planningClient.readClient.ListNamespacesBySelector(requestContext, matchLabels, selectorRequirements)
+}
+
+// GetNamespaceMetadata reads namespace metadata from the wrapped client.
+func (planningClient *planningClient) GetNamespaceMetadata(requestContext context.Context, name string) (map[string]string, map[string]string, error) {
+	return planningClient.readClient.GetNamespaceMetadata(requestContext, name)
+}
+
+// GetTargetConfigMap reads a target from the wrapped client.
+func (planningClient *planningClient) GetTargetConfigMap(requestContext context.Context, namespace, name string) (map[string]string, map[string][]byte, map[string]string, map[string]string, bool, error) {
+	return planningClient.readClient.GetTargetConfigMap(requestContext, namespace, name)
+}
+
+// ListManagedTargets lists managed targets through the wrapped client.
+func (planningClient *planningClient) ListManagedTargets(requestContext context.Context, source, owner string) ([]adapters.ManagedTarget, error) {
+	return planningClient.readClient.ListManagedTargets(requestContext, source, owner)
+}
+
+// ListPolicies reads the policies through the wrapped client; clients that cannot see none, as in Reconcile.
+func (planningClient *planningClient) ListPolicies(requestContext context.Context) ([]core.Policy, error) {
+	policyLister, ok := planningClient.readClient.(adapters.PolicyLister)
+	if !ok {
+		return nil, nil
+	}
+
+	return policyLister.ListPolicies(requestContext)
+}
+
+// GetSourceAnnotations reads source annotations through the wrapped client.
+func (planningClient *planningClient) GetSourceAnnotations(requestContext context.Context, namespace, name string) (map[string]string, bool, error) {
+	metadataReader, ok := planningClient.readClient.(adapters.SourceMetadataReader)
+	if !ok {
+		return nil, false, fmt.Errorf("client cannot read source annotations")
+	}
+
+	return metadataReader.GetSourceAnnotations(requestContext, namespace, name)
+}
+
+// UpsertConfigMap records a create, or an update naming the keys it would change, after reading the live target.
+func (planningClient *planningClient) UpsertConfigMap(requestContext context.Context, namespace, name string, data map[string]string, binaryData map[string][]byte, _, _ map[string]string) error {
+	liveData, liveBinaryData, liveLabels, _, found, err := planningClient.readClient.GetTargetConfigMap(requestContext, namespace, name)
+	if err != nil {
+		return err
+	}
+
+	if !found {
+		planningClient.record(core.PlannedTarget{Namespace: namespace, Name: name, Action: core.PlanCreate})
+		return nil
+	}
+
+	message := "updates metadata only"
+	if changedKeys := changedDataKeys(liveData, liveBinaryData, data, binaryData); len(changedKeys) > 0 {
+		message = "changes keys " + strings.Join(changedKeys, ", ")
+	}
+
+	if liveLabels[core.ManagedLabel] != "true" {
+		message = "overwrites an unmanaged ConfigMap; " + message
+	}
+
+	planningClient.record(core.PlannedTarget{Namespace: namespace, Name: name, Action: core.PlanUpdate, Message: message})
+	return nil
+}
+
+// DeleteConfigMap records a prune.
+func (planningClient *planningClient) DeleteConfigMap(_ context.Context, namespace, name string) error {
+	planningClient.record(core.PlannedTarget{Namespace: namespace, Name: name, Action: core.PlanPrune})
+	return nil
+}
+
+// UpdateConfigMapMetadata records a detach, the only metadata-only write of the pipeline.
+func (planningClient *planningClient) UpdateConfigMapMetadata(_ context.Context, namespace, name string, _, _ map[string]string) error {
+	planningClient.record(core.PlannedTarget{Namespace: namespace, Name: name, Action: core.PlanDetach, Message: "prune is disabled; the ConfigMap is kept without managed markers"})
+	return nil
+}
+
+// changedDataKeys lists the keys that a write of data and binaryData would add, change or remove, sorted.
+func changedDataKeys(liveData map[string]string, liveBinaryData map[string][]byte, data map[string]string, binaryData map[string][]byte) []string {
+	changedKeys := map[string]struct{}{}
+
+	for dataKey, value := range data {
+		if liveValue, exists := liveData[dataKey]; !exists || liveValue != value {
+			changedKeys[dataKey] = struct{}{}
+		}
+	}
+
+	for dataKey, value := range binaryData {
+		if liveValue, exists := liveBinaryData[dataKey]; !exists || !bytes.Equal(liveValue, value) {
+			changedKeys[dataKey] = struct{}{}
+		}
+	}
+
+	for dataKey := range liveData {
+		if _, desired := data[dataKey]; !desired {
+			changedKeys[dataKey] = struct{}{}
+		}
+	}
+
+	for dataKey := range liveBinaryData {
+		if _, desired := binaryData[dataKey]; !desired {
+			changedKeys[dataKey] = struct{}{}
+		}
+	}
+
+	sortedKeys := make([]string, 0, len(changedKeys))
+	for dataKey := range changedKeys {
+		sortedKeys = append(sortedKeys, dataKey)
+	}
+
+	sort.Strings(sortedKeys)
+	return sortedKeys
+}
@@ -0,0 +1,242 @@
+package configpropagation
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"configpropagation/pkg/adapters"
+	configv1alpha1 "configpropagation/pkg/api/v1alpha1"
+	"configpropagation/pkg/core"
+)
+
+func TestPlanReportsEveryActionWithoutWriting(t *testing.T) {
+	client := newMemoryKubeClient("app-1", "app-2", "gone")
+	client.put("platform", "base", &memoryConfigMap{data: map[string]string{"level": "info", "mode": "fast"}})
+
+	batchSize := int32(1)
+	spec := func(prune bool) *core.ConfigPropagationSpec {
+		return &core.ConfigPropagationSpec{
+			SourceRef:         core.ObjectRef{Namespace: "platform", Name: "base"},
+			NamespaceSelector: &core.LabelSelector{},
+			Strategy:          &core.UpdateStrategy{Type: core.StrategyImmediate},
+			Prune:             &prune,
+		}
+	}
+
+	eventRecorder := &capturingEventRecorder{}
+	reconciler := NewReconciler(client, eventRecorder, nil)
+	key := Key{Namespace: "team-a", Name: "cp"}
+	if _, err := reconciler.Reconcile(context.Background(), key, spec(true)); err != nil {
+		t.Fatalf("reconcile: %v", err)
+	}
+
+	client.configMaps[[2]string{"app-2", "base"}].data = map[string]string{"level": "debug", "mode": "fast"}
+	client.put("app-4", "base", &memoryConfigMap{data: map[string]string{"local": "true"}})
+	client.namespaces = []string{"app-1", "app-2", "app-3", "app-4"}
+
+	snapshot := func() map[[2]string]memoryConfigMap {
+		stored := map[[2]string]memoryConfigMap{}
+		for storedKey, configMap := range client.configMaps {
+			stored[storedKey] = *configMap
+		}
+		return stored
+	}
+	before, eventsBefore := snapshot(), len(eventRecorder.events)
+
+	// A rolling strategy still plans every selected namespace.
+	rolling := spec(true)
+	rolling.Strategy = &core.UpdateStrategy{Type: core.StrategyRolling, BatchSize: &batchSize}
+	plan, err := reconciler.Plan(context.Background(), key, rolling)
+	if err != nil {
+		t.Fatalf("plan: %v", err)
+	}
+
+	want := []core.PlannedTarget{
+		{Namespace: "app-2", Name: "base", Action: core.PlanUpdate, Message: "changes keys level"},
+		{Namespace: "app-3", Name: "base", Action: core.PlanCreate},
+		{Namespace: "app-4", Name: "base", Action: core.PlanUpdate, Message: "overwrites an unmanaged ConfigMap; changes keys level, local, mode"},
+		{Namespace: "gone", Name: "base", Action: core.PlanPrune},
+	}
+	if !reflect.DeepEqual(plan.Targets, want) {
+		t.Fatalf("unexpected planned targets:\n got %+v\nwant %+v", plan.Targets, want)
+	}
+
+	if plan.TargetCount != 4 || plan.Create != 1 || plan.Update != 2 || plan.Prune != 1 || plan.Unchanged != 1 {
+		t.Fatalf("unexpected counts: %+v", plan)
+	}
+
+	if !reflect.DeepEqual(snapshot(), before) || len(client.deletes) != 0 || len(eventRecorder.events) != eventsBefore {
+		t.Fatalf("expected the plan to write nothing and emit no events, got deletes %v and events %+v", client.deletes, eventRecorder.events[eventsBefore:])
+	}
+
+	if _, tracked := reconciler.rolloutPlanner.TrackedHash(key.namespacedName()); tracked {
+		t.Fatalf("expected the plan to leave rollout progress alone")
+	}
+
+	detaching, err := reconciler.Plan(context.Background(), key, spec(false))
+	if err != nil {
+		t.Fatalf("plan without prune: %v", err)
+	}
+
+	if last := detaching.Targets[len(detaching.Targets)-1]; last.Namespace != "gone" || last.Action != core.PlanDetach || detaching.Detach != 1 {
+		t.Fatalf("expected the deselected target to be detached, got %+v", detaching.Targets)
+	}
+}
+
+func TestDryRunConfigPropagationRecordsPlanInStatus(t *testing.T) {
+	configPropagation := &configv1alpha1.ConfigPropagation{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "team-a", Name: "cp", Finalizers: []string{core.Finalizer}},
+		Spec: core.ConfigPropagationSpec{
+			SourceRef:         core.ObjectRef{Namespace: "platform", Name: "base"},
+			NamespaceSelector: &core.LabelSelector{MatchLabels: map[string]string{"team": "a"}},
+			DryRun:            true,
+		},
+	}
+
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatalf("add core scheme: %v", err)
+	}
+
+	if err := configv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatalf("add configpropagation scheme: %v", err)
+	}
+
+	builder := fake.NewClientBuilder().WithScheme(scheme).WithStatusSubresource(configPropagation).WithObjects(
+		configPropagation,
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "platform", Name: "base"}, Data: map[string]string{"level": "info"}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "app-1", Labels: map[string]string{"team": "a"}}},
+	)
+	if err := RegisterIndexes(context.Background(), builderIndexer{builder: builder}); err != nil {
+		t.Fatalf("register indexes: %v", err)
+	}
+
+	kubeClient := builder.Build()
+	controller := &ConfigPropagationController{
+		Client:     kubeClient,
+		logger:     logr.Discard(),
+		reconciler: NewReconciler(adapters.NewControllerRuntimeClient(kubeClient, adapters.ClientOptions{}), nil, nil),
+	}
+
+	request := reconcile.Request{NamespacedName: client.ObjectKeyFromObject(configPropagation)}
+	reconcileAndGet := func() *configv1alpha1.ConfigPropagation {
+		t.Helper()
+
+		if _, err := controller.Reconcile(context.Background(), request); err != nil {
+			t.Fatalf("reconcile: %v", err)
+		}
+
+		var stored configv1alpha1.ConfigPropagation
+		if err := kubeClient.Get(context.Background(), request.NamespacedName, &stored); err != nil {
+			t.Fatalf("get ConfigPropagation: %v", err)
+		}
+		return &stored
+	}
+
+	planned := reconcileAndGet()
+	if plan := planned.Status.Plan; plan == nil || plan.Create != 1 || len(plan.Targets) != 1 || plan.Targets[0].Namespace != "app-1" {
+		t.Fatalf("expected a plan creating app-1/base, got %+v", planned.Status.Plan)
+	}
+
+	if ready := planned.Status.Conditions[0]; ready.Status != "False" || ready.Reason != "DryRun" {
+		t.Fatalf("expected Ready to report the dry run, got %+v", ready)
+	}
+
+	var target corev1.ConfigMap
+	if err := kubeClient.Get(context.Background(), client.ObjectKey{Namespace: "app-1", Name: "base"}, &target); err == nil {
+		t.Fatalf("expected a dry run not to write the target")
+	}
+
+	planned.Spec.DryRun = false
+	if err := kubeClient.Update(context.Background(), planned); err != nil {
+		t.Fatalf("disable dry run: %v", err)
+	}
+
+	applied := reconcileAndGet()
+	if applied.Status.Plan != nil || applied.Status.Conditions[0].Reason != "Reconciled" {
+		t.Fatalf("expected the plan to be replaced by a regular status, got %+v", applied.Status)
+	}
+
+	if err := kubeClient.Get(context.Background(), client.ObjectKey{Namespace: "app-1", Name: "base"}, &target); err != nil {
+		t.Fatalf("expected the target once dry run is off: %v", err)
+	}
+}
+
+func TestPlanNextBatchResumesPersistedRollout(t *testing.T) {
+	client := newMemoryKubeClient("app-1", "app-2", "app-3")
+	client.put("platform", "base", &memoryConfigMap{data: map[string]string{"level": "info"}})
+
+	batchSize := int32(1)
+	spec := func() *core.ConfigPropagationSpec {
+		return &core.ConfigPropagationSpec{
+			SourceRef:         core.ObjectRef{Namespace: "platform", Name: "base"},
+			NamespaceSelector: &core.LabelSelector{},
+			Strategy:          &core.UpdateStrategy{Type: core.StrategyRolling, BatchSize: &batchSize},
+		}
+	}
+
+	key := Key{Namespace: "team-a", Name: "cp"}
+	result, err := NewReconciler(client, nil, nil).Reconcile(context.Background(), key, spec())
+	if err != nil {
+		t.Fatalf("reconcile: %v", err)
+	}
+
+	// A fresh reconciler, as in a CLI, continues from the progress persisted in status.
+	plan, err := NewReconciler(client, nil, nil).PlanNextBatch(context.Background(), key, spec(), result.Rollout)
+	if err != nil {
+		t.Fatalf("plan next batch: %v", err)
+	}
+
+	want := []core.PlannedTarget{{Namespace: "app-2", Name: "base", Action: core.PlanCreate}}
+	if !reflect.DeepEqual(plan.Targets, want) || plan.Unchanged != 1 || plan.Pending != 1 {
+		t.Fatalf("expected only the next batch to be planned, got %+v", plan)
+	}
+}
+
+func TestDiffComparesPropagatedDataWithEveryTarget(t *testing.T) {
+	client := newMemoryKubeClient("app-1", "app-2")
+	client.put("platform", "base", &memoryConfigMap{data: map[string]string{"level": "info", "owner": "{{ .Namespace }}"}})
+
+	spec := func() *core.ConfigPropagationSpec {
+		return &core.ConfigPropagationSpec{
+			SourceRef:         core.ObjectRef{Namespace: "platform", Name: "base"},
+			NamespaceSelector: &core.LabelSelector{},
+			Template:          &core.TemplateSpec{Enabled: true},
+		}
+	}
+
+	key := Key{Namespace: "team-a", Name: "cp"}
+	reconciler := NewReconciler(client, nil, nil)
+	if _, err := reconciler.Reconcile(context.Background(), key, spec()); err != nil {
+		t.Fatalf("reconcile: %v", err)
+	}
+
+	client.configMaps[[2]string{"app-1", "base"}].data = map[string]string{"level": "debug", "owner": "app-1"}
+	delete(client.configMaps, [2]string{"app-2", "base"})
+
+	differences, err := reconciler.Diff(context.Background(), key, spec())
+	if err != nil {
+		t.Fatalf("diff: %v", err)
+	}
+
+	want := []TargetDiff{
+		{Namespace: "app-1", Name: "base", Found: true, Keys: []core.KeyDiff{{Key: "level", Change: core.DiffChanged, Desired: "info", Live: "debug"}}},
+		{Namespace: "app-2", Name: "base", Keys: []core.KeyDiff{
+			{Key: "level", Change: core.DiffMissing, Desired: "info"},
+			{Key: "owner", Change: core.DiffMissing, Desired: "app-2"},
+		}},
+	}
+	if !reflect.DeepEqual(differences, want) {
+		t.Fatalf("unexpected differences:\n got %+v\nwant %+v", differences, want)
+	}
+}
@@ -0,0 +1,139 @@
+package configpropagation
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"configpropagation/pkg/adapters"
+	configv1alpha1 "configpropagation/pkg/api/v1alpha1"
+	"configpropagation/pkg/core"
+)
+
+// builderIndexer installs field indexes on a fake client builder, so tests register the same indexes as the manager.
+type builderIndexer struct {
+	builder *fake.ClientBuilder
+}
+
+func (indexer builderIndexer) IndexField(_ context.Context, object client.Object, field string, extractValue client.IndexerFunc) error {
+	indexer.builder.WithIndex(object, field, extractValue)
+	return nil
+}
+
+func TestPolicyChangeHaltsAndResumesExistingConfigPropagation(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatalf("add core scheme: %v", err)
+	}
+
+	if err := configv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatalf("add configpropagation scheme: %v", err)
+	}
+
+	configPropagation := &configv1alpha1.ConfigPropagation{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "team-a", Name: "cp"},
+		Spec: core.ConfigPropagationSpec{
+			SourceRef:         core.ObjectRef{Namespace: "platform", Name: "base"},
+			NamespaceSelector: &core.LabelSelector{MatchLabels: map[string]string{"team": "a"}},
+		},
+	}
+
+	objects := []client.Object{
+		configPropagation,
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "platform", Name: "base"}, Data: map[string]string{"level": "info"}},
+	}
+	for _, namespace := range []string{"app-1", "app-2", "app-3"} {
+		objects = append(objects, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: namespace, Labels: map[string]string{"team": "a"}}})
+	}
+
+	builder := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).WithStatusSubresource(configPropagation)
+	if err := RegisterIndexes(context.Background(), builderIndexer{builder: builder}); err != nil {
+		t.Fatalf("register indexes: %v", err)
+	}
+
+	kubeClient := builder.Build()
+	eventRecorder := &capturingEventRecorder{}
+	controller := &ConfigPropagationController{
+		Client:     kubeClient,
+		logger:     logr.Discard(),
+		reconciler: NewReconciler(adapters.NewControllerRuntimeClient(kubeClient, adapters.ClientOptions{}), eventRecorder, nil),
+	}
+
+	request := reconcile.Request{NamespacedName: client.ObjectKeyFromObject(configPropagation)}
+	reconcileAndReadTarget := func() (reconcile.Result, string) {
+		t.Helper()
+
+		result, err := controller.Reconcile(context.Background(), request)
+		if err != nil {
+			t.Fatalf("reconcile: %v", err)
+		}
+
+		var target corev1.ConfigMap
+		if err := kubeClient.Get(context.Background(), client.ObjectKey{Namespace: "app-3", Name: "base"}, &target); err != nil {
+			t.Fatalf("get target: %v", err)
+		}
+
+		return result, target.Data["level"]
+	}
+
+	if _, level := reconcileAndReadTarget(); level != "info" {
+		t.Fatalf("expected the target to be created, got level %q", level)
+	}
+
+	maxTargets := int32(2)
+	policy := &configv1alpha1.ConfigPropagationPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "small-blast-radius"},
+		Spec:       configv1alpha1.ConfigPropagationPolicySpec{MaxTargets: &maxTargets},
+	}
+	if err := kubeClient.Create(context.Background(), policy); err != nil {
+		t.Fatalf("create policy: %v", err)
+	}
+
+	if requests := controller.requestsForPolicy(context.Background(), policy); len(requests) != 1 || requests[0] != request {
+		t.Fatalf("expected the policy change to enqueue %s, got %+v", request, requests)
+	}
+
+	var source corev1.ConfigMap
+	if err := kubeClient.Get(context.Background(), client.ObjectKey{Namespace: "platform", Name: "base"}, &source); err != nil {
+		t.Fatalf("get source: %v", err)
+	}
+	source.Data["level"] = "debug"
+	if err := kubeClient.Update(context.Background(), &source); err != nil {
+		t.Fatalf("update source: %v", err)
+	}
+
+	result, level := reconcileAndReadTarget()
+	if level != "info" || result.Requeue || result.RequeueAfter != 0 {
+		t.Fatalf("expected the violating CR to be halted without requeue, got level %q result %+v", level, result)
+	}
+
+	var halted configv1alpha1.ConfigPropagation
+	if err := kubeClient.Get(context.Background(), request.NamespacedName, &halted); err != nil {
+		t.Fatalf("get ConfigPropagation: %v", err)
+	}
+
+	ready := halted.Status.Conditions[0]
+	if ready.Type != core.CondReady || ready.Reason != "PolicyViolation" || ready.Message != "violates ConfigPropagationPolicy small-blast-radius: selects 3 namespaces, more than maxTargets 2" {
+		t.Fatalf("expected Ready to report the violation, got %+v", ready)
+	}
+
+	if !hasEvent(eventRecorder, eventReasonPolicyViolation, "Warning") {
+		t.Fatalf("expected a %s event, got %+v", eventReasonPolicyViolation, eventRecorder.events)
+	}
+
+	if err := kubeClient.Delete(context.Background(), policy); err != nil {
+		t.Fatalf("delete policy: %v", err)
+	}
+
+	if _, level := reconcileAndReadTarget(); level != "debug" {
+		t.Fatalf("expected the CR to resume once the policy is gone, got level %q", level)
+	}
+}
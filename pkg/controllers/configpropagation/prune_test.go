@@ -3,6 +3,7 @@ package configpropagation
 import (
 	"configpropagation/pkg/adapters"
 	core "configpropagation/pkg/core"
+	"context"
 	"reflect"
 	"testing"
 )
@@ -25,16 +26,19 @@ type fakePruneClient struct {
 	detached []detachRecord
 }
 
-func (f *fakePruneClient) GetSourceConfigMap(ns, name string) (map[string]string, error) {
-	return map[string]string{"k": "v"}, nil
+func (f *fakePruneClient) GetSourceConfigMap(_ context.Context, ns, name string) (map[string]string, map[string][]byte, error) {
+	return map[string]string{"k": "v"}, nil, nil
 }
-func (f *fakePruneClient) ListNamespacesBySelector(_ map[string]string, _ []adapters.LabelSelectorRequirement) ([]string, error) {
+func (f *fakePruneClient) ListNamespacesBySelector(_ context.Context, _ map[string]string, _ []adapters.LabelSelectorRequirement) ([]string, error) {
 	return nil, nil
 }
-func (f *fakePruneClient) UpsertConfigMap(ns, name string, data map[string]string, labels, annotations map[string]string) error {
+func (f *fakePruneClient) GetNamespaceMetadata(_ context.Context, name string) (map[string]string, map[string]string, error) {
+	return nil, nil, nil
+}
+func (f *fakePruneClient) UpsertConfigMap(_ context.Context, ns, name string, data map[string]string, binaryData map[string][]byte, labels, annotations map[string]string) error {
 	return nil
 }
-func (f *fakePruneClient) GetTargetConfigMap(namespace, name string) (map[string]string, map[string]string, map[string]string, bool, error) {
+func (f *fakePruneClient) GetTargetConfigMap(_ context.Context, namespace, name string) (map[string]string, map[string][]byte, map[string]string, map[string]string, bool, error) {
 	labels := map[string]string{}
 	annotations := map[string]string{}
 
@@ -51,19 +55,19 @@ func (f *fakePruneClient) GetTargetConfigMap(namespace, name string) (map[string
 	}
 
 	if len(labels) == 0 && len(annotations) == 0 {
-		return nil, nil, nil, false, nil
+		return nil, nil, nil, nil, false, nil
 	}
 
-	return nil, labels, annotations, true, nil
+	return nil, nil, labels, annotations, true, nil
 }
-func (f *fakePruneClient) ListManagedTargetNamespaces(source string, name string) ([]string, error) {
-	return append([]string(nil), f.managed...), nil
+func (f *fakePruneClient) ListManagedTargets(_ context.Context, source, owner string) ([]adapters.ManagedTarget, error) {
+	return legacyTargets(source, f.managed), nil
 }
-func (f *fakePruneClient) DeleteConfigMap(namespace, name string) error {
+func (f *fakePruneClient) DeleteConfigMap(_ context.Context, namespace, name string) error {
 	f.deleted = append(f.deleted, [2]string{namespace, name})
 	return nil
 }
-func (f *fakePruneClient) UpdateConfigMapMetadata(namespace, name string, labels, annotations map[string]string) error {
+func (f *fakePruneClient) UpdateConfigMapMetadata(_ context.Context, namespace, name string, labels, annotations map[string]string) error {
 	copiedLabels := map[string]string{}
 	for key, value := range labels {
 		copiedLabels[key] = value
@@ -82,7 +86,7 @@ func TestCleanupDeselectedPruneDeletes(t *testing.T) {
 	fc := &fakePruneClient{managed: []string{"a", "b"}}
 	s := &core.ConfigPropagationSpec{SourceRef: core.ObjectRef{Namespace: "s", Name: "n"}, NamespaceSelector: &core.LabelSelector{}, Prune: boolPtr(true)}
 	r := NewReconciler(fc, nil, nil)
-	if err := r.cleanupDeselected(Key{Namespace: "default", Name: "cp"}, s, []string{"a"}); err != nil {
+	if _, err := r.cleanupDeselected(context.Background(), Key{Namespace: "default", Name: "cp"}, s, mustTargetNamer(t, s), []string{"a"}, nil); err != nil {
 		t.Fatalf("cleanup error: %v", err)
 	}
 	if len(fc.deleted) != 1 || fc.deleted[0] != [2]string{"b", "n"} {
@@ -105,7 +109,7 @@ func TestCleanupDeselectedDetachWhenPruneFalse(t *testing.T) {
 	}
 	s := &core.ConfigPropagationSpec{SourceRef: core.ObjectRef{Namespace: "s", Name: "n"}, NamespaceSelector: &core.LabelSelector{}, Prune: boolPtr(false)}
 	r := NewReconciler(fc, nil, nil)
-	if err := r.cleanupDeselected(Key{Namespace: "default", Name: "cp"}, s, []string{"a"}); err != nil {
+	if _, err := r.cleanupDeselected(context.Background(), Key{Namespace: "default", Name: "cp"}, s, mustTargetNamer(t, s), []string{"a"}, nil); err != nil {
 		t.Fatalf("cleanup error: %v", err)
 	}
 	if len(fc.detached) != 1 {
@@ -130,7 +134,7 @@ func TestCleanupDeselectedDetachSkipsWhenTargetMissing(t *testing.T) {
 	fc := &fakePruneClient{managed: []string{"a"}}
 	s := &core.ConfigPropagationSpec{SourceRef: core.ObjectRef{Namespace: "s", Name: "n"}, NamespaceSelector: &core.LabelSelector{}, Prune: boolPtr(false)}
 	r := NewReconciler(fc, nil, nil)
-	if err := r.cleanupDeselected(Key{Namespace: "default", Name: "cp"}, s, []string{}); err != nil {
+	if _, err := r.cleanupDeselected(context.Background(), Key{Namespace: "default", Name: "cp"}, s, mustTargetNamer(t, s), []string{}, nil); err != nil {
 		t.Fatalf("cleanup error: %v", err)
 	}
 	if len(fc.detached) != 0 {
@@ -142,7 +146,7 @@ func TestFinalizeCleansAll(t *testing.T) {
 	fc := &fakePruneClient{managed: []string{"a", "b"}}
 	r := NewReconciler(fc, nil, nil)
 	s := &core.ConfigPropagationSpec{SourceRef: core.ObjectRef{Namespace: "s", Name: "n"}, NamespaceSelector: &core.LabelSelector{}, Prune: boolPtr(true)}
-	if err := r.Finalize(Key{Namespace: "default", Name: "cp"}, s); err != nil {
+	if err := r.Finalize(context.Background(), Key{Namespace: "default", Name: "cp"}, s); err != nil {
 		t.Fatalf("finalize error: %v", err)
 	}
 	want := [][2]string{{"a", "n"}, {"b", "n"}}
@@ -155,7 +159,7 @@ func TestCleanupDeselectedNoopsWhenNoneManaged(t *testing.T) {
 	fc := &fakePruneClient{managed: []string{}}
 	s := &core.ConfigPropagationSpec{SourceRef: core.ObjectRef{Namespace: "s", Name: "n"}, NamespaceSelector: &core.LabelSelector{}, Prune: boolPtr(true)}
 	r := NewReconciler(fc, nil, nil)
-	if err := r.cleanupDeselected(Key{Namespace: "default", Name: "cp"}, s, []string{"a"}); err != nil {
+	if _, err := r.cleanupDeselected(context.Background(), Key{Namespace: "default", Name: "cp"}, s, mustTargetNamer(t, s), []string{"a"}, nil); err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
 	if len(fc.deleted) != 0 || len(fc.detached) != 0 {
@@ -164,3 +168,68 @@ func TestCleanupDeselectedNoopsWhenNoneManaged(t *testing.T) {
 }
 
 func boolPtr(b bool) *bool { return &b }
+
+func TestFinalizeSparesTargetsOfAnotherUID(t *testing.T) {
+	client := newMemoryKubeClient("team-a", "team-b")
+	client.put("platform", "app", &memoryConfigMap{data: map[string]string{"log": "info"}})
+	spec := &core.ConfigPropagationSpec{
+		SourceRef:         core.ObjectRef{Namespace: "platform", Name: "app"},
+		NamespaceSelector: &core.LabelSelector{},
+		Strategy:          &core.UpdateStrategy{Type: core.StrategyImmediate},
+	}
+
+	predecessor := Key{Namespace: "default", Name: "cp", UID: "uid-1"}
+	if _, err := NewReconciler(client, nil, nil).Reconcile(context.Background(), predecessor, spec); err != nil {
+		t.Fatalf("reconcile: %v", err)
+	}
+
+	if uid := client.configMaps[[2]string{"team-a", "app"}].annotations[core.OwnerUIDAnnotation]; uid != "uid-1" {
+		t.Fatalf("expected the owner UID to be recorded, got %q", uid)
+	}
+
+	// A CR recreated under the same name does not own, and so does not prune, its predecessor's copies.
+	if err := NewReconciler(client, nil, nil).Finalize(context.Background(), Key{Namespace: "default", Name: "cp", UID: "uid-2"}, spec); err != nil {
+		t.Fatalf("finalize successor: %v", err)
+	}
+	if len(client.deletes) != 0 {
+		t.Fatalf("expected no deletes, got %v", client.deletes)
+	}
+
+	if err := NewReconciler(client, nil, nil).Finalize(context.Background(), predecessor, spec); err != nil {
+		t.Fatalf("finalize owner: %v", err)
+	}
+	if len(client.deletes) != 2 {
+		t.Fatalf("expected the owner to prune both copies, got %v", client.deletes)
+	}
+}
+
+func TestSourceRefChangePrunesTargetsOfThePreviousSource(t *testing.T) {
+	client := newMemoryKubeClient("team-a")
+	client.put("platform", "app", &memoryConfigMap{data: map[string]string{"log": "info"}})
+	client.put("platform", "app-v2", &memoryConfigMap{data: map[string]string{"log": "debug"}})
+	reconciler := NewReconciler(client, nil, nil)
+	key := Key{Namespace: "default", Name: "cp"}
+
+	spec := &core.ConfigPropagationSpec{
+		SourceRef:         core.ObjectRef{Namespace: "platform", Name: "app"},
+		NamespaceSelector: &core.LabelSelector{},
+		Strategy:          &core.UpdateStrategy{Type: core.StrategyImmediate},
+	}
+	if _, err := reconciler.Reconcile(context.Background(), key, spec); err != nil {
+		t.Fatalf("reconcile: %v", err)
+	}
+
+	written := client.configMaps[[2]string{"team-a", "app"}].labels
+	if written[core.SourceHashLabel] != core.SourceLabelValue("platform/app") || written[core.OwnerHashLabel] != core.SourceLabelValue("default/cp") {
+		t.Fatalf("expected the hashed source and owner labels, got %+v", written)
+	}
+
+	spec.SourceRef.Name = "app-v2"
+	if _, err := reconciler.Reconcile(context.Background(), key, spec); err != nil {
+		t.Fatalf("reconcile new source: %v", err)
+	}
+
+	if !reflect.DeepEqual(client.names(), []string{"platform/app", "platform/app-v2", "team-a/app-v2"}) {
+		t.Fatalf("expected the copy of the previous source to be pruned, got %v", client.names())
+	}
+}
@@ -1,8 +1,11 @@
 package configpropagation
 
 import (
+	"context"
 	"fmt"
 	"reflect"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -10,12 +13,37 @@ import (
 	"configpropagation/pkg/core"
 )
 
+// legacyTargets describes managed targets named after the source and written before owners were recorded.
+func legacyTargets(source string, namespaces []string) []adapters.ManagedTarget {
+	sourceName := source[strings.LastIndex(source, "/")+1:]
+
+	targets := make([]adapters.ManagedTarget, 0, len(namespaces))
+	for _, namespace := range namespaces {
+		targets = append(targets, adapters.ManagedTarget{Namespace: namespace, Name: sourceName})
+	}
+
+	return targets
+}
+
+// mustTargetNamer builds the target namer for a spec or fails the test.
+func mustTargetNamer(t *testing.T, spec *core.ConfigPropagationSpec) *core.TargetNamer {
+	t.Helper()
+
+	targetNamer, err := core.NewTargetNamer(spec)
+	if err != nil {
+		t.Fatalf("target namer: %v", err)
+	}
+
+	return targetNamer
+}
+
 type fakeClient struct {
 	data       map[string]map[string]map[string]string
 	namespaces []string
+	targets    map[string]map[string]string // written target data by namespace
 }
 
-func (client *fakeClient) GetSourceConfigMap(namespace, name string) (map[string]string, error) {
+func (client *fakeClient) GetSourceConfigMap(_ context.Context, namespace, name string) (map[string]string, map[string][]byte, error) {
 	if namespaceData, exists := client.data[namespace]; exists {
 		if configMapData, exists := namespaceData[name]; exists {
 			copiedData := map[string]string{}
@@ -24,32 +52,46 @@ func (client *fakeClient) GetSourceConfigMap(namespace, name string) (map[string
 				copiedData[key] = value
 			}
 
-			return copiedData, nil
+			return copiedData, nil, nil
 		}
 	}
 
-	return nil, nil
+	return nil, nil, nil
 }
 
-func (client *fakeClient) ListNamespacesBySelector(_ map[string]string, _ []adapters.LabelSelectorRequirement) ([]string, error) {
+func (client *fakeClient) ListNamespacesBySelector(_ context.Context, _ map[string]string, _ []adapters.LabelSelectorRequirement) ([]string, error) {
 	return append([]string(nil), client.namespaces...), nil
 }
 
-func (client *fakeClient) UpsertConfigMap(_ string, _ string, _ map[string]string, _ map[string]string, _ map[string]string) error {
+func (client *fakeClient) GetNamespaceMetadata(_ context.Context, name string) (map[string]string, map[string]string, error) {
+	return nil, nil, nil
+}
+
+func (client *fakeClient) UpsertConfigMap(_ context.Context, namespace string, _ string, data map[string]string, _ map[string][]byte, _ map[string]string, _ map[string]string) error {
+	if client.targets == nil {
+		client.targets = map[string]map[string]string{}
+	}
+	client.targets[namespace] = data
 	return nil
 }
 
-func (client *fakeClient) GetTargetConfigMap(namespace, name string) (map[string]string, map[string]string, map[string]string, bool, error) {
-	return nil, nil, nil, false, nil
+func (client *fakeClient) GetTargetConfigMap(_ context.Context, namespace, name string) (map[string]string, map[string][]byte, map[string]string, map[string]string, bool, error) {
+	data, found := client.targets[namespace]
+	if !found {
+		return nil, nil, nil, nil, false, nil
+	}
+	return data, nil, map[string]string{core.ManagedLabel: "true"}, map[string]string{core.HashAnnotation: core.HashData(data, nil)}, true, nil
 }
 
-func (client *fakeClient) ListManagedTargetNamespaces(source string, name string) ([]string, error) {
-	return []string{}, nil
+func (client *fakeClient) ListManagedTargets(_ context.Context, source, owner string) ([]adapters.ManagedTarget, error) {
+	return nil, nil
 }
 
-func (client *fakeClient) DeleteConfigMap(namespace, name string) error { return nil }
+func (client *fakeClient) DeleteConfigMap(_ context.Context, namespace, name string) error {
+	return nil
+}
 
-func (client *fakeClient) UpdateConfigMapMetadata(namespace, name string, labels, annotations map[string]string) error {
+func (client *fakeClient) UpdateConfigMapMetadata(_ context.Context, namespace, name string, labels, annotations map[string]string) error {
 	return nil
 }
 
@@ -66,7 +108,7 @@ func TestReconcilerPlanImmediate(t *testing.T) {
 		Strategy:          &core.UpdateStrategy{Type: core.StrategyImmediate},
 		DataKeys:          []string{"a", "c"},
 	}
-	result, err := reconciler.Reconcile(key, spec)
+	result, err := reconciler.Reconcile(context.Background(), key, spec)
 	if err != nil {
 		t.Fatalf("reconcile error: %v", err)
 	}
@@ -92,7 +134,7 @@ func TestReconcilerPlanRollingBatch(t *testing.T) {
 		NamespaceSelector: &core.LabelSelector{},
 		Strategy:          &core.UpdateStrategy{Type: core.StrategyRolling, BatchSize: &batchSize},
 	}
-	result, err := reconciler.Reconcile(key, spec)
+	result, err := reconciler.Reconcile(context.Background(), key, spec)
 	if err != nil {
 		t.Fatalf("reconcile error: %v", err)
 	}
@@ -105,7 +147,7 @@ func TestReconcilerPlanRollingBatch(t *testing.T) {
 	}
 
 	// next reconcile should continue with remaining namespaces
-	nextResult, err := reconciler.Reconcile(key, spec)
+	nextResult, err := reconciler.Reconcile(context.Background(), key, spec)
 	if err != nil {
 		t.Fatalf("second reconcile error: %v", err)
 	}
@@ -150,57 +192,66 @@ func TestPlanTargetsBranches(t *testing.T) {
 
 func TestHelpersComputeEffectiveAndListTargetsAndSyncTargets(t *testing.T) {
 	// computeEffective with nil src and keys -> returns empty map
-	effectiveData := computeEffective(nil, nil)
-	if len(effectiveData) != 0 {
+	allKeys, _ := core.NewKeySelector(nil, nil)
+	effectiveData, effectiveBinaryData, _, err := computeEffective(nil, nil, allKeys, nil)
+	if err != nil || len(effectiveData) != 0 || len(effectiveBinaryData) != 0 {
 		t.Fatalf("expected empty effective for nil src")
 	}
 	// computeEffective copy-all path
-	effectiveData = computeEffective(map[string]string{"a": "1"}, nil)
-	if !reflect.DeepEqual(effectiveData, map[string]string{"a": "1"}) {
-		t.Fatalf("copy-all failed: %+v", effectiveData)
+	effectiveData, effectiveBinaryData, _, err = computeEffective(map[string]string{"a": "1"}, map[string][]byte{"b": {0x01}}, allKeys, nil)
+	if err != nil || !reflect.DeepEqual(effectiveData, map[string]string{"a": "1"}) || !reflect.DeepEqual(effectiveBinaryData, map[string][]byte{"b": {0x01}}) {
+		t.Fatalf("copy-all failed: %+v %+v", effectiveData, effectiveBinaryData)
+	}
+	// computeEffective applies dataKeys to binaryData too
+	selectedKeys, _ := core.NewKeySelector([]string{"a", "b"}, nil)
+	effectiveData, effectiveBinaryData, _, err = computeEffective(map[string]string{"a": "1", "c": "3"}, map[string][]byte{"b": {0x01}, "d": {0x02}}, selectedKeys, nil)
+	if err != nil || !reflect.DeepEqual(effectiveData, map[string]string{"a": "1"}) || !reflect.DeepEqual(effectiveBinaryData, map[string][]byte{"b": {0x01}}) {
+		t.Fatalf("dataKeys filter failed: %+v %+v", effectiveData, effectiveBinaryData)
 	}
 	// listTargets exercises adapter translation and nilIfEmpty
 	fakeKubeClient := &fakeClient{data: map[string]map[string]map[string]string{}, namespaces: []string{"x"}}
 	selector := &core.LabelSelector{MatchLabels: map[string]string{}, MatchExpressions: []core.LabelSelectorReq{{Key: "k", Operator: "Exists"}}}
-	namespaces, err := listTargets(fakeKubeClient, selector)
+	namespaces, err := listTargets(context.Background(), fakeKubeClient, selector)
 	if err != nil || !reflect.DeepEqual(namespaces, []string{"x"}) {
 		t.Fatalf("listTargets failed: %v %v", namespaces, err)
 	}
 	// syncTargets executes loop and returns nil
-	hashValue := core.HashData(map[string]string{"k": "v"})
+	hashValue := core.HashData(map[string]string{"k": "v"}, nil)
 	reconciler := NewReconciler(fakeKubeClient, nil, nil)
-	summary, err := reconciler.syncTargets(Key{Namespace: "default", Name: "cp"}, []string{"ns"}, "name", map[string]string{"k": "v"}, hashValue, "src", core.ConflictOverwrite)
-	if err != nil {
-		t.Fatalf("syncTargets error: %v", err)
-	}
+	summary := reconciler.syncTargets(context.Background(), Key{Namespace: "default", Name: "cp"}, []string{"ns"}, map[string]string{"ns": "name"}, desiredContent{data: map[string]string{"k": "v"}, hash: hashValue}, core.ObjectRef{Namespace: "src", Name: "name"}, core.ConflictOverwrite)
 	if len(summary.completed) != 1 || len(summary.outOfSync) != 0 {
 		t.Fatalf("unexpected sync summary: %+v", summary)
 	}
-	// syncTargets error path
-	failingUpsertClient := &badUpsert{*fakeKubeClient}
+	// syncTargets error path reports the namespace instead of aborting
+	failingUpsertClient := &badUpsert{fakeClient{data: fakeKubeClient.data, namespaces: fakeKubeClient.namespaces}}
 	failingReconciler := NewReconciler(failingUpsertClient, nil, nil)
-	if _, err := failingReconciler.syncTargets(Key{Namespace: "default", Name: "cp"}, []string{"ns"}, "name", map[string]string{"k": "v"}, hashValue, "src", core.ConflictOverwrite); err == nil {
-		t.Fatalf("expected syncTargets to error on upsert")
+	failedSummary := failingReconciler.syncTargets(context.Background(), Key{Namespace: "default", Name: "cp"}, []string{"ns"}, map[string]string{"ns": "name"}, desiredContent{data: map[string]string{"k": "v"}, hash: hashValue}, core.ObjectRef{Namespace: "src", Name: "name"}, core.ConflictOverwrite)
+	if len(failedSummary.completed) != 0 || len(failedSummary.outOfSync) != 1 || failedSummary.outOfSync[0].Reason != core.ReasonTransientError {
+		t.Fatalf("expected upsert failure to be reported per namespace, got %+v", failedSummary)
 	}
 }
 
 type errClient struct{ fakeClient }
 
-func (client *errClient) GetSourceConfigMap(namespace, name string) (map[string]string, error) {
-	return nil, fmt.Errorf("boom")
+func (client *errClient) GetSourceConfigMap(_ context.Context, namespace, name string) (map[string]string, map[string][]byte, error) {
+	return nil, nil, fmt.Errorf("boom")
 }
 
-func (client *errClient) UpsertConfigMap(namespace, name string, data map[string]string, labels, annotations map[string]string) error {
+func (client *errClient) UpsertConfigMap(_ context.Context, namespace, name string, data map[string]string, binaryData map[string][]byte, labels, annotations map[string]string) error {
 	return fmt.Errorf("fail")
 }
 
-func (client *errClient) ListNamespacesBySelector(_ map[string]string, _ []adapters.LabelSelectorRequirement) ([]string, error) {
+func (client *errClient) ListNamespacesBySelector(_ context.Context, _ map[string]string, _ []adapters.LabelSelectorRequirement) ([]string, error) {
 	return nil, fmt.Errorf("nslist")
 }
 
+func (client *errClient) GetNamespaceMetadata(_ context.Context, name string) (map[string]string, map[string]string, error) {
+	return nil, nil, nil
+}
+
 type badUpsert struct{ fakeClient }
 
-func (client *badUpsert) UpsertConfigMap(namespace, name string, data map[string]string, labels, annotations map[string]string) error {
+func (client *badUpsert) UpsertConfigMap(_ context.Context, namespace, name string, data map[string]string, binaryData map[string][]byte, labels, annotations map[string]string) error {
 	return fmt.Errorf("nope")
 }
 
@@ -208,28 +259,32 @@ func TestReconcileErrorPaths(t *testing.T) {
 	reconciler := NewReconciler(&errClient{fakeClient{data: map[string]map[string]map[string]string{}, namespaces: []string{"n"}}}, nil, nil)
 	spec := &core.ConfigPropagationSpec{SourceRef: core.ObjectRef{Namespace: "s", Name: "n"}, NamespaceSelector: &core.LabelSelector{}}
 
-	if _, err := reconciler.Reconcile(Key{Namespace: "ns", Name: "cp"}, spec); err == nil {
+	if _, err := reconciler.Reconcile(context.Background(), Key{Namespace: "ns", Name: "cp"}, spec); err == nil {
 		t.Fatalf("expected error from source get")
 	}
 
 	// Source ok, upsert fails
-	failingUpsertErrClient := &errClient{fakeClient{data: map[string]map[string]map[string]string{"s": {"n": {}}}, namespaces: []string{"n"}}}
-	reconcilerWithUpsertError := NewReconciler(failingUpsertErrClient, nil, nil)
+	failingUpsertClient := &badUpsert{fakeClient{data: map[string]map[string]map[string]string{"s": {"n": {"k": "v"}}}, namespaces: []string{"n"}}}
+	reconcilerWithUpsertError := NewReconciler(failingUpsertClient, nil, nil)
 
-	if _, err := reconcilerWithUpsertError.Reconcile(Key{Namespace: "ns", Name: "cp"}, spec); err == nil {
-		t.Fatalf("expected upsert error")
+	upsertResult, err := reconcilerWithUpsertError.Reconcile(context.Background(), Key{Namespace: "ns", Name: "cp"}, spec)
+	if err != nil {
+		t.Fatalf("expected upsert failure to be isolated, got %v", err)
+	}
+	if len(upsertResult.OutOfSync) != 1 || upsertResult.OutOfSync[0].Reason != core.ReasonTransientError {
+		t.Fatalf("expected upsert failure in outOfSync, got %+v", upsertResult.OutOfSync)
 	}
 
 	// Namespace list fails
 	failingListErrClient := &errClient{fakeClient{data: map[string]map[string]map[string]string{"s": {"n": {}}}, namespaces: []string{"n"}}}
 	reconcilerWithListError := NewReconciler(failingListErrClient, nil, nil)
 
-	if _, err := reconcilerWithListError.Reconcile(Key{Namespace: "ns", Name: "cp"}, spec); err == nil {
+	if _, err := reconcilerWithListError.Reconcile(context.Background(), Key{Namespace: "ns", Name: "cp"}, spec); err == nil {
 		t.Fatalf("expected list namespaces error")
 	}
 
 	// Nil spec
-	if _, err := reconcilerWithUpsertError.Reconcile(Key{Namespace: "ns", Name: "cp"}, nil); err == nil {
+	if _, err := reconcilerWithUpsertError.Reconcile(context.Background(), Key{Namespace: "ns", Name: "cp"}, nil); err == nil {
 		t.Fatalf("expected error for nil spec")
 	}
 }
@@ -239,7 +294,7 @@ func TestReconcileValidationFailure(t *testing.T) {
 	// Invalid: strategy type unrecognized
 	spec := &core.ConfigPropagationSpec{SourceRef: core.ObjectRef{Namespace: "s", Name: "n"}, NamespaceSelector: &core.LabelSelector{}, Strategy: &core.UpdateStrategy{Type: "canary"}}
 
-	if _, err := reconciler.Reconcile(Key{Namespace: "ns", Name: "cp"}, spec); err == nil {
+	if _, err := reconciler.Reconcile(context.Background(), Key{Namespace: "ns", Name: "cp"}, spec); err == nil {
 		t.Fatalf("expected validation error")
 	}
 }
@@ -250,7 +305,7 @@ func TestReconcileSuccessCoversImpl(t *testing.T) {
 	reconciler := NewReconciler(fakeKubeClient, nil, nil)
 	spec := &core.ConfigPropagationSpec{SourceRef: core.ObjectRef{Namespace: "s", Name: "n"}, NamespaceSelector: &core.LabelSelector{}, Strategy: &core.UpdateStrategy{Type: core.StrategyImmediate}}
 
-	if _, err := reconciler.Reconcile(Key{Namespace: "ns", Name: "cp"}, spec); err != nil {
+	if _, err := reconciler.Reconcile(context.Background(), Key{Namespace: "ns", Name: "cp"}, spec); err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
 }
@@ -259,7 +314,7 @@ func TestReconcileNoTargetsNoUpserts(t *testing.T) {
 	fakeKubeClient := &fakeClient{data: map[string]map[string]map[string]string{"s": {"n": {"k": "v"}}}, namespaces: []string{}}
 	reconciler := NewReconciler(fakeKubeClient, nil, nil)
 	spec := &core.ConfigPropagationSpec{SourceRef: core.ObjectRef{Namespace: "s", Name: "n"}, NamespaceSelector: &core.LabelSelector{}}
-	rolloutResult, err := reconciler.Reconcile(Key{Namespace: "ns", Name: "cp"}, spec)
+	rolloutResult, err := reconciler.Reconcile(context.Background(), Key{Namespace: "ns", Name: "cp"}, spec)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -276,10 +331,14 @@ type capturedEvent struct {
 }
 
 type capturingEventRecorder struct {
+	mutex  sync.Mutex
 	events []capturedEvent
 }
 
 func (recorder *capturingEventRecorder) Normalf(name core.NamespacedName, reason, messageFmt string, args ...interface{}) {
+	recorder.mutex.Lock()
+	defer recorder.mutex.Unlock()
+
 	recorder.events = append(recorder.events, capturedEvent{
 		namespacedName: name,
 		reason:         reason,
@@ -289,6 +348,9 @@ func (recorder *capturingEventRecorder) Normalf(name core.NamespacedName, reason
 }
 
 func (recorder *capturingEventRecorder) Warningf(name core.NamespacedName, reason, messageFmt string, args ...interface{}) {
+	recorder.mutex.Lock()
+	defer recorder.mutex.Unlock()
+
 	recorder.events = append(recorder.events, capturedEvent{
 		namespacedName: name,
 		reason:         reason,
@@ -298,10 +360,13 @@ func (recorder *capturingEventRecorder) Warningf(name core.NamespacedName, reaso
 }
 
 type capturingMetricsRecorder struct {
+	mutex     sync.Mutex
 	counts    map[string]int
 	errors    map[string]int
+	drifts    int
 	targets   []struct{ total, outOfSync int }
 	durations []time.Duration
+	forgotten []core.NamespacedName
 }
 
 func newCapturingMetricsRecorder() *capturingMetricsRecorder {
@@ -309,76 +374,114 @@ func newCapturingMetricsRecorder() *capturingMetricsRecorder {
 }
 
 func (recorder *capturingMetricsRecorder) AddPropagations(action string, count int) {
+	recorder.mutex.Lock()
+	defer recorder.mutex.Unlock()
+
 	recorder.counts[action] += count
 }
 
-func (recorder *capturingMetricsRecorder) ObserveTargets(total, outOfSync int) {
+func (recorder *capturingMetricsRecorder) ObserveTargets(_ core.NamespacedName, total, outOfSync int) {
+	recorder.mutex.Lock()
+	defer recorder.mutex.Unlock()
+
 	recorder.targets = append(recorder.targets, struct{ total, outOfSync int }{total: total, outOfSync: outOfSync})
 }
 
+func (recorder *capturingMetricsRecorder) ForgetTargets(name core.NamespacedName) {
+	recorder.mutex.Lock()
+	defer recorder.mutex.Unlock()
+
+	recorder.forgotten = append(recorder.forgotten, name)
+}
+
 func (recorder *capturingMetricsRecorder) ObserveReconcileDuration(duration time.Duration) {
+	recorder.mutex.Lock()
+	defer recorder.mutex.Unlock()
+
 	recorder.durations = append(recorder.durations, duration)
 }
 
 func (recorder *capturingMetricsRecorder) IncError(stage string) {
+	recorder.mutex.Lock()
+	defer recorder.mutex.Unlock()
+
 	recorder.errors[stage]++
 }
 
+func (recorder *capturingMetricsRecorder) IncDrift() {
+	recorder.mutex.Lock()
+	defer recorder.mutex.Unlock()
+
+	recorder.drifts++
+}
+
 type instrumentationClient struct {
+	mutex    sync.Mutex
 	upserts  []string
 	deletes  []string
 	skipHash string
 }
 
 func newInstrumentationClient() *instrumentationClient {
-	return &instrumentationClient{skipHash: core.HashData(map[string]string{"key": "value"})}
+	return &instrumentationClient{skipHash: core.HashData(map[string]string{"key": "value"}, nil)}
 }
 
-func (client *instrumentationClient) GetSourceConfigMap(namespace, name string) (map[string]string, error) {
-	return map[string]string{"key": "value"}, nil
+func (client *instrumentationClient) GetSourceConfigMap(_ context.Context, namespace, name string) (map[string]string, map[string][]byte, error) {
+	return map[string]string{"key": "value"}, nil, nil
 }
 
-func (client *instrumentationClient) ListNamespacesBySelector(map[string]string, []adapters.LabelSelectorRequirement) ([]string, error) {
+func (client *instrumentationClient) ListNamespacesBySelector(context.Context, map[string]string, []adapters.LabelSelectorRequirement) ([]string, error) {
 	return []string{"new", "skip", "update"}, nil
 }
 
-func (client *instrumentationClient) UpsertConfigMap(namespace, name string, data map[string]string, labels, annotations map[string]string) error {
+func (client *instrumentationClient) GetNamespaceMetadata(_ context.Context, name string) (map[string]string, map[string]string, error) {
+	return nil, nil, nil
+}
+
+func (client *instrumentationClient) UpsertConfigMap(_ context.Context, namespace, name string, data map[string]string, binaryData map[string][]byte, labels, annotations map[string]string) error {
+	client.mutex.Lock()
+	defer client.mutex.Unlock()
+
 	client.upserts = append(client.upserts, fmt.Sprintf("%s/%s", namespace, name))
 	return nil
 }
 
-func (client *instrumentationClient) GetTargetConfigMap(namespace, name string) (map[string]string, map[string]string, map[string]string, bool, error) {
+func (client *instrumentationClient) GetTargetConfigMap(_ context.Context, namespace, name string) (map[string]string, map[string][]byte, map[string]string, map[string]string, bool, error) {
 	switch namespace {
 	case "new":
-		return nil, nil, nil, false, nil
+		return nil, nil, nil, nil, false, nil
 	case "skip":
-		return nil,
+		return map[string]string{"key": "value"}, nil,
 			map[string]string{core.ManagedLabel: "true"},
 			map[string]string{core.SourceAnnotation: "src/cfg", core.HashAnnotation: client.skipHash},
 			true, nil
 	case "update":
-		return nil,
+		outdated := map[string]string{"key": "outdated"}
+		return outdated, nil,
 			map[string]string{core.ManagedLabel: "true"},
-			map[string]string{core.SourceAnnotation: "src/cfg", core.HashAnnotation: "outdated"},
+			map[string]string{core.SourceAnnotation: "src/cfg", core.HashAnnotation: core.HashData(outdated, nil)},
 			true, nil
 	default:
-		return nil, nil, nil, false, nil
+		return nil, nil, nil, nil, false, nil
 	}
 }
 
-func (client *instrumentationClient) ListManagedTargetNamespaces(source string, name string) ([]string, error) {
-	if source == "src/cfg" && name == "cfg" {
-		return []string{"orphan"}, nil
+func (client *instrumentationClient) ListManagedTargets(_ context.Context, source, owner string) ([]adapters.ManagedTarget, error) {
+	if source == "src/cfg" {
+		return legacyTargets(source, []string{"orphan"}), nil
 	}
-	return []string{}, nil
+	return nil, nil
 }
 
-func (client *instrumentationClient) DeleteConfigMap(namespace, name string) error {
+func (client *instrumentationClient) DeleteConfigMap(_ context.Context, namespace, name string) error {
+	client.mutex.Lock()
+	defer client.mutex.Unlock()
+
 	client.deletes = append(client.deletes, fmt.Sprintf("%s/%s", namespace, name))
 	return nil
 }
 
-func (client *instrumentationClient) UpdateConfigMapMetadata(namespace, name string, labels, annotations map[string]string) error {
+func (client *instrumentationClient) UpdateConfigMapMetadata(_ context.Context, namespace, name string, labels, annotations map[string]string) error {
 	return nil
 }
 
@@ -397,7 +500,7 @@ func TestReconcilerEmitsEventsAndMetrics(t *testing.T) {
 		Prune:             &prune,
 	}
 
-	result, err := reconciler.Reconcile(Key{Namespace: "default", Name: "cp"}, spec)
+	result, err := reconciler.Reconcile(context.Background(), Key{Namespace: "default", Name: "cp"}, spec)
 	if err != nil {
 		t.Fatalf("unexpected reconcile error: %v", err)
 	}
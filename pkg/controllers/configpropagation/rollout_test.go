@@ -0,0 +1,151 @@
+package configpropagation
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"testing"
+
+	"configpropagation/pkg/adapters"
+	"configpropagation/pkg/core"
+)
+
+// fakeRolloutClient serves per-namespace targets so rollout progress can be rebuilt from the cluster.
+type fakeRolloutClient struct {
+	mutex      sync.Mutex
+	source     map[string]string
+	namespaces []string
+	targets    map[string]map[string]string
+	upserts    []string
+}
+
+func (f *fakeRolloutClient) GetSourceConfigMap(_ context.Context, namespace, name string) (map[string]string, map[string][]byte, error) {
+	return f.source, nil, nil
+}
+
+func (f *fakeRolloutClient) ListNamespacesBySelector(context.Context, map[string]string, []adapters.LabelSelectorRequirement) ([]string, error) {
+	return append([]string(nil), f.namespaces...), nil
+}
+
+func (f *fakeRolloutClient) GetNamespaceMetadata(_ context.Context, name string) (map[string]string, map[string]string, error) {
+	return nil, nil, nil
+}
+
+func (f *fakeRolloutClient) UpsertConfigMap(_ context.Context, namespace, name string, data map[string]string, binaryData map[string][]byte, labels, annotations map[string]string) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	f.upserts = append(f.upserts, namespace)
+	f.targets[namespace] = data
+	return nil
+}
+
+func (f *fakeRolloutClient) GetTargetConfigMap(_ context.Context, namespace, name string) (map[string]string, map[string][]byte, map[string]string, map[string]string, bool, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	data, found := f.targets[namespace]
+	if !found {
+		return nil, nil, nil, nil, false, nil
+	}
+	return data, nil, map[string]string{core.ManagedLabel: "true"}, map[string]string{core.SourceAnnotation: "src/cfg", core.HashAnnotation: core.HashData(data, nil)}, true, nil
+}
+
+func (f *fakeRolloutClient) ListManagedTargets(_ context.Context, source, owner string) ([]adapters.ManagedTarget, error) {
+	return nil, nil
+}
+
+func (f *fakeRolloutClient) DeleteConfigMap(_ context.Context, namespace, name string) error {
+	return nil
+}
+
+func (f *fakeRolloutClient) UpdateConfigMapMetadata(_ context.Context, namespace, name string, labels, annotations map[string]string) error {
+	return nil
+}
+
+func rollingSpec(batchSize int32) *core.ConfigPropagationSpec {
+	return &core.ConfigPropagationSpec{
+		SourceRef:         core.ObjectRef{Namespace: "src", Name: "cfg"},
+		NamespaceSelector: &core.LabelSelector{},
+		Strategy:          &core.UpdateStrategy{Type: core.StrategyRolling, BatchSize: &batchSize},
+	}
+}
+
+func TestRollingResumesFromPersistedStatus(t *testing.T) {
+	source := map[string]string{"k": "v"}
+	client := &fakeRolloutClient{source: source, namespaces: []string{"a", "b", "c", "d"}, targets: map[string]map[string]string{}}
+	key := Key{Namespace: "default", Name: "cp"}
+
+	first := NewReconciler(client, nil, nil)
+	result, err := first.Reconcile(context.Background(), key, rollingSpec(2))
+	if err != nil {
+		t.Fatalf("reconcile error: %v", err)
+	}
+	if result.Rollout == nil || !reflect.DeepEqual(result.Rollout.CompletedNamespaces, []string{"a", "b"}) || !reflect.DeepEqual(result.Rollout.CurrentBatch, []string{"a", "b"}) {
+		t.Fatalf("expected rollout status for first batch, got %+v", result.Rollout)
+	}
+	if result.Rollout.Hash != core.HashData(source, nil) || result.Rollout.StartedAt == "" {
+		t.Fatalf("expected hash and start time to be recorded, got %+v", result.Rollout)
+	}
+
+	// A new leader only knows what was persisted in status; completed targets are re-verified, not rewritten.
+	client.upserts = nil
+	second := NewReconciler(client, nil, nil)
+	second.RestoreRollout(key, result.Rollout)
+
+	resumed, err := second.Reconcile(context.Background(), key, rollingSpec(2))
+	if err != nil {
+		t.Fatalf("reconcile error: %v", err)
+	}
+	if !reflect.DeepEqual(resumed.Planned, []string{"c", "d"}) || resumed.CompletedCount != 4 {
+		t.Fatalf("expected resume at c,d with all complete, got %+v", resumed)
+	}
+	if !reflect.DeepEqual(client.upserts, []string{"c", "d"}) {
+		t.Fatalf("expected only remaining namespaces to be written, got %v", client.upserts)
+	}
+	if resumed.Rollout.StartedAt != result.Rollout.StartedAt {
+		t.Fatalf("expected start time to survive failover, got %q want %q", resumed.Rollout.StartedAt, result.Rollout.StartedAt)
+	}
+}
+
+func TestRollingRebuildsProgressFromTargetHashes(t *testing.T) {
+	source := map[string]string{"k": "v"}
+	client := &fakeRolloutClient{
+		source:     source,
+		namespaces: []string{"a", "b", "c", "d"},
+		// a and c already carry the desired content; b still has the previous revision.
+		targets: map[string]map[string]string{"a": {"k": "v"}, "b": {"k": "old"}, "c": {"k": "v"}},
+	}
+
+	reconciler := NewReconciler(client, nil, nil)
+	result, err := reconciler.Reconcile(context.Background(), Key{Namespace: "default", Name: "cp"}, rollingSpec(2))
+	if err != nil {
+		t.Fatalf("reconcile error: %v", err)
+	}
+	if !reflect.DeepEqual(result.Planned, []string{"b", "d"}) {
+		t.Fatalf("expected verified targets to be skipped, got planned %v", result.Planned)
+	}
+	if result.CompletedCount != 4 || len(result.OutOfSync) != 0 {
+		t.Fatalf("expected rollout to complete after one batch, got %+v", result)
+	}
+}
+
+func TestRestoreRolloutIgnoresStaleHash(t *testing.T) {
+	client := &fakeRolloutClient{source: map[string]string{"k": "v"}, namespaces: []string{"a", "b"}, targets: map[string]map[string]string{}}
+	key := Key{Namespace: "default", Name: "cp"}
+	reconciler := NewReconciler(client, nil, nil)
+
+	if _, err := reconciler.Reconcile(context.Background(), key, rollingSpec(1)); err != nil {
+		t.Fatalf("reconcile error: %v", err)
+	}
+
+	reconciler.RestoreRollout(key, &core.RolloutStatus{Hash: "stale", CompletedNamespaces: []string{"a", "b"}})
+
+	result, err := reconciler.Reconcile(context.Background(), key, rollingSpec(1))
+	if err != nil {
+		t.Fatalf("reconcile error: %v", err)
+	}
+	if !reflect.DeepEqual(result.Planned, []string{"b"}) {
+		t.Fatalf("expected stale status to be ignored, got planned %v", result.Planned)
+	}
+}
@@ -2,15 +2,18 @@ package configpropagation
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
 	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	"configpropagation/pkg/adapters"
@@ -27,16 +30,37 @@ type ConfigPropagationController struct {
 
 var _ reconcile.Reconciler = &ConfigPropagationController{}
 
+// Options configures the controller registered by SetupWithManager.
+type Options struct {
+	// ServerSideApply writes targets with server-side apply instead of get-then-update.
+	ServerSideApply bool
+	// APICallTimeout bounds every Kubernetes API call made while reconciling; zero disables the bound.
+	APICallTimeout time.Duration
+	// MaxConcurrentReconciles is how many ConfigPropagations are reconciled at once; values below 1 mean 1.
+	MaxConcurrentReconciles int
+	// RequireSourceOptIn only reads sources in other namespaces that list the ConfigPropagation's namespace in
+	// their allow-from-namespaces annotation.
+	RequireSourceOptIn bool
+}
+
 // NewController constructs a ConfigPropagationController wired with the manager's client.
-func NewController(manager ctrl.Manager) *ConfigPropagationController {
-	kubeClient := adapters.NewControllerRuntimeClient(manager.GetClient())
+func NewController(manager ctrl.Manager, options Options) *ConfigPropagationController {
+	clientOptions := adapters.ClientOptions{CallTimeout: options.APICallTimeout}
+
+	kubeClient := adapters.NewControllerRuntimeClient(manager.GetClient(), clientOptions)
+	if options.ServerSideApply {
+		kubeClient = adapters.NewServerSideApplyClient(manager.GetClient(), clientOptions)
+	}
 	eventRecorder := adapters.NewControllerRuntimeEventRecorder(manager.GetEventRecorderFor("configpropagation"))
 	metricsRecorder := adapters.NewPrometheusMetricsRecorder()
 
+	reconciler := NewReconciler(kubeClient, eventRecorder, metricsRecorder)
+	reconciler.RequireSourceOptIn(options.RequireSourceOptIn)
+
 	return &ConfigPropagationController{
 		Client:     manager.GetClient(),
 		logger:     ctrl.Log.WithName("controllers").WithName("ConfigPropagation"),
-		reconciler: NewReconciler(kubeClient, eventRecorder, metricsRecorder),
+		reconciler: reconciler,
 	}
 }
 
@@ -64,7 +88,7 @@ func (controller *ConfigPropagationController) Reconcile(requestContext context.
 		}
 	} else {
 		if controllerutil.ContainsFinalizer(&configPropagation, core.Finalizer) {
-			if err := controller.reconciler.Finalize(Key{Namespace: configPropagation.Namespace, Name: configPropagation.Name}, &configPropagation.Spec); err != nil {
+			if err := controller.reconciler.Finalize(requestContext, keyFor(&configPropagation), &configPropagation.Spec); err != nil {
 				return ctrl.Result{}, err
 			}
 
@@ -78,9 +102,25 @@ func (controller *ConfigPropagationController) Reconcile(requestContext context.
 		return ctrl.Result{}, nil
 	}
 
-	result, err := controller.reconciler.Reconcile(Key{Namespace: reconcileRequest.Namespace, Name: reconcileRequest.Name}, &configPropagation.Spec)
+	if configPropagation.Spec.Paused {
+		return ctrl.Result{}, controller.reportPaused(requestContext, &configPropagation)
+	}
+
+	key := keyFor(&configPropagation)
+	if configPropagation.Spec.DryRun {
+		return controller.reconcilePlan(requestContext, requestLogger, key, &configPropagation)
+	}
+
+	controller.reconciler.RestoreRollout(key, configPropagation.Status.Rollout)
+
+	result, err := controller.reconciler.Reconcile(requestContext, key, &configPropagation.Spec)
 	if err != nil {
-		requestLogger.Error(err, "reconciliation failed")
+		halted := waitsForChange(err)
+		if halted {
+			requestLogger.Info("reconciliation halted", "reason", err.Error())
+		} else {
+			requestLogger.Error(err, "reconciliation failed")
+		}
 
 		statusPatch := client.MergeFrom(configPropagation.DeepCopy())
 		configPropagation.ApplyErrorStatus(err)
@@ -92,6 +132,10 @@ func (controller *ConfigPropagationController) Reconcile(requestContext context.
 			requestLogger.Error(patchErr, "failed to update status after error")
 		}
 
+		if halted {
+			return ctrl.Result{}, nil
+		}
+
 		return ctrl.Result{}, err
 	}
 
@@ -112,6 +156,11 @@ func (controller *ConfigPropagationController) Reconcile(requestContext context.
 		requeueAfter = time.Duration(*configPropagation.Spec.ResyncPeriodSeconds) * time.Second
 	}
 
+	// Come back as soon as the earliest backed-off target may be retried.
+	if result.RetryAfter > 0 && (requeueAfter == 0 || result.RetryAfter < requeueAfter) {
+		requeueAfter = result.RetryAfter
+	}
+
 	if requeueAfter > 0 {
 		return ctrl.Result{RequeueAfter: requeueAfter}, nil
 	}
@@ -119,11 +168,83 @@ func (controller *ConfigPropagationController) Reconcile(requestContext context.
 	return ctrl.Result{}, nil
 }
 
+// reportPaused marks a paused ConfigPropagation as such without reading sources or touching targets. The status
+// is only patched once, so the watch events of the patch do not keep the CR busy.
+func (controller *ConfigPropagationController) reportPaused(requestContext context.Context, configPropagation *configv1alpha1.ConfigPropagation) error {
+	if configPropagation.IsReportedPaused() {
+		return nil
+	}
+
+	statusPatch := client.MergeFrom(configPropagation.DeepCopy())
+	configPropagation.ApplyPausedStatus()
+
+	if err := controller.Status().Patch(requestContext, configPropagation, statusPatch); err != nil && !apierrors.IsConflict(err) {
+		return fmt.Errorf("update status: %w", err)
+	}
+
+	return nil
+}
+
+// reconcilePlan records the plan of a ConfigPropagation in dry-run mode instead of reconciling it. The plan is
+// recomputed whenever the CR, a source, a namespace or a policy changes, and on the resync period.
+func (controller *ConfigPropagationController) reconcilePlan(requestContext context.Context, requestLogger logr.Logger, key Key, configPropagation *configv1alpha1.ConfigPropagation) (ctrl.Result, error) {
+	plan, err := controller.reconciler.Plan(requestContext, key, &configPropagation.Spec)
+
+	statusPatch := client.MergeFrom(configPropagation.DeepCopy())
+	if err != nil {
+		requestLogger.Info("dry run failed", "reason", err.Error())
+		configPropagation.ApplyErrorStatus(err)
+	} else {
+		configPropagation.ApplyPlanStatus(plan)
+	}
+
+	if patchErr := controller.Status().Patch(requestContext, configPropagation, statusPatch); patchErr != nil {
+		if apierrors.IsConflict(patchErr) {
+			return ctrl.Result{Requeue: true}, nil
+		}
+
+		return ctrl.Result{}, fmt.Errorf("update status: %w", patchErr)
+	}
+
+	if err != nil && !waitsForChange(err) {
+		return ctrl.Result{}, err
+	}
+
+	if configPropagation.Spec.ResyncPeriodSeconds != nil && *configPropagation.Spec.ResyncPeriodSeconds > 0 {
+		return ctrl.Result{RequeueAfter: time.Duration(*configPropagation.Spec.ResyncPeriodSeconds) * time.Second}, nil
+	}
+
+	return ctrl.Result{}, nil
+}
+
+// waitsForChange reports whether a reconcile error persists until the ConfigPropagation, a policy or a source
+// changes. Each of those enqueues a reconcile, so retrying with backoff would only repeat the error.
+func waitsForChange(err error) bool {
+	var policyViolation *core.PolicyViolationError
+	var notShareable *core.SourceNotShareableError
+
+	return errors.As(err, &policyViolation) || errors.As(err, &notShareable)
+}
+
+// keyFor identifies a ConfigPropagation read from the API server, including its UID.
+func keyFor(configPropagation *configv1alpha1.ConfigPropagation) Key {
+	return Key{Namespace: configPropagation.Namespace, Name: configPropagation.Name, UID: string(configPropagation.UID)}
+}
+
 // SetupWithManager registers the controller with the provided manager.
-func SetupWithManager(manager ctrl.Manager) error {
-	reconciler := NewController(manager)
+// Source and managed ConfigMaps plus Namespaces are watched so edits propagate without waiting for a resync,
+// and ConfigPropagationPolicies so a policy change halts or resumes the ConfigPropagations it affects.
+func SetupWithManager(manager ctrl.Manager, options Options) error {
+	if err := RegisterIndexes(context.Background(), manager.GetFieldIndexer()); err != nil {
+		return err
+	}
+
+	reconciler := NewController(manager, options)
 	return ctrl.NewControllerManagedBy(manager).
-		WithOptions(controller.Options{MaxConcurrentReconciles: 1}).
+		WithOptions(controller.Options{MaxConcurrentReconciles: max(options.MaxConcurrentReconciles, 1)}).
 		For(&configv1alpha1.ConfigPropagation{}).
+		Watches(&corev1.ConfigMap{}, handler.EnqueueRequestsFromMapFunc(reconciler.requestsForConfigMap)).
+		Watches(&corev1.Namespace{}, reconciler.namespaceEventHandler()).
+		Watches(&configv1alpha1.ConfigPropagationPolicy{}, handler.EnqueueRequestsFromMapFunc(reconciler.requestsForPolicy)).
 		Complete(reconciler)
 }
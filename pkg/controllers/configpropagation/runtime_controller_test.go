@@ -2,6 +2,7 @@ package configpropagation
 
 import (
 	"context"
+	"fmt"
 	"testing"
 	"time"
 
@@ -26,32 +27,36 @@ type stubKubeClient struct {
 	deleteCalls       [][2]string
 }
 
-func (s *stubKubeClient) GetSourceConfigMap(namespace, name string) (map[string]string, error) {
-	return map[string]string{"key": "value"}, nil
+func (s *stubKubeClient) GetSourceConfigMap(_ context.Context, namespace, name string) (map[string]string, map[string][]byte, error) {
+	return map[string]string{"key": "value"}, nil, nil
 }
 
-func (s *stubKubeClient) ListNamespacesBySelector(map[string]string, []adapters.LabelSelectorRequirement) ([]string, error) {
+func (s *stubKubeClient) ListNamespacesBySelector(context.Context, map[string]string, []adapters.LabelSelectorRequirement) ([]string, error) {
 	return nil, nil
 }
 
-func (s *stubKubeClient) UpsertConfigMap(string, string, map[string]string, map[string]string, map[string]string) error {
+func (s *stubKubeClient) GetNamespaceMetadata(_ context.Context, name string) (map[string]string, map[string]string, error) {
+	return nil, nil, nil
+}
+
+func (s *stubKubeClient) UpsertConfigMap(context.Context, string, string, map[string]string, map[string][]byte, map[string]string, map[string]string) error {
 	return nil
 }
 
-func (s *stubKubeClient) GetTargetConfigMap(string, string) (map[string]string, map[string]string, map[string]string, bool, error) {
-	return nil, nil, nil, false, nil
+func (s *stubKubeClient) GetTargetConfigMap(context.Context, string, string) (map[string]string, map[string][]byte, map[string]string, map[string]string, bool, error) {
+	return nil, nil, nil, nil, false, nil
 }
 
-func (s *stubKubeClient) ListManagedTargetNamespaces(string, string) ([]string, error) {
-	return append([]string(nil), s.managedNamespaces...), nil
+func (s *stubKubeClient) ListManagedTargets(_ context.Context, source, owner string) ([]adapters.ManagedTarget, error) {
+	return legacyTargets(source, s.managedNamespaces), nil
 }
 
-func (s *stubKubeClient) DeleteConfigMap(namespace, name string) error {
+func (s *stubKubeClient) DeleteConfigMap(_ context.Context, namespace, name string) error {
 	s.deleteCalls = append(s.deleteCalls, [2]string{namespace, name})
 	return nil
 }
 
-func (s *stubKubeClient) UpdateConfigMapMetadata(string, string, map[string]string, map[string]string) error {
+func (s *stubKubeClient) UpdateConfigMapMetadata(context.Context, string, string, map[string]string, map[string]string) error {
 	return nil
 }
 
@@ -98,6 +103,40 @@ func TestControllerAddsFinalizer(t *testing.T) {
 	}
 }
 
+func TestControllerLeavesPausedConfigPropagationAlone(t *testing.T) {
+	kubeStub := &stubKubeClient{managedNamespaces: []string{"ns1"}}
+
+	configPropagation := &configv1alpha1.ConfigPropagation{
+		ObjectMeta: metav1.ObjectMeta{Name: "cp", Namespace: "default", Finalizers: []string{core.Finalizer}},
+		Spec: core.ConfigPropagationSpec{
+			SourceRef:         core.ObjectRef{Namespace: "src", Name: "cfg"},
+			NamespaceSelector: &core.LabelSelector{},
+			Paused:            true,
+		},
+	}
+
+	controller := &ConfigPropagationController{Client: buildFakeClient(t, configPropagation), reconciler: NewReconciler(kubeStub, nil, nil)}
+
+	for attempt := 0; attempt < 2; attempt++ {
+		if result, err := controller.Reconcile(context.Background(), reconcile.Request{NamespacedName: client.ObjectKeyFromObject(configPropagation)}); err != nil || result != (ctrl.Result{}) {
+			t.Fatalf("reconcile: result %+v, err %v", result, err)
+		}
+	}
+
+	if len(kubeStub.deleteCalls) != 0 {
+		t.Fatalf("expected a paused CR not to prune, got %+v", kubeStub.deleteCalls)
+	}
+
+	var updated configv1alpha1.ConfigPropagation
+	if err := controller.Get(context.Background(), client.ObjectKeyFromObject(configPropagation), &updated); err != nil {
+		t.Fatalf("get updated object: %v", err)
+	}
+
+	if !updated.IsReportedPaused() || len(updated.Status.Conditions) != 1 {
+		t.Fatalf("expected Progressing to report the pause, got %+v", updated.Status.Conditions)
+	}
+}
+
 func TestControllerFinalizeRemovesFinalizer(t *testing.T) {
 	kubeStub := &stubKubeClient{managedNamespaces: []string{"ns1"}}
 	reconciler := NewReconciler(kubeStub, nil, nil)
@@ -169,3 +208,35 @@ func TestReconcileSchedulesResync(t *testing.T) {
 		t.Fatalf("expected RequeueAfter %s, got %+v", expected, result)
 	}
 }
+
+func TestReconcileRequeuesForBackedOffTargets(t *testing.T) {
+	resync := int32(45)
+	configPropagation := &configv1alpha1.ConfigPropagation{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "example"},
+		Spec: core.ConfigPropagationSpec{
+			SourceRef:           core.ObjectRef{Namespace: "src", Name: "cfg"},
+			NamespaceSelector:   &core.LabelSelector{},
+			ResyncPeriodSeconds: &resync,
+		},
+	}
+
+	kubeAdapter := &fakeFailingClient{namespaces: []string{"broken"}, upsertErrors: map[string]error{"broken": fmt.Errorf("boom")}}
+	reconciler := NewReconciler(kubeAdapter, nil, nil)
+	reconciler.targetBackoff = core.NewBackoffTracker[targetRetryKey](2*time.Second, time.Minute, nil, func() float64 { return 1 })
+
+	controller := &ConfigPropagationController{
+		Client:     buildFakeClient(t, configPropagation),
+		logger:     logr.Discard(),
+		reconciler: reconciler,
+	}
+
+	request := ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "example"}}
+	result, err := controller.Reconcile(context.Background(), request)
+	if err != nil {
+		t.Fatalf("unexpected reconcile error: %v", err)
+	}
+
+	if result.RequeueAfter != 2*time.Second {
+		t.Fatalf("expected requeue at the earliest retry instead of the resync period, got %+v", result)
+	}
+}
@@ -0,0 +1,65 @@
+package configpropagation
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"configpropagation/pkg/core"
+)
+
+func TestSourceOptInIsCheckedBeforeReading(t *testing.T) {
+	client := newMemoryKubeClient("app-1")
+	client.put("team-a", "local", &memoryConfigMap{data: map[string]string{"owner": "team-a"}})
+	client.put("platform", "base", &memoryConfigMap{data: map[string]string{"log": "info"}})
+
+	spec := func() *core.ConfigPropagationSpec {
+		return &core.ConfigPropagationSpec{
+			SourceRef:         core.ObjectRef{Namespace: "team-a", Name: "local"},
+			NamespaceSelector: &core.LabelSelector{},
+			Sources:           []core.SourceSpec{{Namespace: "platform", Name: "base"}},
+			Strategy:          &core.UpdateStrategy{Type: core.StrategyImmediate},
+		}
+	}
+
+	eventRecorder := &capturingEventRecorder{}
+	reconciler := NewReconciler(client, eventRecorder, nil)
+	reconciler.requireSourceOptIn = true
+	key := Key{Namespace: "team-a", Name: "cp"}
+
+	_, err := reconciler.Reconcile(context.Background(), key, spec())
+
+	var notShareable *core.SourceNotShareableError
+	if !errors.As(err, &notShareable) || notShareable.Source.Name != "base" || notShareable.Namespace != "team-a" {
+		t.Fatalf("expected the overlay without opt-in to be refused, got %v", err)
+	}
+
+	if _, written := client.configMaps[[2]string{"app-1", "local"}]; written {
+		t.Fatalf("expected nothing to be written while a source is not shareable")
+	}
+
+	if !hasEvent(eventRecorder, eventReasonSourceNotShareable, "Warning") {
+		t.Fatalf("expected a %s event, got %+v", eventReasonSourceNotShareable, eventRecorder.events)
+	}
+
+	client.put("platform", "base", &memoryConfigMap{
+		data:        map[string]string{"log": "info"},
+		annotations: map[string]string{core.AllowFromNamespacesAnnotation: "team-b, team-*"},
+	})
+
+	if _, err := reconciler.Reconcile(context.Background(), key, spec()); err != nil {
+		t.Fatalf("expected the opted-in source to be read: %v", err)
+	}
+
+	target := client.configMaps[[2]string{"app-1", "local"}]
+	if target == nil || target.data["log"] != "info" || target.data["owner"] != "team-a" {
+		t.Fatalf("expected both layers to be propagated, got %+v", target)
+	}
+
+	// Without the requirement, sources are read as before.
+	permissive := NewReconciler(newMemoryKubeClient("app-1"), nil, nil)
+	permissive.clientAdapter.(*memoryKubeClient).put("platform", "base", &memoryConfigMap{data: map[string]string{"log": "info"}})
+	if err := permissive.checkSourceShareable(context.Background(), key, core.ObjectRef{Namespace: "platform", Name: "base"}); err != nil {
+		t.Fatalf("expected the opt-in to be optional by default: %v", err)
+	}
+}
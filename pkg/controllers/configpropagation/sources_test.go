@@ -0,0 +1,117 @@
+package configpropagation
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"configpropagation/pkg/core"
+)
+
+// layeredSpec builds an immediate propagation of org/base overlaid by env/prod and team/overlay.
+func layeredSpec(mergeMode string, conflictPolicy string) *core.ConfigPropagationSpec {
+	return &core.ConfigPropagationSpec{
+		SourceRef:         core.ObjectRef{Namespace: "org", Name: "base"},
+		NamespaceSelector: &core.LabelSelector{},
+		Strategy:          &core.UpdateStrategy{Type: core.StrategyImmediate},
+		ConflictPolicy:    conflictPolicy,
+		MergeMode:         mergeMode,
+		Sources: []core.SourceSpec{
+			{Namespace: "env", Name: "prod"},
+			{Namespace: "team", Name: "overlay", DataKeys: []string{"log"}},
+		},
+	}
+}
+
+// layeredClient holds the three layers and selects the team-a namespace.
+func layeredClient() *memoryKubeClient {
+	client := newMemoryKubeClient("team-a")
+	client.put("org", "base", &memoryConfigMap{data: map[string]string{"log": "info", "region": "eu"}})
+	client.put("env", "prod", &memoryConfigMap{data: map[string]string{"log": "warn", "replicas": "3"}})
+	client.put("team", "overlay", &memoryConfigMap{data: map[string]string{"log": "debug", "ignored": "x"}})
+	return client
+}
+
+func TestMultipleSourcesMergeIntoOneTarget(t *testing.T) {
+	client := layeredClient()
+	reconciler := NewReconciler(client, nil, nil)
+
+	result, err := reconciler.Reconcile(context.Background(), Key{Namespace: "default", Name: "cp"}, layeredSpec("", ""))
+	if err != nil {
+		t.Fatalf("reconcile: %v", err)
+	}
+
+	target := client.configMaps[[2]string{"team-a", "base"}]
+	if target == nil {
+		t.Fatalf("expected target named after sourceRef")
+	}
+
+	wantData := map[string]string{"log": "debug", "region": "eu", "replicas": "3"}
+	if !reflect.DeepEqual(target.data, wantData) {
+		t.Fatalf("unexpected merged data %+v", target.data)
+	}
+
+	if target.annotations[core.HashAnnotation] != core.HashData(wantData, nil) || result.Planned[0] != "team-a" {
+		t.Fatalf("expected merged content to be hashed, got %+v", target.annotations)
+	}
+
+	if target.annotations[core.SourcesAnnotation] != "org/base,env/prod,team/overlay" {
+		t.Fatalf("unexpected sources annotation %q", target.annotations[core.SourcesAnnotation])
+	}
+
+	var keySources map[string]string
+	if err := json.Unmarshal([]byte(target.annotations[core.KeySourcesAnnotation]), &keySources); err != nil {
+		t.Fatalf("decode key sources: %v", err)
+	}
+	if !reflect.DeepEqual(keySources, map[string]string{"log": "team/overlay", "region": "org/base", "replicas": "env/prod"}) {
+		t.Fatalf("unexpected key sources %+v", keySources)
+	}
+}
+
+func TestMultipleSourcesRejectCollisions(t *testing.T) {
+	client := layeredClient()
+	reconciler := NewReconciler(client, nil, nil)
+
+	_, err := reconciler.Reconcile(context.Background(), Key{Namespace: "default", Name: "cp"}, layeredSpec(core.MergeReject, ""))
+	if err == nil || !strings.Contains(err.Error(), `"log"`) {
+		t.Fatalf("expected collision error, got %v", err)
+	}
+
+	if _, written := client.configMaps[[2]string{"team-a", "base"}]; written {
+		t.Fatalf("expected nothing to be written when sources collide")
+	}
+}
+
+func TestDriftNamesTheLayerOfEditedKeys(t *testing.T) {
+	client := layeredClient()
+	eventRecorder := &capturingEventRecorder{}
+	reconciler := NewReconciler(client, eventRecorder, nil)
+	key := Key{Namespace: "default", Name: "cp"}
+
+	if _, err := reconciler.Reconcile(context.Background(), key, layeredSpec("", core.ConflictSkip)); err != nil {
+		t.Fatalf("reconcile: %v", err)
+	}
+
+	client.configMaps[[2]string{"team-a", "base"}].data["replicas"] = "10"
+
+	result, err := reconciler.Reconcile(context.Background(), key, layeredSpec("", core.ConflictSkip))
+	if err != nil {
+		t.Fatalf("reconcile: %v", err)
+	}
+
+	if len(result.OutOfSync) != 1 || result.OutOfSync[0].Reason != core.ReasonDrifted || !strings.Contains(result.OutOfSync[0].Message, "replicas (from env/prod)") {
+		t.Fatalf("expected drift naming the env layer, got %+v", result.OutOfSync)
+	}
+
+	found := false
+	for _, event := range eventRecorder.events {
+		if event.reason == eventReasonConfigDrifted && strings.Contains(event.message, "replicas (from env/prod)") {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatalf("expected drift event naming the layer, got %+v", eventRecorder.events)
+	}
+}
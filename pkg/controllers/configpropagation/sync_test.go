@@ -1,7 +1,9 @@
 package configpropagation
 
 import (
+	"context"
 	"reflect"
+	"sync"
 	"testing"
 
 	"configpropagation/pkg/adapters"
@@ -9,31 +11,35 @@ import (
 )
 
 type fakeClientSync struct {
+	mutex sync.Mutex
 	// namespace -> name -> data
 	sources map[string]map[string]map[string]string
+	// namespace -> name -> binaryData
+	binarySources map[string]map[string]map[string][]byte
 	// namespace labels
 	nsLabels map[string]map[string]string
 	upserts  []struct {
 		ns, name            string
 		data                map[string]string
+		binaryData          map[string][]byte
 		labels, annotations map[string]string
 	}
 }
 
-func (f *fakeClientSync) GetSourceConfigMap(ns, name string) (map[string]string, error) {
+func (f *fakeClientSync) GetSourceConfigMap(_ context.Context, ns, name string) (map[string]string, map[string][]byte, error) {
 	if m1, ok := f.sources[ns]; ok {
 		if d, ok := m1[name]; ok {
 			out := map[string]string{}
 			for k, v := range d {
 				out[k] = v
 			}
-			return out, nil
+			return out, f.binarySources[ns][name], nil
 		}
 	}
-	return nil, nil
+	return nil, nil, nil
 }
 
-func (f *fakeClientSync) ListNamespacesBySelector(matchLabels map[string]string, _ []adapters.LabelSelectorRequirement) ([]string, error) {
+func (f *fakeClientSync) ListNamespacesBySelector(_ context.Context, matchLabels map[string]string, _ []adapters.LabelSelectorRequirement) ([]string, error) {
 	var res []string
 	for ns, lbls := range f.nsLabels {
 		ok := true
@@ -50,7 +56,14 @@ func (f *fakeClientSync) ListNamespacesBySelector(matchLabels map[string]string,
 	return res, nil
 }
 
-func (f *fakeClientSync) UpsertConfigMap(ns, name string, data map[string]string, labels, annotations map[string]string) error {
+func (f *fakeClientSync) GetNamespaceMetadata(_ context.Context, name string) (map[string]string, map[string]string, error) {
+	return f.nsLabels[name], nil, nil
+}
+
+func (f *fakeClientSync) UpsertConfigMap(_ context.Context, ns, name string, data map[string]string, binaryData map[string][]byte, labels, annotations map[string]string) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
 	// shallow copies for verification stability
 	d := map[string]string{}
 	for k, v := range data {
@@ -64,23 +77,28 @@ func (f *fakeClientSync) UpsertConfigMap(ns, name string, data map[string]string
 	for k, v := range annotations {
 		a[k] = v
 	}
+	b := map[string][]byte{}
+	for k, v := range binaryData {
+		b[k] = append([]byte(nil), v...)
+	}
 	f.upserts = append(f.upserts, struct {
 		ns, name            string
 		data                map[string]string
+		binaryData          map[string][]byte
 		labels, annotations map[string]string
-	}{ns, name, d, l, a})
+	}{ns, name, d, b, l, a})
 	return nil
 }
 
-func (f *fakeClientSync) GetTargetConfigMap(namespace, name string) (map[string]string, map[string]string, map[string]string, bool, error) {
+func (f *fakeClientSync) GetTargetConfigMap(_ context.Context, namespace, name string) (map[string]string, map[string][]byte, map[string]string, map[string]string, bool, error) {
 	// Default: no existing target
-	return nil, nil, nil, false, nil
+	return nil, nil, nil, nil, false, nil
 }
-func (f *fakeClientSync) ListManagedTargetNamespaces(source string, name string) ([]string, error) {
-	return []string{}, nil
+func (f *fakeClientSync) ListManagedTargets(_ context.Context, source, owner string) ([]adapters.ManagedTarget, error) {
+	return nil, nil
 }
-func (f *fakeClientSync) DeleteConfigMap(namespace, name string) error { return nil }
-func (f *fakeClientSync) UpdateConfigMapMetadata(namespace, name string, labels, annotations map[string]string) error {
+func (f *fakeClientSync) DeleteConfigMap(_ context.Context, namespace, name string) error { return nil }
+func (f *fakeClientSync) UpdateConfigMapMetadata(_ context.Context, namespace, name string, labels, annotations map[string]string) error {
 	return nil
 }
 
@@ -103,7 +121,7 @@ func TestSyncCopiesFilteredDataAndSetsManagedMetadata(t *testing.T) {
 		DataKeys:          []string{"a", "b"},
 		Strategy:          &core.UpdateStrategy{Type: core.StrategyImmediate},
 	}
-	planned, err := r.Reconcile(key, s)
+	planned, err := r.Reconcile(context.Background(), key, s)
 	if err != nil {
 		t.Fatalf("reconcile error: %v", err)
 	}
@@ -155,7 +173,7 @@ func TestSyncWhenSourceMissingAndEvents(t *testing.T) {
 		NamespaceSelector: &core.LabelSelector{MatchLabels: map[string]string{"team": "x"}},
 		Strategy:          &core.UpdateStrategy{Type: core.StrategyImmediate},
 	}
-	planned, err := r.Reconcile(key, s)
+	planned, err := r.Reconcile(context.Background(), key, s)
 	if err != nil {
 		t.Fatalf("reconcile error: %v", err)
 	}
@@ -191,7 +209,7 @@ func TestSyncCopiesAllWhenNoDataKeysAndExpressionsProvided(t *testing.T) {
 		},
 		Strategy: &core.UpdateStrategy{Type: core.StrategyImmediate},
 	}
-	planned, err := r.Reconcile(key, s)
+	planned, err := r.Reconcile(context.Background(), key, s)
 	if err != nil {
 		t.Fatalf("reconcile error: %v", err)
 	}
@@ -219,7 +237,7 @@ func TestSyncIgnoresMissingDataKeys(t *testing.T) {
 		DataKeys:          []string{"missing", "only"},
 		Strategy:          &core.UpdateStrategy{Type: core.StrategyImmediate},
 	}
-	_, err := r.Reconcile(key, s)
+	_, err := r.Reconcile(context.Background(), key, s)
 	if err != nil {
 		t.Fatalf("reconcile error: %v", err)
 	}
@@ -231,3 +249,35 @@ func TestSyncIgnoresMissingDataKeys(t *testing.T) {
 		t.Fatalf("unexpected data after filtering: %+v", u.data)
 	}
 }
+
+func TestSyncCopiesBinaryData(t *testing.T) {
+	certificate := []byte{0x30, 0x82, 0x01, 0x0a}
+	fc := &fakeClientSync{
+		sources:       map[string]map[string]map[string]string{"src": {"cfg": {"ca.pem": "text"}}},
+		binarySources: map[string]map[string]map[string][]byte{"src": {"cfg": {"ca.der": certificate, "logo.png": {0x89, 0x50}}}},
+		nsLabels:      map[string]map[string]string{"nsa": {}},
+	}
+	r := NewReconciler(fc, nil, nil)
+	s := &core.ConfigPropagationSpec{
+		SourceRef:         core.ObjectRef{Namespace: "src", Name: "cfg"},
+		NamespaceSelector: &core.LabelSelector{},
+		DataKeys:          []string{"ca.pem", "ca.der"},
+		Strategy:          &core.UpdateStrategy{Type: core.StrategyImmediate},
+	}
+	if _, err := r.Reconcile(context.Background(), Key{Namespace: "default", Name: "cp"}, s); err != nil {
+		t.Fatalf("reconcile error: %v", err)
+	}
+	if len(fc.upserts) != 1 {
+		t.Fatalf("expected 1 upsert, got %d", len(fc.upserts))
+	}
+	upsert := fc.upserts[0]
+	if !reflect.DeepEqual(upsert.binaryData, map[string][]byte{"ca.der": certificate}) {
+		t.Fatalf("expected filtered binaryData, got %+v", upsert.binaryData)
+	}
+	if !reflect.DeepEqual(upsert.data, map[string]string{"ca.pem": "text"}) {
+		t.Fatalf("unexpected data: %+v", upsert.data)
+	}
+	if upsert.annotations[core.HashAnnotation] != core.HashData(upsert.data, upsert.binaryData) {
+		t.Fatalf("hash annotation must cover binaryData")
+	}
+}
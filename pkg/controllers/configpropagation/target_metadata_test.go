@@ -0,0 +1,83 @@
+package configpropagation
+
+import (
+	"context"
+	"testing"
+
+	"configpropagation/pkg/core"
+)
+
+// labelledSpec propagates platform/app with custom target metadata and prune disabled, so deselection detaches.
+func labelledSpec(labels, annotations map[string]string) *core.ConfigPropagationSpec {
+	prune := false
+	return &core.ConfigPropagationSpec{
+		SourceRef:         core.ObjectRef{Namespace: "platform", Name: "app"},
+		NamespaceSelector: &core.LabelSelector{},
+		Strategy:          &core.UpdateStrategy{Type: core.StrategyImmediate},
+		Prune:             &prune,
+		Target:            &core.TargetSpec{Labels: labels, Annotations: annotations},
+	}
+}
+
+func TestTargetMetadataIsStampedUpdatedAndDetached(t *testing.T) {
+	client := newMemoryKubeClient("team-a")
+	client.put("platform", "app", &memoryConfigMap{data: map[string]string{"log": "info"}})
+	reconciler := NewReconciler(client, nil, nil)
+	key := Key{Namespace: "default", Name: "cp"}
+
+	spec := labelledSpec(map[string]string{"team": "a"}, map[string]string{"reloader.stakater.com/match": "true"})
+	if result, err := reconciler.Reconcile(context.Background(), key, spec); err != nil || len(result.OutOfSync) != 0 {
+		t.Fatalf("reconcile: %v %+v", err, result.OutOfSync)
+	}
+
+	target := client.configMaps[[2]string{"team-a", "app"}]
+	if target.labels["team"] != "a" || target.labels[core.ManagedLabel] != "true" || target.annotations["reloader.stakater.com/match"] != "true" {
+		t.Fatalf("expected custom metadata next to the managed metadata, got %+v %+v", target.labels, target.annotations)
+	}
+	target.labels["argocd.argoproj.io/instance"] = "shop"
+
+	// Metadata edits alone start a new rollout and reach targets whose data is unchanged.
+	edited := labelledSpec(map[string]string{"team": "b"}, nil)
+	before, _ := reconciler.desiredContent(context.Background(), key, spec)
+	after, _ := reconciler.desiredContent(context.Background(), key, edited)
+	if before.rolloutHash == after.rolloutHash || before.hash != after.hash {
+		t.Fatalf("expected metadata to change the rollout hash but not the data hash")
+	}
+
+	if _, err := reconciler.Reconcile(context.Background(), key, edited); err != nil {
+		t.Fatalf("reconcile edited spec: %v", err)
+	}
+
+	target = client.configMaps[[2]string{"team-a", "app"}]
+	if target.labels["team"] != "b" || target.labels["argocd.argoproj.io/instance"] != "shop" {
+		t.Fatalf("expected the label to change and foreign labels to survive, got %+v", target.labels)
+	}
+	if _, stale := target.annotations["reloader.stakater.com/match"]; stale {
+		t.Fatalf("expected an annotation dropped from the spec to be removed, got %+v", target.annotations)
+	}
+
+	// Deselecting the namespace detaches the target and takes the custom metadata with it.
+	client.namespaces = nil
+	if _, err := reconciler.Reconcile(context.Background(), key, edited); err != nil {
+		t.Fatalf("reconcile after deselection: %v", err)
+	}
+
+	target = client.configMaps[[2]string{"team-a", "app"}]
+	if _, kept := target.labels["team"]; kept || target.labels[core.ManagedLabel] != "" || target.labels["argocd.argoproj.io/instance"] != "shop" {
+		t.Fatalf("expected only foreign labels after detach, got %+v", target.labels)
+	}
+	if target.data["log"] != "info" {
+		t.Fatalf("expected detached data to be preserved")
+	}
+}
+
+func TestDetachStripsTargetMetadataWithoutOwnershipRecord(t *testing.T) {
+	labels, annotations := stripTargetMetadata(
+		&core.TargetSpec{Labels: map[string]string{"team": "a", "tier": "gold"}, Annotations: map[string]string{"note": "x"}},
+		map[string]string{"team": "a", "tier": "edited", "other": "kept"},
+		map[string]string{"note": "x"})
+
+	if len(labels) != 2 || labels["tier"] != "edited" || labels["other"] != "kept" || len(annotations) != 0 {
+		t.Fatalf("expected only unchanged custom keys to be stripped, got %+v %+v", labels, annotations)
+	}
+}
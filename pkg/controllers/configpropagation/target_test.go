@@ -0,0 +1,115 @@
+package configpropagation
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"configpropagation/pkg/core"
+)
+
+// targetSpec builds an immediate propagation of src/platform-base with the given target naming.
+func targetSpec(target *core.TargetSpec, prune bool) *core.ConfigPropagationSpec {
+	return &core.ConfigPropagationSpec{
+		SourceRef:         core.ObjectRef{Namespace: "src", Name: "platform-base"},
+		NamespaceSelector: &core.LabelSelector{},
+		Strategy:          &core.UpdateStrategy{Type: core.StrategyImmediate},
+		Prune:             boolPtr(prune),
+		Target:            target,
+	}
+}
+
+func TestTargetNameAndTemplate(t *testing.T) {
+	client := newMemoryKubeClient("team-a", "team-b")
+	client.put("src", "platform-base", &memoryConfigMap{data: map[string]string{"k": "v"}})
+	reconciler := NewReconciler(client, nil, nil)
+	key := Key{Namespace: "default", Name: "cp"}
+
+	if _, err := reconciler.Reconcile(context.Background(), key, targetSpec(&core.TargetSpec{Name: "app-config"}, true)); err != nil {
+		t.Fatalf("reconcile: %v", err)
+	}
+
+	want := []string{"src/platform-base", "team-a/app-config", "team-b/app-config"}
+	if got := client.names(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("fixed name: want %v got %v", want, got)
+	}
+
+	owner := client.configMaps[[2]string{"team-a", "app-config"}].annotations[core.OwnerAnnotation]
+	if owner != "default/cp" {
+		t.Fatalf("expected owner annotation, got %q", owner)
+	}
+
+	// Switching to a template renames every target and prunes the old names.
+	if _, err := reconciler.Reconcile(context.Background(), key, targetSpec(&core.TargetSpec{NameTemplate: "{{ .SourceName }}-{{ .Namespace }}"}, true)); err != nil {
+		t.Fatalf("reconcile: %v", err)
+	}
+
+	want = []string{"src/platform-base", "team-a/platform-base-team-a", "team-b/platform-base-team-b"}
+	if got := client.names(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("template: want %v got %v", want, got)
+	}
+
+	// Deselection and finalization follow the rendered names.
+	client.namespaces = []string{"team-a"}
+	if _, err := reconciler.Reconcile(context.Background(), key, targetSpec(&core.TargetSpec{NameTemplate: "{{ .SourceName }}-{{ .Namespace }}"}, true)); err != nil {
+		t.Fatalf("reconcile: %v", err)
+	}
+	if _, found := client.configMaps[[2]string{"team-b", "platform-base-team-b"}]; found {
+		t.Fatalf("expected deselected target to be pruned")
+	}
+
+	if err := reconciler.Finalize(context.Background(), key, targetSpec(&core.TargetSpec{NameTemplate: "{{ .SourceName }}-{{ .Namespace }}"}, true)); err != nil {
+		t.Fatalf("finalize: %v", err)
+	}
+	if got := client.names(); !reflect.DeepEqual(got, []string{"src/platform-base"}) {
+		t.Fatalf("expected finalize to remove all targets, got %v", got)
+	}
+}
+
+func TestCleanupLeavesOtherOwnersTargets(t *testing.T) {
+	client := newMemoryKubeClient("team-a")
+	client.put("src", "platform-base", &memoryConfigMap{data: map[string]string{"k": "v"}})
+	client.put("team-a", "other-config", &memoryConfigMap{
+		data:        map[string]string{"k": "v"},
+		labels:      map[string]string{core.ManagedLabel: "true"},
+		annotations: map[string]string{core.SourceAnnotation: "src/platform-base", core.OwnerAnnotation: "default/other"},
+	})
+	client.put("team-a", "platform-base", &memoryConfigMap{
+		data:        map[string]string{"k": "v"},
+		labels:      map[string]string{core.ManagedLabel: "true"},
+		annotations: map[string]string{core.SourceAnnotation: "src/platform-base"},
+	})
+	reconciler := NewReconciler(client, nil, nil)
+
+	if _, err := reconciler.Reconcile(context.Background(), Key{Namespace: "default", Name: "cp"}, targetSpec(&core.TargetSpec{Name: "app-config"}, false)); err != nil {
+		t.Fatalf("reconcile: %v", err)
+	}
+
+	if _, found := client.configMaps[[2]string{"team-a", "other-config"}]; !found {
+		t.Fatalf("expected another propagation's target to be left alone")
+	}
+
+	// The legacy target written under the source name is detached after the rename.
+	legacy := client.configMaps[[2]string{"team-a", "platform-base"}]
+	if legacy == nil || legacy.labels[core.ManagedLabel] != "" || legacy.annotations[core.SourceAnnotation] != "" {
+		t.Fatalf("expected legacy target to be detached, got %+v", legacy)
+	}
+}
+
+func TestInvalidRenderedTargetNameIsReported(t *testing.T) {
+	client := newMemoryKubeClient("Team_A", "team-b")
+	client.put("src", "platform-base", &memoryConfigMap{data: map[string]string{"k": "v"}})
+	reconciler := NewReconciler(client, nil, nil)
+
+	result, err := reconciler.Reconcile(context.Background(), Key{Namespace: "default", Name: "cp"}, targetSpec(&core.TargetSpec{NameTemplate: "cfg-{{ .Namespace }}"}, true))
+	if err != nil {
+		t.Fatalf("reconcile: %v", err)
+	}
+
+	if len(result.OutOfSync) != 1 || result.OutOfSync[0].Namespace != "Team_A" || result.OutOfSync[0].Reason != core.ReasonInvalidTargetName {
+		t.Fatalf("expected invalid name to be reported, got %+v", result.OutOfSync)
+	}
+	if _, found := client.configMaps[[2]string{"team-b", "cfg-team-b"}]; !found {
+		t.Fatalf("expected valid namespaces to sync")
+	}
+}
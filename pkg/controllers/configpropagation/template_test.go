@@ -0,0 +1,113 @@
+package configpropagation
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"configpropagation/pkg/core"
+)
+
+// templatedClient selects two namespaces with different tiers and holds a templated source.
+func templatedClient() *memoryKubeClient {
+	client := newMemoryKubeClient("payments", "search")
+	client.namespaceLabels = map[string]map[string]string{"payments": {"tier": "gold"}, "search": {"tier": "silver"}}
+	client.put("platform", "app", &memoryConfigMap{data: map[string]string{"endpoint": "https://{{ .Namespace }}.{{ .Values.domain }}", "tier": "{{ .Labels.tier }}"}})
+	return client
+}
+
+// templatedSpec renders the platform/app source with the given cluster-wide values.
+func templatedSpec(values map[string]string) *core.ConfigPropagationSpec {
+	return &core.ConfigPropagationSpec{
+		SourceRef:         core.ObjectRef{Namespace: "platform", Name: "app"},
+		NamespaceSelector: &core.LabelSelector{},
+		Strategy:          &core.UpdateStrategy{Type: core.StrategyImmediate},
+		Template:          &core.TemplateSpec{Enabled: true, Values: values},
+	}
+}
+
+func TestTemplateRendersPerNamespace(t *testing.T) {
+	client := templatedClient()
+	reconciler := NewReconciler(client, nil, nil)
+	key := Key{Namespace: "default", Name: "cp"}
+
+	result, err := reconciler.Reconcile(context.Background(), key, templatedSpec(map[string]string{"domain": "example.com"}))
+	if err != nil || len(result.OutOfSync) != 0 {
+		t.Fatalf("reconcile: %v %+v", err, result.OutOfSync)
+	}
+
+	payments := client.configMaps[[2]string{"payments", "app"}]
+	search := client.configMaps[[2]string{"search", "app"}]
+	if payments.data["endpoint"] != "https://payments.example.com" || payments.data["tier"] != "gold" || search.data["tier"] != "silver" {
+		t.Fatalf("unexpected rendering: %+v %+v", payments.data, search.data)
+	}
+
+	if payments.annotations[core.HashAnnotation] != core.HashData(payments.data, nil) || payments.annotations[core.HashAnnotation] == search.annotations[core.HashAnnotation] {
+		t.Fatalf("expected each target to record the hash of its rendered data")
+	}
+
+	// A second pass over unchanged inputs rewrites nothing, so a marker on the live object survives.
+	payments.labels["sentinel"] = "kept"
+	if _, err := reconciler.Reconcile(context.Background(), key, templatedSpec(map[string]string{"domain": "example.com"})); err != nil {
+		t.Fatalf("second reconcile: %v", err)
+	}
+	if client.configMaps[[2]string{"payments", "app"}].labels["sentinel"] != "kept" {
+		t.Fatalf("expected rendered targets to be recognized as up to date")
+	}
+}
+
+func TestTemplateErrorsArePerNamespace(t *testing.T) {
+	client := templatedClient()
+	delete(client.namespaceLabels, "search")
+	eventRecorder := &capturingEventRecorder{}
+	reconciler := NewReconciler(client, eventRecorder, nil)
+
+	result, err := reconciler.Reconcile(context.Background(), Key{Namespace: "default", Name: "cp"}, templatedSpec(map[string]string{"domain": "example.com"}))
+	if err != nil {
+		t.Fatalf("template errors must not fail the reconcile: %v", err)
+	}
+
+	if len(result.OutOfSync) != 1 || result.OutOfSync[0].Namespace != "search" || result.OutOfSync[0].Reason != core.ReasonTemplateError {
+		t.Fatalf("expected a TemplateError for search only, got %+v", result.OutOfSync)
+	}
+
+	if !strings.Contains(result.OutOfSync[0].Message, `key "tier"`) {
+		t.Fatalf("expected the failing key in the message, got %q", result.OutOfSync[0].Message)
+	}
+
+	if _, written := client.configMaps[[2]string{"payments", "app"}]; !written {
+		t.Fatalf("expected healthy namespace to be written")
+	}
+
+	if _, written := client.configMaps[[2]string{"search", "app"}]; written {
+		t.Fatalf("expected failing namespace to be left alone")
+	}
+}
+
+func TestRollingTemplateRerendersCompletedNamespaceOnLabelChange(t *testing.T) {
+	client := templatedClient()
+	reconciler := NewReconciler(client, nil, nil)
+	key := Key{Namespace: "default", Name: "cp"}
+	batchSize := int32(1)
+	spec := func() *core.ConfigPropagationSpec {
+		spec := templatedSpec(map[string]string{"domain": "example.com"})
+		spec.Strategy = &core.UpdateStrategy{Type: core.StrategyRolling, BatchSize: &batchSize}
+		return spec
+	}
+
+	for batch := 0; batch < 2; batch++ {
+		if _, err := reconciler.Reconcile(context.Background(), key, spec()); err != nil {
+			t.Fatalf("reconcile: %v", err)
+		}
+	}
+
+	// The rollout is complete; relabelling a namespace leaves the content fingerprint as it was.
+	client.namespaceLabels["payments"] = map[string]string{"tier": "platinum"}
+	result, err := reconciler.Reconcile(context.Background(), key, spec())
+	if err != nil || len(result.OutOfSync) != 0 {
+		t.Fatalf("reconcile: %v %+v", err, result.OutOfSync)
+	}
+	if tier := client.configMaps[[2]string{"payments", "app"}].data["tier"]; tier != "platinum" {
+		t.Fatalf("expected the completed namespace to be re-rendered, got tier %q", tier)
+	}
+}
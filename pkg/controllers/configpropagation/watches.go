@@ -0,0 +1,271 @@
+package configpropagation
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"configpropagation/pkg/adapters"
+	configv1alpha1 "configpropagation/pkg/api/v1alpha1"
+	"configpropagation/pkg/core"
+)
+
+const (
+	// sourceRefIndex maps a source ConfigMap namespace/name to the ConfigPropagations reading it, as sourceRef or overlay.
+	sourceRefIndex = "spec.sourceRef"
+	// selectorLabelIndex maps a namespace label key to the ConfigPropagations whose selector requires it.
+	selectorLabelIndex = "spec.namespaceSelector.labelKeys"
+	// anyLabelKey indexes selectors that can match namespaces without requiring any label key.
+	anyLabelKey = "*"
+)
+
+// RegisterIndexes installs the field indexers used to map watched objects back to ConfigPropagations.
+func RegisterIndexes(requestContext context.Context, indexer client.FieldIndexer) error {
+	if err := indexer.IndexField(requestContext, &configv1alpha1.ConfigPropagation{}, sourceRefIndex, sourceRefIndexValues); err != nil {
+		return fmt.Errorf("index %s: %w", sourceRefIndex, err)
+	}
+
+	if err := indexer.IndexField(requestContext, &configv1alpha1.ConfigPropagation{}, selectorLabelIndex, selectorLabelIndexValues); err != nil {
+		return fmt.Errorf("index %s: %w", selectorLabelIndex, err)
+	}
+
+	return adapters.RegisterTargetIndexes(requestContext, indexer)
+}
+
+// sourceRefIndexValues returns the namespace/name of every source layer read by a ConfigPropagation.
+func sourceRefIndexValues(object client.Object) []string {
+	configPropagation, ok := object.(*configv1alpha1.ConfigPropagation)
+	if !ok {
+		return nil
+	}
+
+	var values []string
+
+	for _, sourceLayer := range core.SourceLayers(&configPropagation.Spec) {
+		if sourceLayer.Namespace == "" || sourceLayer.Name == "" {
+			continue
+		}
+
+		values = append(values, fmt.Sprintf("%s/%s", sourceLayer.Namespace, sourceLayer.Name))
+	}
+
+	return values
+}
+
+// selectorLabelIndexValues returns the label keys a namespace must carry to match the selector.
+// Selectors that can match namespaces without any particular key are indexed under anyLabelKey.
+func selectorLabelIndexValues(object client.Object) []string {
+	configPropagation, ok := object.(*configv1alpha1.ConfigPropagation)
+	if !ok {
+		return nil
+	}
+
+	selector := configPropagation.Spec.NamespaceSelector
+	if selector == nil {
+		return []string{anyLabelKey}
+	}
+
+	requiredKeys := map[string]struct{}{}
+
+	for labelKey := range selector.MatchLabels {
+		requiredKeys[labelKey] = struct{}{}
+	}
+
+	for _, expression := range selector.MatchExpressions {
+		if expression.Operator == "In" || expression.Operator == "Exists" {
+			requiredKeys[expression.Key] = struct{}{}
+		}
+	}
+
+	if len(requiredKeys) == 0 {
+		return []string{anyLabelKey}
+	}
+
+	values := make([]string, 0, len(requiredKeys))
+	for labelKey := range requiredKeys {
+		values = append(values, labelKey)
+	}
+
+	sort.Strings(values)
+	return values
+}
+
+// requestsForConfigMap enqueues every ConfigPropagation that reads the changed ConfigMap as a source,
+// or that manages it as a target so out-of-band edits are detected as drift.
+func (controller *ConfigPropagationController) requestsForConfigMap(requestContext context.Context, object client.Object) []reconcile.Request {
+	sourceIdentifiers := []string{fmt.Sprintf("%s/%s", object.GetNamespace(), object.GetName())}
+
+	if object.GetLabels()[core.ManagedLabel] == "true" {
+		if managedSource := object.GetAnnotations()[core.SourceAnnotation]; managedSource != "" {
+			sourceIdentifiers = append(sourceIdentifiers, managedSource)
+		}
+	}
+
+	var requests []reconcile.Request
+
+	// Managed targets name their writer directly, which covers targets renamed away from the source name.
+	if object.GetLabels()[core.ManagedLabel] == "true" {
+		if ownerNamespace, ownerName, found := strings.Cut(object.GetAnnotations()[core.OwnerAnnotation], "/"); found {
+			requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: ownerNamespace, Name: ownerName}})
+		}
+	}
+
+	for _, sourceIdentifier := range sourceIdentifiers {
+		var configPropagations configv1alpha1.ConfigPropagationList
+
+		if err := controller.List(requestContext, &configPropagations, client.MatchingFields{sourceRefIndex: sourceIdentifier}); err != nil {
+			controller.logger.Error(err, "list ConfigPropagations for source", "source", sourceIdentifier)
+			continue
+		}
+
+		for index := range configPropagations.Items {
+			requests = append(requests, requestFor(&configPropagations.Items[index]))
+		}
+	}
+
+	return requests
+}
+
+// requestsForPolicy enqueues every ConfigPropagation, since any of them may start or stop violating the changed policy.
+func (controller *ConfigPropagationController) requestsForPolicy(requestContext context.Context, _ client.Object) []reconcile.Request {
+	var configPropagations configv1alpha1.ConfigPropagationList
+
+	if err := controller.List(requestContext, &configPropagations); err != nil {
+		controller.logger.Error(err, "list ConfigPropagations for policy change")
+		return nil
+	}
+
+	requests := make([]reconcile.Request, 0, len(configPropagations.Items))
+	for index := range configPropagations.Items {
+		requests = append(requests, requestFor(&configPropagations.Items[index]))
+	}
+
+	return requests
+}
+
+// requestsForNamespaceLabels enqueues every ConfigPropagation whose selector matches any of the label sets.
+// Passing both the previous and current labels covers namespaces entering and leaving a selection.
+func (controller *ConfigPropagationController) requestsForNamespaceLabels(requestContext context.Context, labelSets ...map[string]string) []reconcile.Request {
+	return controller.requestsForNamespace(requestContext, func(*configv1alpha1.ConfigPropagation) bool { return true }, labelSets...)
+}
+
+// requestsForNamespaceAnnotations enqueues the templated ConfigPropagations selecting a namespace with the labels,
+// since only templates read namespace annotations.
+func (controller *ConfigPropagationController) requestsForNamespaceAnnotations(requestContext context.Context, namespaceLabels map[string]string) []reconcile.Request {
+	templated := func(configPropagation *configv1alpha1.ConfigPropagation) bool {
+		return configPropagation.Spec.Template != nil && configPropagation.Spec.Template.Enabled
+	}
+
+	return controller.requestsForNamespace(requestContext, templated, namespaceLabels)
+}
+
+// requestsForNamespace enqueues the ConfigPropagations accepted by the filter whose selector matches any of the
+// label sets.
+func (controller *ConfigPropagationController) requestsForNamespace(requestContext context.Context, accept func(*configv1alpha1.ConfigPropagation) bool, labelSets ...map[string]string) []reconcile.Request {
+	candidateKeys := map[string]struct{}{anyLabelKey: {}}
+	for _, labelSet := range labelSets {
+		for labelKey := range labelSet {
+			candidateKeys[labelKey] = struct{}{}
+		}
+	}
+
+	seen := map[types.NamespacedName]struct{}{}
+	var requests []reconcile.Request
+
+	for labelKey := range candidateKeys {
+		var configPropagations configv1alpha1.ConfigPropagationList
+
+		if err := controller.List(requestContext, &configPropagations, client.MatchingFields{selectorLabelIndex: labelKey}); err != nil {
+			controller.logger.Error(err, "list ConfigPropagations for namespace label", "labelKey", labelKey)
+			continue
+		}
+
+		for index := range configPropagations.Items {
+			configPropagation := &configPropagations.Items[index]
+
+			request := requestFor(configPropagation)
+			if _, alreadyQueued := seen[request.NamespacedName]; alreadyQueued {
+				continue
+			}
+
+			if !accept(configPropagation) || !selectorMatchesAny(configPropagation.Spec.NamespaceSelector, labelSets) {
+				continue
+			}
+
+			seen[request.NamespacedName] = struct{}{}
+			requests = append(requests, request)
+		}
+	}
+
+	sort.Slice(requests, func(left, right int) bool {
+		return requests[left].String() < requests[right].String()
+	})
+
+	return requests
+}
+
+// namespaceEventHandler reacts to namespace lifecycle and label changes, and to annotation changes that templates
+// can render.
+func (controller *ConfigPropagationController) namespaceEventHandler() handler.EventHandler {
+	enqueue := func(queue workqueue.RateLimitingInterface, requests []reconcile.Request) {
+		for _, request := range requests {
+			queue.Add(request)
+		}
+	}
+
+	return handler.Funcs{
+		CreateFunc: func(requestContext context.Context, createEvent event.CreateEvent, queue workqueue.RateLimitingInterface) {
+			enqueue(queue, controller.requestsForNamespaceLabels(requestContext, createEvent.Object.GetLabels()))
+		},
+		UpdateFunc: func(requestContext context.Context, updateEvent event.UpdateEvent, queue workqueue.RateLimitingInterface) {
+			oldLabels := updateEvent.ObjectOld.GetLabels()
+			newLabels := updateEvent.ObjectNew.GetLabels()
+
+			if labels.Equals(oldLabels, newLabels) {
+				if !labels.Equals(updateEvent.ObjectOld.GetAnnotations(), updateEvent.ObjectNew.GetAnnotations()) {
+					enqueue(queue, controller.requestsForNamespaceAnnotations(requestContext, newLabels))
+				}
+				return
+			}
+
+			enqueue(queue, controller.requestsForNamespaceLabels(requestContext, oldLabels, newLabels))
+		},
+		DeleteFunc: func(requestContext context.Context, deleteEvent event.DeleteEvent, queue workqueue.RateLimitingInterface) {
+			enqueue(queue, controller.requestsForNamespaceLabels(requestContext, deleteEvent.Object.GetLabels()))
+		},
+	}
+}
+
+// selectorMatchesAny reports whether the selector matches at least one of the label sets.
+func selectorMatchesAny(selector *core.LabelSelector, labelSets []map[string]string) bool {
+	if selector == nil {
+		return true
+	}
+
+	namespaceSelector, err := adapters.NewLabelSelector(selector.MatchLabels, selectorRequirements(selector))
+	if err != nil {
+		return false
+	}
+
+	for _, labelSet := range labelSets {
+		if namespaceSelector.Matches(labels.Set(labelSet)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// requestFor builds the reconcile request for a ConfigPropagation.
+func requestFor(configPropagation *configv1alpha1.ConfigPropagation) reconcile.Request {
+	return reconcile.Request{NamespacedName: types.NamespacedName{Namespace: configPropagation.Namespace, Name: configPropagation.Name}}
+}
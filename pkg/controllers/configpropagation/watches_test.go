@@ -0,0 +1,198 @@
+package configpropagation
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	configv1alpha1 "configpropagation/pkg/api/v1alpha1"
+	"configpropagation/pkg/core"
+)
+
+func buildIndexedClient(t *testing.T, objects ...client.Object) client.Client {
+	t.Helper()
+
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatalf("add core scheme: %v", err)
+	}
+
+	if err := configv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatalf("add configpropagation scheme: %v", err)
+	}
+
+	return fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(objects...).
+		WithIndex(&configv1alpha1.ConfigPropagation{}, sourceRefIndex, sourceRefIndexValues).
+		WithIndex(&configv1alpha1.ConfigPropagation{}, selectorLabelIndex, selectorLabelIndexValues).
+		Build()
+}
+
+func watchedPropagation(namespace, name string, source core.ObjectRef, selector *core.LabelSelector) *configv1alpha1.ConfigPropagation {
+	return &configv1alpha1.ConfigPropagation{
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+		Spec:       core.ConfigPropagationSpec{SourceRef: source, NamespaceSelector: selector},
+	}
+}
+
+func requestNames(t *testing.T, queue workqueue.RateLimitingInterface) []string {
+	t.Helper()
+
+	var names []string
+	for queue.Len() > 0 {
+		item, _ := queue.Get()
+		names = append(names, item.(reconcile.Request).String())
+		queue.Done(item)
+	}
+
+	return names
+}
+
+func TestSelectorLabelIndexValues(t *testing.T) {
+	cases := map[string]struct {
+		selector *core.LabelSelector
+		want     []string
+	}{
+		"nil selector":        {selector: nil, want: []string{anyLabelKey}},
+		"empty selector":      {selector: &core.LabelSelector{}, want: []string{anyLabelKey}},
+		"match labels":        {selector: &core.LabelSelector{MatchLabels: map[string]string{"team": "a", "env": "prod"}}, want: []string{"env", "team"}},
+		"in and exists":       {selector: &core.LabelSelector{MatchExpressions: []core.LabelSelectorReq{{Key: "tier", Operator: "In", Values: []string{"web"}}, {Key: "owner", Operator: "Exists"}}}, want: []string{"owner", "tier"}},
+		"negative operations": {selector: &core.LabelSelector{MatchExpressions: []core.LabelSelectorReq{{Key: "skip", Operator: "DoesNotExist"}}}, want: []string{anyLabelKey}},
+	}
+
+	for name, testCase := range cases {
+		got := selectorLabelIndexValues(watchedPropagation("default", "cp", core.ObjectRef{Namespace: "src", Name: "cfg"}, testCase.selector))
+		if !reflect.DeepEqual(got, testCase.want) {
+			t.Fatalf("%s: want %v got %v", name, testCase.want, got)
+		}
+	}
+}
+
+func TestRequestsForConfigMap(t *testing.T) {
+	reader := watchedPropagation("team-a", "reader", core.ObjectRef{Namespace: "platform", Name: "base"}, &core.LabelSelector{})
+	other := watchedPropagation("team-b", "other", core.ObjectRef{Namespace: "platform", Name: "unrelated"}, &core.LabelSelector{})
+
+	controller := &ConfigPropagationController{Client: buildIndexedClient(t, reader, other), logger: logr.Discard()}
+
+	source := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "platform", Name: "base"}}
+	requests := controller.requestsForConfigMap(context.Background(), source)
+	if len(requests) != 1 || requests[0].String() != "team-a/reader" {
+		t.Fatalf("expected only team-a/reader, got %+v", requests)
+	}
+
+	managedTarget := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{
+		Namespace:   "tenant",
+		Name:        "base",
+		Labels:      map[string]string{core.ManagedLabel: "true"},
+		Annotations: map[string]string{core.SourceAnnotation: "platform/base"},
+	}}
+	requests = controller.requestsForConfigMap(context.Background(), managedTarget)
+	if len(requests) != 1 || requests[0].String() != "team-a/reader" {
+		t.Fatalf("expected managed target edit to enqueue team-a/reader, got %+v", requests)
+	}
+
+	unwatched := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "platform", Name: "nobody"}}
+	if requests := controller.requestsForConfigMap(context.Background(), unwatched); len(requests) != 0 {
+		t.Fatalf("expected no requests for unreferenced ConfigMap, got %+v", requests)
+	}
+}
+
+func TestNamespaceHandlerEnqueuesMatchingSelectors(t *testing.T) {
+	source := core.ObjectRef{Namespace: "platform", Name: "base"}
+	payments := watchedPropagation("default", "payments", source, &core.LabelSelector{MatchLabels: map[string]string{"team": "payments"}})
+	search := watchedPropagation("default", "search", source, &core.LabelSelector{MatchLabels: map[string]string{"team": "search"}})
+	everyone := watchedPropagation("default", "everyone", source, &core.LabelSelector{})
+	notSandbox := watchedPropagation("default", "not-sandbox", source, &core.LabelSelector{MatchExpressions: []core.LabelSelectorReq{{Key: "sandbox", Operator: "DoesNotExist"}}})
+	templated := watchedPropagation("default", "templated", source, &core.LabelSelector{MatchLabels: map[string]string{"team": "search"}})
+	templated.Spec.Template = &core.TemplateSpec{Enabled: true}
+
+	controller := &ConfigPropagationController{Client: buildIndexedClient(t, payments, search, everyone, notSandbox, templated), logger: logr.Discard()}
+	eventHandler := controller.namespaceEventHandler()
+	queue := workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter())
+	defer queue.ShutDown()
+
+	created := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "pay-1", Labels: map[string]string{"team": "payments"}}}
+	eventHandler.Create(context.Background(), event.CreateEvent{Object: created}, queue)
+
+	want := []string{"default/everyone", "default/not-sandbox", "default/payments"}
+	if got := requestNames(t, queue); !reflect.DeepEqual(got, want) {
+		t.Fatalf("create: want %v got %v", want, got)
+	}
+
+	// Moving a namespace between teams must reconcile both the old and new selections.
+	moved := created.DeepCopy()
+	moved.Labels = map[string]string{"team": "search", "sandbox": "true"}
+	eventHandler.Update(context.Background(), event.UpdateEvent{ObjectOld: created, ObjectNew: moved}, queue)
+
+	want = []string{"default/everyone", "default/not-sandbox", "default/payments", "default/search", "default/templated"}
+	if got := requestNames(t, queue); !reflect.DeepEqual(got, want) {
+		t.Fatalf("update: want %v got %v", want, got)
+	}
+
+	// Annotation changes only reach templated ConfigPropagations selecting the namespace.
+	annotated := moved.DeepCopy()
+	annotated.Annotations = map[string]string{"note": "x"}
+	eventHandler.Update(context.Background(), event.UpdateEvent{ObjectOld: moved, ObjectNew: annotated}, queue)
+	want = []string{"default/templated"}
+	if got := requestNames(t, queue); !reflect.DeepEqual(got, want) {
+		t.Fatalf("annotation update: want %v got %v", want, got)
+	}
+
+	// Updates that leave labels and annotations untouched are ignored.
+	eventHandler.Update(context.Background(), event.UpdateEvent{ObjectOld: annotated, ObjectNew: annotated.DeepCopy()}, queue)
+	if queue.Len() != 0 {
+		t.Fatalf("expected no requests for a metadata-preserving update, got %d", queue.Len())
+	}
+
+	eventHandler.Delete(context.Background(), event.DeleteEvent{Object: moved}, queue)
+	want = []string{"default/everyone", "default/search", "default/templated"}
+	if got := requestNames(t, queue); !reflect.DeepEqual(got, want) {
+		t.Fatalf("delete: want %v got %v", want, got)
+	}
+}
+
+func TestRequestsForConfigMapFollowsOwnerAnnotation(t *testing.T) {
+	renamed := watchedPropagation("team-a", "renamed", core.ObjectRef{Namespace: "platform", Name: "base"}, &core.LabelSelector{})
+	controller := &ConfigPropagationController{Client: buildIndexedClient(t, renamed), logger: logr.Discard()}
+
+	target := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{
+		Namespace:   "tenant",
+		Name:        "app-config",
+		Labels:      map[string]string{core.ManagedLabel: "true"},
+		Annotations: map[string]string{core.OwnerAnnotation: "team-a/renamed"},
+	}}
+
+	requests := controller.requestsForConfigMap(context.Background(), target)
+	if len(requests) != 1 || requests[0].String() != "team-a/renamed" {
+		t.Fatalf("expected owner to be enqueued, got %+v", requests)
+	}
+}
+
+func TestRequestsForOverlaySource(t *testing.T) {
+	layered := watchedPropagation("team-a", "layered", core.ObjectRef{Namespace: "org", Name: "base"}, &core.LabelSelector{})
+	layered.Spec.Sources = []core.SourceSpec{{Namespace: "env", Name: "prod"}}
+
+	if got := sourceRefIndexValues(layered); !reflect.DeepEqual(got, []string{"org/base", "env/prod"}) {
+		t.Fatalf("expected every layer to be indexed, got %v", got)
+	}
+
+	controller := &ConfigPropagationController{Client: buildIndexedClient(t, layered), logger: logr.Discard()}
+
+	overlay := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "env", Name: "prod"}}
+	requests := controller.requestsForConfigMap(context.Background(), overlay)
+	if len(requests) != 1 || requests[0].String() != "team-a/layered" {
+		t.Fatalf("expected overlay edit to enqueue team-a/layered, got %+v", requests)
+	}
+}
@@ -0,0 +1,90 @@
+package configpropagation
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"configpropagation/pkg/core"
+)
+
+// slowClient is a memoryKubeClient whose writes take a while and fail for chosen namespaces,
+// recording how many writes were in flight at once.
+type slowClient struct {
+	*memoryKubeClient
+	mutex      sync.Mutex
+	failing    map[string]bool
+	inFlight   int
+	peakWrites int
+}
+
+func (client *slowClient) UpsertConfigMap(requestContext context.Context, namespace, name string, data map[string]string, binaryData map[string][]byte, labels, annotations map[string]string) error {
+	client.mutex.Lock()
+	client.inFlight++
+	if client.inFlight > client.peakWrites {
+		client.peakWrites = client.inFlight
+	}
+	client.mutex.Unlock()
+
+	time.Sleep(2 * time.Millisecond)
+
+	client.mutex.Lock()
+	client.inFlight--
+	client.mutex.Unlock()
+
+	if client.failing[namespace] {
+		return fmt.Errorf("write to %s refused", namespace)
+	}
+
+	return client.memoryKubeClient.UpsertConfigMap(requestContext, namespace, name, data, binaryData, labels, annotations)
+}
+
+func TestSyncTargetsWritesConcurrentlyInPlannedOrder(t *testing.T) {
+	var namespaces []string
+	targetNames := map[string]string{}
+	for index := 0; index < 24; index++ {
+		namespace := fmt.Sprintf("team-%02d", index)
+		namespaces = append(namespaces, namespace)
+		targetNames[namespace] = "app"
+	}
+
+	client := &slowClient{memoryKubeClient: newMemoryKubeClient(namespaces...), failing: map[string]bool{"team-03": true, "team-17": true}}
+	metricsRecorder := newCapturingMetricsRecorder()
+	eventRecorder := &capturingEventRecorder{}
+
+	reconciler := NewReconciler(client, eventRecorder, metricsRecorder)
+	reconciler.targetWorkers = 6
+
+	content := desiredContent{data: map[string]string{"k": "v"}, hash: core.HashData(map[string]string{"k": "v"}, nil)}
+	outcome := reconciler.syncTargets(context.Background(), Key{Namespace: "default", Name: "cp"}, namespaces, targetNames, content, core.ObjectRef{Namespace: "platform", Name: "app"}, core.ConflictOverwrite)
+
+	var wantCompleted []string
+	for _, namespace := range namespaces {
+		if !client.failing[namespace] {
+			wantCompleted = append(wantCompleted, namespace)
+		}
+	}
+
+	if !reflect.DeepEqual(outcome.completed, wantCompleted) {
+		t.Fatalf("expected completions in planned order, got %v", outcome.completed)
+	}
+
+	if len(outcome.outOfSync) != 2 || outcome.outOfSync[0].Namespace != "team-03" || outcome.outOfSync[1].Namespace != "team-17" {
+		t.Fatalf("expected failures in planned order, got %+v", outcome.outOfSync)
+	}
+
+	if outcome.retryAfter <= 0 {
+		t.Fatalf("expected the failed targets to schedule a retry")
+	}
+
+	if client.peakWrites < 2 || client.peakWrites > reconciler.targetWorkers {
+		t.Fatalf("expected between 2 and %d concurrent writes, saw %d", reconciler.targetWorkers, client.peakWrites)
+	}
+
+	if metricsRecorder.counts["create"] != len(wantCompleted) || metricsRecorder.errors["upsert"] != 2 {
+		t.Fatalf("unexpected metrics %+v %+v", metricsRecorder.counts, metricsRecorder.errors)
+	}
+}
@@ -0,0 +1,147 @@
+package core
+
+import (
+	"math/rand"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	defaultRetryBase = 500 * time.Millisecond
+	defaultRetryMax  = 5 * time.Minute
+)
+
+// BackoffState describes the retry state of a single failing item.
+type BackoffState struct {
+	Failures  int
+	NextRetry time.Time
+	Remaining time.Duration
+	Detail    string
+}
+
+// BackoffTracker tracks consecutive failures per item and spaces retries with capped exponential backoff.
+type BackoffTracker[K comparable] struct {
+	mutex   sync.Mutex
+	base    time.Duration
+	max     time.Duration
+	now     func() time.Time
+	random  func() float64
+	entries map[K]*backoffEntry
+}
+
+type backoffEntry struct {
+	failures  int
+	nextRetry time.Time
+	detail    string
+}
+
+// NewBackoffTracker constructs a tracker with the provided bounds.
+// A nil clock defaults to time.Now and a nil random source defaults to math/rand.
+func NewBackoffTracker[K comparable](base, max time.Duration, now func() time.Time, random func() float64) *BackoffTracker[K] {
+	if base <= 0 {
+		base = defaultRetryBase
+	}
+
+	if max < base {
+		max = base
+	}
+
+	if now == nil {
+		now = time.Now
+	}
+
+	if random == nil {
+		random = rand.Float64
+	}
+
+	return &BackoffTracker[K]{base: base, max: max, now: now, random: random, entries: map[K]*backoffEntry{}}
+}
+
+// DefaultRetryBounds reads the backoff bounds from RETRY_BASE_MS and RETRY_MAX_MS.
+func DefaultRetryBounds() (time.Duration, time.Duration) {
+	base := millisecondsFromEnv("RETRY_BASE_MS", defaultRetryBase)
+	max := millisecondsFromEnv("RETRY_MAX_MS", defaultRetryMax)
+
+	if max < base {
+		max = base
+	}
+
+	return base, max
+}
+
+// millisecondsFromEnv parses a positive millisecond duration from the environment.
+func millisecondsFromEnv(name string, fallback time.Duration) time.Duration {
+	if environmentValue := os.Getenv(name); environmentValue != "" {
+		if parsed, err := strconv.Atoi(environmentValue); err == nil && parsed >= 1 {
+			return time.Duration(parsed) * time.Millisecond
+		}
+	}
+
+	return fallback
+}
+
+// Waiting reports the backoff state of an item that must not be retried yet.
+func (tracker *BackoffTracker[K]) Waiting(item K) (BackoffState, bool) {
+	tracker.mutex.Lock()
+	defer tracker.mutex.Unlock()
+
+	entry, exists := tracker.entries[item]
+	if !exists {
+		return BackoffState{}, false
+	}
+
+	remaining := entry.nextRetry.Sub(tracker.now())
+	if remaining <= 0 {
+		return BackoffState{}, false
+	}
+
+	return BackoffState{Failures: entry.failures, NextRetry: entry.nextRetry, Remaining: remaining, Detail: entry.detail}, true
+}
+
+// Failure records a failed attempt and schedules the next retry.
+// The delay doubles per consecutive failure up to the maximum, with jitter over its upper half.
+func (tracker *BackoffTracker[K]) Failure(item K, detail string) BackoffState {
+	tracker.mutex.Lock()
+	defer tracker.mutex.Unlock()
+
+	entry, exists := tracker.entries[item]
+	if !exists {
+		entry = &backoffEntry{}
+		tracker.entries[item] = entry
+	}
+
+	entry.failures++
+	entry.detail = detail
+
+	delay := tracker.max
+	if shift := entry.failures - 1; shift < 32 && tracker.base<<shift > 0 && tracker.base<<shift < tracker.max {
+		delay = tracker.base << shift
+	}
+
+	delay = delay/2 + time.Duration(tracker.random()*float64(delay/2))
+	entry.nextRetry = tracker.now().Add(delay)
+
+	return BackoffState{Failures: entry.failures, NextRetry: entry.nextRetry, Remaining: delay, Detail: detail}
+}
+
+// Success clears any failure history for the item.
+func (tracker *BackoffTracker[K]) Success(item K) {
+	tracker.mutex.Lock()
+	defer tracker.mutex.Unlock()
+
+	delete(tracker.entries, item)
+}
+
+// Retain drops the history of every item for which keep returns false.
+func (tracker *BackoffTracker[K]) Retain(keep func(K) bool) {
+	tracker.mutex.Lock()
+	defer tracker.mutex.Unlock()
+
+	for item := range tracker.entries {
+		if !keep(item) {
+			delete(tracker.entries, item)
+		}
+	}
+}
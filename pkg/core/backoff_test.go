@@ -0,0 +1,88 @@
+package core_test
+
+import (
+	"testing"
+	"time"
+
+	core "configpropagation/pkg/core"
+)
+
+func TestBackoffTrackerDoublesUpToMax(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := func() time.Time { return now }
+	noJitter := func() float64 { return 1 }
+
+	tracker := core.NewBackoffTracker[string](time.Second, 5*time.Second, clock, noJitter)
+
+	if _, waiting := tracker.Waiting("ns"); waiting {
+		t.Fatalf("expected unknown item not to be backing off")
+	}
+
+	for attempt, want := range []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second} {
+		state := tracker.Failure("ns", "Forbidden")
+		if state.Remaining != want || state.Failures != attempt+1 || !state.NextRetry.Equal(now.Add(want)) {
+			t.Fatalf("attempt %d: want delay %v, got %+v", attempt+1, want, state)
+		}
+	}
+
+	state, waiting := tracker.Waiting("ns")
+	if !waiting || state.Detail != "Forbidden" || state.Remaining != 5*time.Second {
+		t.Fatalf("expected item to be backing off, got %+v %v", state, waiting)
+	}
+
+	now = now.Add(5 * time.Second)
+	if _, waiting := tracker.Waiting("ns"); waiting {
+		t.Fatalf("expected item to be retryable once the delay elapsed")
+	}
+
+	tracker.Success("ns")
+	if state := tracker.Failure("ns", "TransientError"); state.Remaining != time.Second {
+		t.Fatalf("expected success to reset the delay, got %+v", state)
+	}
+}
+
+func TestBackoffTrackerJitterStaysWithinUpperHalf(t *testing.T) {
+	now := time.Now()
+	noJitter := core.NewBackoffTracker[string](4*time.Second, time.Minute, func() time.Time { return now }, func() float64 { return 0 })
+	if state := noJitter.Failure("a", ""); state.Remaining != 2*time.Second {
+		t.Fatalf("expected minimum jitter to halve the delay, got %v", state.Remaining)
+	}
+
+	halfJitter := core.NewBackoffTracker[string](4*time.Second, time.Minute, func() time.Time { return now }, func() float64 { return 0.5 })
+	if state := halfJitter.Failure("a", ""); state.Remaining != 3*time.Second {
+		t.Fatalf("expected jitter to spread the delay, got %v", state.Remaining)
+	}
+}
+
+func TestBackoffTrackerRetain(t *testing.T) {
+	tracker := core.NewBackoffTracker[string](time.Minute, time.Hour, nil, nil)
+	tracker.Failure("keep", "")
+	tracker.Failure("drop", "")
+
+	tracker.Retain(func(item string) bool { return item == "keep" })
+
+	if _, waiting := tracker.Waiting("keep"); !waiting {
+		t.Fatalf("expected retained item to keep its backoff")
+	}
+	if _, waiting := tracker.Waiting("drop"); waiting {
+		t.Fatalf("expected dropped item to be forgotten")
+	}
+}
+
+func TestDefaultRetryBoundsFromEnv(t *testing.T) {
+	t.Setenv("RETRY_BASE_MS", "250")
+	t.Setenv("RETRY_MAX_MS", "10000")
+
+	base, max := core.DefaultRetryBounds()
+	if base != 250*time.Millisecond || max != 10*time.Second {
+		t.Fatalf("unexpected bounds %v %v", base, max)
+	}
+
+	t.Setenv("RETRY_BASE_MS", "invalid")
+	t.Setenv("RETRY_MAX_MS", "1")
+
+	base, max = core.DefaultRetryBounds()
+	if base != 500*time.Millisecond || max != base {
+		t.Fatalf("expected fallback base and max clamped to base, got %v %v", base, max)
+	}
+}
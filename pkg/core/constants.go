@@ -5,6 +5,26 @@ const (
 	ManagedLabel     = "configpropagator.platform.example.com/managed"
 	SourceAnnotation = "configpropagator.platform.example.com/source"
 	HashAnnotation   = "configpropagator.platform.example.com/hash"
+	OwnerAnnotation  = "configpropagator.platform.example.com/owner"
+
+	// OwnerUIDAnnotation records the UID of the owning ConfigPropagation, so a recreated CR with the same name
+	// does not claim targets of its predecessor. Cross-namespace ownerReferences are not allowed, hence an annotation.
+	OwnerUIDAnnotation = "configpropagator.platform.example.com/owner-uid"
+
+	// Multi-source targets record their contributing sources and which source each key came from.
+	SourcesAnnotation    = "configpropagator.platform.example.com/sources"
+	KeySourcesAnnotation = "configpropagator.platform.example.com/key-sources"
+
+	// OwnedMetadataAnnotation records which label and annotation keys the controller wrote, so updates leave other keys alone.
+	OwnedMetadataAnnotation = "configpropagator.platform.example.com/owned-metadata"
+
+	// SourceHashLabel carries SourceLabelValue of the source, so targets of one source can be selected by label.
+	SourceHashLabel = "configpropagator.platform.example.com/source-hash"
+	// OwnerHashLabel carries SourceLabelValue of the owning ConfigPropagation's namespace/name.
+	OwnerHashLabel = "configpropagator.platform.example.com/owner-hash"
+
+	// AllowFromNamespacesAnnotation on a source ConfigMap lists the namespaces whose ConfigPropagations may read it.
+	AllowFromNamespacesAnnotation = "configpropagator.platform.example.com/allow-from-namespaces"
 
 	Finalizer = "configpropagator.platform.example.com/finalizer"
 )
@@ -14,6 +34,9 @@ const (
 	CondReady       = "Ready"
 	CondProgressing = "Progressing"
 	CondDegraded    = "Degraded"
+
+	// CondSourceNotShareable is set while a source has not opted in to being read from the ConfigPropagation's namespace.
+	CondSourceNotShareable = "SourceNotShareable"
 )
 
 // Strategy enums
@@ -22,8 +45,65 @@ const (
 	StrategyRolling   = "rolling"
 )
 
+// Planned target actions reported by a dry run
+const (
+	PlanCreate = "Create"
+	PlanUpdate = "Update"
+	PlanPrune  = "Prune"
+	PlanDetach = "Detach"
+	PlanSkip   = "Skip"
+)
+
+// Merge mode enums for key collisions between sources
+const (
+	MergeOverride = "override"
+	MergeReject   = "reject"
+)
+
 // Conflict policy enums
 const (
 	ConflictOverwrite = "overwrite"
 	ConflictSkip      = "skip"
 )
+
+// ReasonPendingRollout reports a namespace waiting for a later batch of a rolling rollout.
+const ReasonPendingRollout = "PendingRollout"
+
+// ReasonPendingSync reports a namespace an immediate rollout did not synchronize in this reconcile.
+const ReasonPendingSync = "PendingSync"
+
+// ReasonConflictPolicySkip reports an existing unmanaged target left alone under conflictPolicy=skip.
+const ReasonConflictPolicySkip = "ConflictPolicySkip"
+
+// ReasonDrifted reports a managed target whose data was edited in place since the controller last wrote it.
+const ReasonDrifted = "Drifted"
+
+// ReasonPaused is the Progressing condition reason while spec.paused stops target writes.
+const ReasonPaused = "Paused"
+
+// ReasonFieldManagerConflict reports a target left alone under conflictPolicy=skip because
+// server-side apply found fields owned by another field manager.
+const ReasonFieldManagerConflict = "FieldManagerConflict"
+
+// Out-of-sync reasons for targets whose last write or lookup failed.
+const (
+	ReasonForbidden         = "Forbidden"
+	ReasonNamespaceNotFound = "NamespaceNotFound"
+	ReasonConflict          = "Conflict"
+	ReasonTooLarge          = "TooLarge"
+	ReasonTransientError    = "TransientError"
+	ReasonBackingOff        = "BackingOff"
+	ReasonInvalidTargetName = "InvalidTargetName"
+	ReasonTemplateError     = "TemplateError"
+	ReasonOwnedByOther      = "OwnedByOther"
+)
+
+// IsFailureReason reports whether an out-of-sync reason describes a failed target rather than pending work.
+func IsFailureReason(reason string) bool {
+	switch reason {
+	case ReasonForbidden, ReasonNamespaceNotFound, ReasonConflict, ReasonTooLarge, ReasonTransientError, ReasonBackingOff, ReasonInvalidTargetName, ReasonTemplateError, ReasonOwnedByOther:
+		return true
+	default:
+		return false
+	}
+}
@@ -19,3 +19,16 @@ func TestConstantsStability(t *testing.T) {
 		t.Fatalf("Finalizer changed: %s", core.Finalizer)
 	}
 }
+
+func TestIsFailureReason(t *testing.T) {
+	for _, reason := range []string{core.ReasonForbidden, core.ReasonNamespaceNotFound, core.ReasonConflict, core.ReasonTooLarge, core.ReasonTransientError, core.ReasonBackingOff} {
+		if !core.IsFailureReason(reason) {
+			t.Fatalf("expected %s to be a failure reason", reason)
+		}
+	}
+	for _, reason := range []string{core.ReasonPendingRollout, core.ReasonPendingSync, core.ReasonConflictPolicySkip, core.ReasonDrifted} {
+		if core.IsFailureReason(reason) {
+			t.Fatalf("expected %s not to be a failure reason", reason)
+		}
+	}
+}
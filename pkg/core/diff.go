@@ -0,0 +1,67 @@
+package core
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+)
+
+// Kinds of key differences between the propagated data and a target.
+const (
+	DiffMissing = "Missing" // propagated key absent from the target
+	DiffChanged = "Changed" // target value differs from the propagated value
+	DiffExtra   = "Extra"   // target key that is not propagated
+)
+
+// KeyDiff is one key whose target value differs from the propagated value.
+type KeyDiff struct {
+	Key     string
+	Change  string // Missing|Changed|Extra
+	Desired string // binaryData values are described by their size
+	Live    string
+}
+
+// DiffData compares the live data of a target with the data that would be propagated to it and returns the
+// differing keys in key order. data and binaryData are compared separately, as they are written.
+func DiffData(liveData map[string]string, liveBinaryData map[string][]byte, data map[string]string, binaryData map[string][]byte) []KeyDiff {
+	var differences []KeyDiff
+
+	for dataKey, value := range data {
+		if liveValue, exists := liveData[dataKey]; !exists {
+			differences = append(differences, KeyDiff{Key: dataKey, Change: DiffMissing, Desired: value})
+		} else if liveValue != value {
+			differences = append(differences, KeyDiff{Key: dataKey, Change: DiffChanged, Desired: value, Live: liveValue})
+		}
+	}
+
+	for dataKey, liveValue := range liveData {
+		if _, desired := data[dataKey]; !desired {
+			differences = append(differences, KeyDiff{Key: dataKey, Change: DiffExtra, Live: liveValue})
+		}
+	}
+
+	for dataKey, value := range binaryData {
+		if liveValue, exists := liveBinaryData[dataKey]; !exists {
+			differences = append(differences, KeyDiff{Key: dataKey, Change: DiffMissing, Desired: describeBinary(value)})
+		} else if !bytes.Equal(liveValue, value) {
+			differences = append(differences, KeyDiff{Key: dataKey, Change: DiffChanged, Desired: describeBinary(value), Live: describeBinary(liveValue)})
+		}
+	}
+
+	for dataKey, liveValue := range liveBinaryData {
+		if _, desired := binaryData[dataKey]; !desired {
+			differences = append(differences, KeyDiff{Key: dataKey, Change: DiffExtra, Live: describeBinary(liveValue)})
+		}
+	}
+
+	sort.SliceStable(differences, func(left, right int) bool {
+		return differences[left].Key < differences[right].Key
+	})
+
+	return differences
+}
+
+// describeBinary stands in for a binaryData value in a KeyDiff.
+func describeBinary(value []byte) string {
+	return fmt.Sprintf("<%d bytes of binaryData>", len(value))
+}
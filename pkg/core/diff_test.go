@@ -0,0 +1,31 @@
+package core_test
+
+import (
+	"reflect"
+	"testing"
+
+	core "configpropagation/pkg/core"
+)
+
+func TestDiffData(t *testing.T) {
+	differences := core.DiffData(
+		map[string]string{"level": "debug", "same": "x", "local": "1"},
+		map[string][]byte{"cert": []byte("old")},
+		map[string]string{"level": "info", "same": "x", "added": "y"},
+		map[string][]byte{"cert": []byte("new!")},
+	)
+
+	want := []core.KeyDiff{
+		{Key: "added", Change: core.DiffMissing, Desired: "y"},
+		{Key: "cert", Change: core.DiffChanged, Desired: "<4 bytes of binaryData>", Live: "<3 bytes of binaryData>"},
+		{Key: "level", Change: core.DiffChanged, Desired: "info", Live: "debug"},
+		{Key: "local", Change: core.DiffExtra, Live: "1"},
+	}
+	if !reflect.DeepEqual(differences, want) {
+		t.Fatalf("unexpected differences:\n got %+v\nwant %+v", differences, want)
+	}
+
+	if core.DiffData(map[string]string{"a": "b"}, nil, map[string]string{"a": "b"}, nil) != nil {
+		t.Fatalf("expected equal data to have no differences")
+	}
+}
@@ -2,14 +2,51 @@ package core
 
 import (
 	"crypto/sha256"
+	"encoding/binary"
 	"encoding/hex"
+	"io"
 	"sort"
 	"strings"
 )
 
-// HashData computes a stable sha256 hash of the string data map.
+// binaryDataDomain prefixes the combined digest so text and binary content never share a preimage.
+const binaryDataDomain = "configpropagator/binaryData/v1"
+
+// HashData computes a stable sha256 hash of the ConfigMap data and binaryData maps.
 // Keys are sorted and joined as key\u0000value pairs to avoid JSON map nondeterminism.
-func HashData(data map[string]string) string {
+// Content without binaryData hashes exactly as text-only content always has, so existing targets keep their hash.
+func HashData(data map[string]string, binaryData map[string][]byte) string {
+	textHash := hashStringData(data)
+	if len(binaryData) == 0 {
+		return textHash
+	}
+
+	// Combine both section digests under a domain tag; binary entries are length-prefixed so arbitrary bytes cannot collide.
+	keys := make([]string, 0, len(binaryData))
+
+	for key := range binaryData {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	binaryHasher := sha256.New()
+
+	for _, key := range keys {
+		writeLengthPrefixed(binaryHasher, []byte(key))
+		writeLengthPrefixed(binaryHasher, binaryData[key])
+	}
+
+	combinedHasher := sha256.New()
+	writeLengthPrefixed(combinedHasher, []byte(binaryDataDomain))
+	writeLengthPrefixed(combinedHasher, []byte(textHash))
+	writeLengthPrefixed(combinedHasher, binaryHasher.Sum(nil))
+
+	return hex.EncodeToString(combinedHasher.Sum(nil))
+}
+
+// hashStringData computes the text-only digest used by HashData.
+func hashStringData(data map[string]string) string {
 	if len(data) == 0 {
 		return ""
 	}
@@ -35,3 +72,22 @@ func HashData(data map[string]string) string {
 
 	return hex.EncodeToString(hashSum[:])
 }
+
+// sourceLabelValueLength keeps SourceLabelValue within the 63 character limit of label values.
+const sourceLabelValueLength = 40
+
+// SourceLabelValue hashes a namespace/name identifier of a source or owner into a valid label value.
+func SourceLabelValue(source string) string {
+	hashSum := sha256.Sum256([]byte(source))
+
+	return hex.EncodeToString(hashSum[:])[:sourceLabelValueLength]
+}
+
+// writeLengthPrefixed writes the value preceded by its big-endian length.
+func writeLengthPrefixed(writer io.Writer, value []byte) {
+	var length [8]byte
+	binary.BigEndian.PutUint64(length[:], uint64(len(value)))
+
+	_, _ = writer.Write(length[:])
+	_, _ = writer.Write(value)
+}
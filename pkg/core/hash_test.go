@@ -9,8 +9,8 @@ func TestHashDataDeterministicAndOrderIndependent(t *testing.T) {
 	firstDataSet := map[string]string{"a": "1", "b": "2", "c": "3"}
 	secondDataSet := map[string]string{"c": "3", "b": "2", "a": "1"}
 
-	firstHash := core.HashData(firstDataSet)
-	secondHash := core.HashData(secondDataSet)
+	firstHash := core.HashData(firstDataSet, nil)
+	secondHash := core.HashData(secondDataSet, nil)
 
 	if firstHash == "" {
 		t.Fatalf("hash should not be empty for non-empty data")
@@ -22,11 +22,55 @@ func TestHashDataDeterministicAndOrderIndependent(t *testing.T) {
 }
 
 func TestHashDataEmpty(t *testing.T) {
-	if hashValue := core.HashData(nil); hashValue != "" {
+	if hashValue := core.HashData(nil, nil); hashValue != "" {
 		t.Fatalf("expected empty hash for nil, got %q", hashValue)
 	}
 
-	if hashValue := core.HashData(map[string]string{}); hashValue != "" {
+	if hashValue := core.HashData(map[string]string{}, map[string][]byte{}); hashValue != "" {
 		t.Fatalf("expected empty hash for empty, got %q", hashValue)
 	}
 }
+
+func TestHashDataTextOnlyIsBackwardCompatible(t *testing.T) {
+	// Digest of "a\x001\n" as produced before binaryData was hashed; existing targets carry it.
+	const legacyHash = "ce3581a167a59bafe03430a337ef286d228406063a29b5d5d62d1700897f22db"
+
+	if hashValue := core.HashData(map[string]string{"a": "1"}, nil); hashValue != legacyHash {
+		t.Fatalf("text-only hash changed: %s", hashValue)
+	}
+}
+
+func TestHashDataCoversBinaryData(t *testing.T) {
+	text := map[string]string{"a": "1"}
+	textHash := core.HashData(text, nil)
+
+	withBinary := core.HashData(text, map[string][]byte{"cert.der": {0x30, 0x82}})
+	if withBinary == textHash || withBinary == "" {
+		t.Fatalf("binaryData must change the hash")
+	}
+
+	if reordered := core.HashData(map[string]string{"a": "1"}, map[string][]byte{"cert.der": {0x30, 0x82}}); reordered != withBinary {
+		t.Fatalf("hash must be stable for identical content")
+	}
+
+	if changed := core.HashData(text, map[string][]byte{"cert.der": {0x30, 0x83}}); changed == withBinary {
+		t.Fatalf("binary value changes must change the hash")
+	}
+
+	// The same key/value moved between data and binaryData is different content.
+	if core.HashData(map[string]string{"k": "v"}, nil) == core.HashData(nil, map[string][]byte{"k": []byte("v")}) {
+		t.Fatalf("data and binaryData must be domain separated")
+	}
+
+	// Entries are length-prefixed so shifting bytes between key and value is detected.
+	if core.HashData(nil, map[string][]byte{"ab": []byte("c")}) == core.HashData(nil, map[string][]byte{"a": []byte("bc")}) {
+		t.Fatalf("binary entries must not be ambiguous")
+	}
+}
+
+func TestSourceLabelValue(t *testing.T) {
+	value := core.SourceLabelValue("platform/app")
+	if len(value) > 63 || value != core.SourceLabelValue("platform/app") || value == core.SourceLabelValue("platform/app2") {
+		t.Fatalf("expected a stable, distinct label value within 63 characters, got %q", value)
+	}
+}
@@ -0,0 +1,211 @@
+package core
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// maxConfigMapKeyLength is the Kubernetes limit for ConfigMap keys.
+const maxConfigMapKeyLength = 253
+
+var configMapKeyPattern = regexp.MustCompile(`^[-._a-zA-Z0-9]+$`)
+
+// KeyMapper renames keys according to the first matching mapping of a spec.
+type KeyMapper struct {
+	mappings []KeyMapping
+	patterns []*regexp.Regexp
+}
+
+// NewKeyMapper compiles the key mappings once so they can be applied to every source layer.
+func NewKeyMapper(mappings []KeyMapping) (*KeyMapper, error) {
+	mapper := &KeyMapper{mappings: mappings, patterns: make([]*regexp.Regexp, len(mappings))}
+
+	for index, mapping := range mappings {
+		if mapping.Regex == "" {
+			continue
+		}
+
+		// Anchor the expression so a rule only applies to keys it matches entirely.
+		pattern, err := regexp.Compile("^(?:" + mapping.Regex + ")$")
+		if err != nil {
+			return nil, fmt.Errorf("keyMappings[%d].regex: %w", index, err)
+		}
+
+		mapper.patterns[index] = pattern
+	}
+
+	return mapper, nil
+}
+
+// Map returns the target key for a source key. Keys matched by no mapping keep their name.
+func (mapper *KeyMapper) Map(sourceKey string) string {
+	for index, mapping := range mapper.mappings {
+		switch {
+		case mapping.From != "":
+			if sourceKey == mapping.From {
+				return mapping.To
+			}
+		case mapper.patterns[index] != nil:
+			if mapper.patterns[index].MatchString(sourceKey) {
+				return mapper.patterns[index].ReplaceAllString(sourceKey, mapping.Replacement)
+			}
+		case mapping.StripPrefix != "" || mapping.AddPrefix != "":
+			if strings.HasPrefix(sourceKey, mapping.StripPrefix) {
+				return mapping.AddPrefix + strings.TrimPrefix(sourceKey, mapping.StripPrefix)
+			}
+		}
+	}
+
+	return sourceKey
+}
+
+// Apply renames every key of the data and binaryData maps.
+// Two source keys mapped to the same target key, or a target key that is not a valid ConfigMap key, is an error.
+// A nil mapper leaves the content untouched.
+func (mapper *KeyMapper) Apply(data map[string]string, binaryData map[string][]byte) (map[string]string, map[string][]byte, error) {
+	if mapper == nil || len(mapper.mappings) == 0 {
+		return data, binaryData, nil
+	}
+
+	mappedFrom := map[string]string{}
+	mapKey := func(sourceKey string) (string, error) {
+		targetKey := mapper.Map(sourceKey)
+
+		if err := ValidateConfigMapKey(targetKey); err != nil {
+			return "", fmt.Errorf("key %q mapped to %q: %w", sourceKey, targetKey, err)
+		}
+
+		if previousKey, taken := mappedFrom[targetKey]; taken {
+			return "", fmt.Errorf("keys %q and %q both map to %q", previousKey, sourceKey, targetKey)
+		}
+
+		mappedFrom[targetKey] = sourceKey
+		return targetKey, nil
+	}
+
+	mappedData := make(map[string]string, len(data))
+	for _, sourceKey := range sortedKeys(data) {
+		targetKey, err := mapKey(sourceKey)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		mappedData[targetKey] = data[sourceKey]
+	}
+
+	mappedBinaryData := make(map[string][]byte, len(binaryData))
+	for _, sourceKey := range sortedKeys(binaryData) {
+		targetKey, err := mapKey(sourceKey)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		mappedBinaryData[targetKey] = binaryData[sourceKey]
+	}
+
+	return mappedData, mappedBinaryData, nil
+}
+
+// ValidateConfigMapKey checks a key against the Kubernetes ConfigMap key rules.
+func ValidateConfigMapKey(key string) error {
+	if key == "" {
+		return fmt.Errorf("key must not be empty")
+	}
+
+	if len(key) > maxConfigMapKeyLength {
+		return fmt.Errorf("key must be no more than %d characters", maxConfigMapKeyLength)
+	}
+
+	if key == "." || key == ".." {
+		return fmt.Errorf("key must not be '.' or '..'")
+	}
+
+	if !configMapKeyPattern.MatchString(key) {
+		return fmt.Errorf("key must consist of alphanumeric characters, '-', '_' or '.'")
+	}
+
+	return nil
+}
+
+// validateKeyMappings checks that every mapping sets one rule, that regexes compile,
+// and that static outputs are valid keys which no two mappings share.
+func validateKeyMappings(mappings []KeyMapping) error {
+	if _, err := NewKeyMapper(mappings); err != nil {
+		return err
+	}
+
+	exactSources := map[string]int{}
+	exactTargets := map[string]int{}
+	prefixTargets := map[string]int{}
+
+	for index, mapping := range mappings {
+		rules := 0
+		if mapping.From != "" || mapping.To != "" {
+			rules++
+		}
+		if mapping.StripPrefix != "" || mapping.AddPrefix != "" {
+			rules++
+		}
+		if mapping.Regex != "" || mapping.Replacement != "" {
+			rules++
+		}
+
+		if rules != 1 {
+			return fmt.Errorf("keyMappings[%d] must set exactly one of from/to, stripPrefix/addPrefix or regex/replacement", index)
+		}
+
+		switch {
+		case mapping.From != "" || mapping.To != "":
+			if err := ValidateConfigMapKey(mapping.From); err != nil {
+				return fmt.Errorf("keyMappings[%d].from: %w", index, err)
+			}
+
+			if err := ValidateConfigMapKey(mapping.To); err != nil {
+				return fmt.Errorf("keyMappings[%d].to: %w", index, err)
+			}
+
+			if previous, duplicate := exactSources[mapping.From]; duplicate {
+				return fmt.Errorf("keyMappings[%d].from %q is already mapped by keyMappings[%d]", index, mapping.From, previous)
+			}
+
+			if previous, duplicate := exactTargets[mapping.To]; duplicate {
+				return fmt.Errorf("keyMappings[%d] and keyMappings[%d] both produce %q", previous, index, mapping.To)
+			}
+
+			exactSources[mapping.From] = index
+			exactTargets[mapping.To] = index
+		case mapping.StripPrefix != "" || mapping.AddPrefix != "":
+			for _, prefix := range []string{mapping.StripPrefix, mapping.AddPrefix} {
+				if prefix != "" && !configMapKeyPattern.MatchString(prefix) {
+					return fmt.Errorf("keyMappings[%d]: prefix %q must consist of alphanumeric characters, '-', '_' or '.'", index, prefix)
+				}
+			}
+
+			rewrite := mapping.StripPrefix + "\u0000" + mapping.AddPrefix
+			if previous, duplicate := prefixTargets[rewrite]; duplicate {
+				return fmt.Errorf("keyMappings[%d] duplicates keyMappings[%d]", index, previous)
+			}
+
+			prefixTargets[rewrite] = index
+		default:
+			if mapping.Regex == "" {
+				return fmt.Errorf("keyMappings[%d].regex is required with replacement", index)
+			}
+		}
+	}
+
+	return nil
+}
+
+// sortedKeys returns the keys of a map in sorted order so mapping errors are deterministic.
+func sortedKeys[V any](values map[string]V) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,106 @@
+package core_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	core "configpropagation/pkg/core"
+)
+
+func TestKeyMapperRules(t *testing.T) {
+	mapper, err := core.NewKeyMapper([]core.KeyMapping{
+		{From: "prod.properties", To: "application.properties"},
+		{StripPrefix: "prod-"},
+		{Regex: `(.+)\.yml`, Replacement: "${1}.yaml"},
+		{AddPrefix: "shared-"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	cases := map[string]string{
+		"prod.properties": "application.properties",
+		"prod-db.url":     "db.url",
+		"logging.yml":     "logging.yaml",
+		"banner.txt":      "shared-banner.txt",
+	}
+
+	for sourceKey, want := range cases {
+		if got := mapper.Map(sourceKey); got != want {
+			t.Fatalf("%s: want %q got %q", sourceKey, want, got)
+		}
+	}
+}
+
+func TestKeyMapperApply(t *testing.T) {
+	mapper, err := core.NewKeyMapper([]core.KeyMapping{{From: "prod.properties", To: "application.properties"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	data, binaryData, err := mapper.Apply(map[string]string{"prod.properties": "a=1", "other": "x"}, map[string][]byte{"bin": {0x01}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !reflect.DeepEqual(data, map[string]string{"application.properties": "a=1", "other": "x"}) || !reflect.DeepEqual(binaryData, map[string][]byte{"bin": {0x01}}) {
+		t.Fatalf("unexpected mapped content %+v %+v", data, binaryData)
+	}
+
+	var nilMapper *core.KeyMapper
+	if data, _, err := nilMapper.Apply(map[string]string{"a": "1"}, nil); err != nil || data["a"] != "1" {
+		t.Fatalf("expected nil mapper to pass content through, got %+v %v", data, err)
+	}
+}
+
+func TestKeyMapperApplyRejectsCollisionsAndInvalidKeys(t *testing.T) {
+	stripMapper, _ := core.NewKeyMapper([]core.KeyMapping{{StripPrefix: "prod-"}})
+	if _, _, err := stripMapper.Apply(map[string]string{"prod-url": "a"}, map[string][]byte{"url": {0x01}}); err == nil || !strings.Contains(err.Error(), `both map to "url"`) {
+		t.Fatalf("expected collision error, got %v", err)
+	}
+
+	regexMapper, _ := core.NewKeyMapper([]core.KeyMapping{{Regex: `(.+)`, Replacement: "bad/$1"}})
+	if _, _, err := regexMapper.Apply(map[string]string{"key": "a"}, nil); err == nil || !strings.Contains(err.Error(), `"bad/key"`) {
+		t.Fatalf("expected invalid key error, got %v", err)
+	}
+}
+
+func TestValidateSpecKeyMappings(t *testing.T) {
+	cases := map[string]struct {
+		mappings []core.KeyMapping
+		wantErr  string
+	}{
+		"valid":            {mappings: []core.KeyMapping{{From: "a", To: "b"}, {StripPrefix: "x-", AddPrefix: "y-"}, {Regex: `(.+)\.yml`, Replacement: "$1.yaml"}}},
+		"no rule":          {mappings: []core.KeyMapping{{}}, wantErr: "exactly one"},
+		"mixed rules":      {mappings: []core.KeyMapping{{From: "a", To: "b", AddPrefix: "x"}}, wantErr: "exactly one"},
+		"missing to":       {mappings: []core.KeyMapping{{From: "a"}}, wantErr: "keyMappings[0].to"},
+		"invalid to":       {mappings: []core.KeyMapping{{From: "a", To: "b/c"}}, wantErr: "keyMappings[0].to"},
+		"duplicate output": {mappings: []core.KeyMapping{{From: "a", To: "c"}, {From: "b", To: "c"}}, wantErr: `both produce "c"`},
+		"duplicate from":   {mappings: []core.KeyMapping{{From: "a", To: "b"}, {From: "a", To: "c"}}, wantErr: "already mapped"},
+		"invalid prefix":   {mappings: []core.KeyMapping{{AddPrefix: "a:"}}, wantErr: "prefix"},
+		"duplicate prefix": {mappings: []core.KeyMapping{{StripPrefix: "a"}, {StripPrefix: "a"}}, wantErr: "duplicates"},
+		"bad regex":        {mappings: []core.KeyMapping{{Regex: "(", Replacement: "x"}}, wantErr: "keyMappings[0].regex"},
+		"replacement only": {mappings: []core.KeyMapping{{Replacement: "x"}}, wantErr: "regex is required"},
+	}
+
+	for name, testCase := range cases {
+		spec := &core.ConfigPropagationSpec{
+			SourceRef:         core.ObjectRef{Namespace: "ns", Name: "cfg"},
+			NamespaceSelector: &core.LabelSelector{},
+			KeyMappings:       testCase.mappings,
+		}
+
+		err := core.ValidateSpec(spec)
+		if testCase.wantErr == "" {
+			if err != nil {
+				t.Fatalf("%s: unexpected error: %v", name, err)
+			}
+			continue
+		}
+
+		if err == nil || !strings.Contains(err.Error(), testCase.wantErr) {
+			t.Fatalf("%s: expected error containing %q, got %v", name, testCase.wantErr, err)
+		}
+	}
+}
@@ -0,0 +1,154 @@
+package core
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// KeySelector decides which source keys are propagated.
+// Entries are exact key names, glob patterns (*, ? and [...] as in path.Match) or regular expressions written as /expr/.
+// None of those metacharacters are valid in ConfigMap keys, so every entry has exactly one reading.
+type KeySelector struct {
+	include []keyPattern
+	exclude []keyPattern
+}
+
+// keyPattern is one compiled dataKeys or excludeKeys entry.
+type keyPattern struct {
+	exact string
+	glob  string
+	regex *regexp.Regexp
+}
+
+// NewKeySelector compiles the dataKeys and excludeKeys entries of a source layer.
+// An empty include list selects every key that is not excluded.
+func NewKeySelector(dataKeys, excludeKeys []string) (*KeySelector, error) {
+	include, err := compileKeyPatterns("dataKeys", dataKeys)
+	if err != nil {
+		return nil, err
+	}
+
+	exclude, err := compileKeyPatterns("excludeKeys", excludeKeys)
+	if err != nil {
+		return nil, err
+	}
+
+	return &KeySelector{include: include, exclude: exclude}, nil
+}
+
+// compileKeyPatterns parses the entries of one selection field.
+func compileKeyPatterns(field string, entries []string) ([]keyPattern, error) {
+	patterns := make([]keyPattern, 0, len(entries))
+
+	for index, entry := range entries {
+		switch {
+		case entry == "":
+			return nil, fmt.Errorf("%s[%d] must not be empty", field, index)
+		case len(entry) > 2 && strings.HasPrefix(entry, "/") && strings.HasSuffix(entry, "/"):
+			// Anchor the expression so /feature-.*/ does not also match keys that merely contain it.
+			expression, err := regexp.Compile("^(?:" + entry[1:len(entry)-1] + ")$")
+			if err != nil {
+				return nil, fmt.Errorf("%s[%d]: invalid regex %q: %w", field, index, entry, err)
+			}
+
+			patterns = append(patterns, keyPattern{regex: expression})
+		case strings.ContainsAny(entry, "*?["):
+			if _, err := path.Match(entry, ""); err != nil {
+				return nil, fmt.Errorf("%s[%d]: invalid glob %q: %w", field, index, entry, err)
+			}
+
+			patterns = append(patterns, keyPattern{glob: entry})
+		default:
+			if err := ValidateConfigMapKey(entry); err != nil {
+				return nil, fmt.Errorf("%s[%d]: %w", field, index, err)
+			}
+
+			patterns = append(patterns, keyPattern{exact: entry})
+		}
+	}
+
+	return patterns, nil
+}
+
+// matches reports whether the key satisfies the pattern.
+func (pattern keyPattern) matches(key string) bool {
+	switch {
+	case pattern.regex != nil:
+		return pattern.regex.MatchString(key)
+	case pattern.glob != "":
+		matched, _ := path.Match(pattern.glob, key)
+		return matched
+	default:
+		return pattern.exact == key
+	}
+}
+
+// Filtered reports whether the selector narrows the source at all.
+func (selector *KeySelector) Filtered() bool {
+	return len(selector.include) > 0 || len(selector.exclude) > 0
+}
+
+// Matches reports whether a source key is selected.
+func (selector *KeySelector) Matches(key string) bool {
+	for _, pattern := range selector.exclude {
+		if pattern.matches(key) {
+			return false
+		}
+	}
+
+	if len(selector.include) == 0 {
+		return true
+	}
+
+	for _, pattern := range selector.include {
+		if pattern.matches(key) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Select copies the selected keys of the data and binaryData maps and returns the sorted names of the matched keys.
+func (selector *KeySelector) Select(data map[string]string, binaryData map[string][]byte) (map[string]string, map[string][]byte, []string) {
+	selectedData := map[string]string{}
+	selectedBinaryData := map[string][]byte{}
+	matchedKeys := []string{}
+
+	for key, value := range data {
+		if selector.Matches(key) {
+			selectedData[key] = value
+			matchedKeys = append(matchedKeys, key)
+		}
+	}
+
+	for key, value := range binaryData {
+		if selector.Matches(key) {
+			selectedBinaryData[key] = value
+			if _, alsoText := data[key]; !alsoText {
+				matchedKeys = append(matchedKeys, key)
+			}
+		}
+	}
+
+	sort.Strings(matchedKeys)
+	return selectedData, selectedBinaryData, matchedKeys
+}
+
+// validateKeySelection checks the dataKeys and excludeKeys of sourceRef and every overlay source.
+func validateKeySelection(spec *ConfigPropagationSpec) error {
+	for index, layer := range SourceLayers(spec) {
+		if _, err := NewKeySelector(layer.DataKeys, layer.ExcludeKeys); err != nil {
+			if index == 0 {
+				return err
+			}
+
+			return fmt.Errorf("sources[%d].%w", index-1, err)
+		}
+	}
+
+	return nil
+}
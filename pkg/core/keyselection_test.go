@@ -0,0 +1,92 @@
+package core_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	core "configpropagation/pkg/core"
+)
+
+func TestKeySelectorPatterns(t *testing.T) {
+	selector, err := core.NewKeySelector([]string{"feature-*.yaml", "/app-(dev|prod)\\.properties/", "banner.txt"}, []string{"feature-legacy-?.yaml"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	cases := map[string]bool{
+		"feature-a.yaml":        true,
+		"feature-legacy-1.yaml": false,
+		"feature-a.yml":         false,
+		"app-prod.properties":   true,
+		"app-test.properties":   false,
+		"my-app-dev.properties": false,
+		"banner.txt":            true,
+		"banner.txt.bak":        false,
+	}
+
+	for key, want := range cases {
+		if got := selector.Matches(key); got != want {
+			t.Fatalf("%s: want %v got %v", key, want, got)
+		}
+	}
+}
+
+func TestKeySelectorSelectReportsMatchedKeys(t *testing.T) {
+	selector, _ := core.NewKeySelector(nil, []string{"secret-*"})
+	if !selector.Filtered() {
+		t.Fatalf("expected exclusions to count as filtering")
+	}
+
+	data, binaryData, matched := selector.Select(map[string]string{"a": "1", "secret-token": "x"}, map[string][]byte{"b": {0x01}, "secret-key": {0x02}})
+	if !reflect.DeepEqual(data, map[string]string{"a": "1"}) || !reflect.DeepEqual(binaryData, map[string][]byte{"b": {0x01}}) {
+		t.Fatalf("unexpected selection %+v %+v", data, binaryData)
+	}
+
+	if !reflect.DeepEqual(matched, []string{"a", "b"}) {
+		t.Fatalf("unexpected matched keys %v", matched)
+	}
+
+	everything, _ := core.NewKeySelector(nil, nil)
+	if everything.Filtered() || !everything.Matches("anything") {
+		t.Fatalf("expected an empty selector to select every key")
+	}
+}
+
+func TestValidateSpecKeySelection(t *testing.T) {
+	cases := map[string]struct {
+		dataKeys    []string
+		excludeKeys []string
+		sources     []core.SourceSpec
+		wantErr     string
+	}{
+		"valid":             {dataKeys: []string{"a", "feature-*", "/x.+/"}, excludeKeys: []string{"[ab]"}},
+		"bad glob":          {dataKeys: []string{"feature-[a"}, wantErr: "dataKeys[0]: invalid glob"},
+		"bad regex":         {excludeKeys: []string{"/(/"}, wantErr: "excludeKeys[0]: invalid regex"},
+		"empty entry":       {dataKeys: []string{""}, wantErr: "dataKeys[0] must not be empty"},
+		"invalid exact key": {dataKeys: []string{"a/b"}, wantErr: "dataKeys[0]"},
+		"bad overlay glob":  {sources: []core.SourceSpec{{Namespace: "env", Name: "prod", DataKeys: []string{"["}}}, wantErr: "sources[0].dataKeys[0]"},
+	}
+
+	for name, testCase := range cases {
+		spec := &core.ConfigPropagationSpec{
+			SourceRef:         core.ObjectRef{Namespace: "ns", Name: "cfg"},
+			NamespaceSelector: &core.LabelSelector{},
+			DataKeys:          testCase.dataKeys,
+			ExcludeKeys:       testCase.excludeKeys,
+			Sources:           testCase.sources,
+		}
+
+		err := core.ValidateSpec(spec)
+		if testCase.wantErr == "" {
+			if err != nil {
+				t.Fatalf("%s: unexpected error: %v", name, err)
+			}
+			continue
+		}
+
+		if err == nil || !strings.Contains(err.Error(), testCase.wantErr) {
+			t.Fatalf("%s: expected error containing %q, got %v", name, testCase.wantErr, err)
+		}
+	}
+}
@@ -0,0 +1,145 @@
+package core
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+)
+
+// targetMetadataDomain prefixes the rollout fingerprint of specs that stamp custom target metadata.
+const targetMetadataDomain = "configpropagator/targetMetadata/v1"
+
+// OwnedMetadata lists the label and annotation keys the controller wrote on a target.
+// It is recorded in OwnedMetadataAnnotation so later writes can drop keys it no longer wants
+// without touching metadata added by other tools.
+type OwnedMetadata struct {
+	Labels      []string `json:"labels,omitempty"`
+	Annotations []string `json:"annotations,omitempty"`
+}
+
+// legacyOwnedMetadata is assumed for targets written before ownership was recorded.
+var legacyOwnedMetadata = OwnedMetadata{
+	Labels:      []string{ManagedLabel, SourceHashLabel, OwnerHashLabel},
+	Annotations: []string{SourceAnnotation, HashAnnotation, OwnerAnnotation, OwnerUIDAnnotation, SourcesAnnotation, KeySourcesAnnotation},
+}
+
+// ParseOwnedMetadata reads the ownership record from target annotations.
+// Targets without a readable record are treated as owning the well-known managed keys only.
+func ParseOwnedMetadata(annotations map[string]string) OwnedMetadata {
+	recorded, exists := annotations[OwnedMetadataAnnotation]
+	if !exists {
+		return legacyOwnedMetadata
+	}
+
+	var owned OwnedMetadata
+	if err := json.Unmarshal([]byte(recorded), &owned); err != nil {
+		return legacyOwnedMetadata
+	}
+
+	return owned
+}
+
+// MergeOwnedMetadata applies the desired managed labels and annotations over the live ones.
+// Keys owned by a previous write but no longer desired are removed; every other key is left untouched.
+// The returned annotations record the new ownership.
+func MergeOwnedMetadata(liveLabels, liveAnnotations, desiredLabels, desiredAnnotations map[string]string) (map[string]string, map[string]string) {
+	previouslyOwned := ParseOwnedMetadata(liveAnnotations)
+
+	labels := mergeOwnedKeys(liveLabels, previouslyOwned.Labels, desiredLabels)
+	annotations := mergeOwnedKeys(liveAnnotations, previouslyOwned.Annotations, desiredAnnotations)
+
+	ownership, _ := json.Marshal(OwnedMetadata{Labels: sortedKeys(desiredLabels), Annotations: sortedKeys(desiredAnnotations)})
+	annotations[OwnedMetadataAnnotation] = string(ownership)
+
+	return labels, annotations
+}
+
+// StripOwnedMetadata removes every controller-owned label and annotation, and the ownership record itself,
+// leaving the metadata of other tools in place. It is used when a target is detached from management.
+func StripOwnedMetadata(liveLabels, liveAnnotations map[string]string) (map[string]string, map[string]string) {
+	owned := ParseOwnedMetadata(liveAnnotations)
+
+	labels := mergeOwnedKeys(liveLabels, owned.Labels, nil)
+	labels = mergeOwnedKeys(labels, legacyOwnedMetadata.Labels, nil)
+
+	annotations := mergeOwnedKeys(liveAnnotations, owned.Annotations, nil)
+	annotations = mergeOwnedKeys(annotations, legacyOwnedMetadata.Annotations, nil)
+	delete(annotations, OwnedMetadataAnnotation)
+
+	return labels, annotations
+}
+
+// mergeOwnedKeys copies the live map, drops the previously owned keys and sets the desired ones.
+func mergeOwnedKeys(live map[string]string, previouslyOwned []string, desired map[string]string) map[string]string {
+	merged := make(map[string]string, len(live)+len(desired))
+	for key, value := range live {
+		merged[key] = value
+	}
+
+	for _, key := range previouslyOwned {
+		delete(merged, key)
+	}
+
+	for key, value := range desired {
+		merged[key] = value
+	}
+
+	return merged
+}
+
+// TargetMetadataFingerprint folds the custom target labels and annotations into a rollout hash so editing them
+// starts a new rollout. Specs without custom metadata keep the content hash unchanged.
+func TargetMetadataFingerprint(contentHash string, labels, annotations map[string]string) string {
+	if len(labels) == 0 && len(annotations) == 0 {
+		return contentHash
+	}
+
+	hasher := sha256.New()
+	writeLengthPrefixed(hasher, []byte(targetMetadataDomain))
+	writeLengthPrefixed(hasher, []byte(contentHash))
+	writeLengthPrefixed(hasher, []byte(hashStringData(labels)))
+	writeLengthPrefixed(hasher, []byte(hashStringData(annotations)))
+
+	return hex.EncodeToString(hasher.Sum(nil))
+}
+
+// TargetMetadataCurrent reports whether a live target carries every desired custom label and annotation
+// and no longer carries custom keys a previous write owned but the spec dropped.
+func TargetMetadataCurrent(liveLabels, liveAnnotations, desiredLabels, desiredAnnotations map[string]string) bool {
+	owned := ParseOwnedMetadata(liveAnnotations)
+
+	return ownedKeysCurrent(liveLabels, owned.Labels, legacyOwnedMetadata.Labels, desiredLabels) &&
+		ownedKeysCurrent(liveAnnotations, owned.Annotations, legacyOwnedMetadata.Annotations, desiredAnnotations)
+}
+
+// ownedKeysCurrent checks one metadata map: desired entries must be present and stale owned keys absent.
+// Well-known managed keys are compared elsewhere and ignored here.
+func ownedKeysCurrent(live map[string]string, owned, managed []string, desired map[string]string) bool {
+	for key, value := range desired {
+		if liveValue, exists := live[key]; !exists || liveValue != value {
+			return false
+		}
+	}
+
+	managedSet := make(map[string]struct{}, len(managed)+1)
+	for _, key := range managed {
+		managedSet[key] = struct{}{}
+	}
+	managedSet[OwnedMetadataAnnotation] = struct{}{}
+
+	for _, key := range owned {
+		if _, isManaged := managedSet[key]; isManaged {
+			continue
+		}
+
+		if _, stillDesired := desired[key]; stillDesired {
+			continue
+		}
+
+		if _, exists := live[key]; exists {
+			return false
+		}
+	}
+
+	return true
+}
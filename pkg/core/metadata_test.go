@@ -0,0 +1,100 @@
+package core_test
+
+import (
+	"reflect"
+	"testing"
+
+	core "configpropagation/pkg/core"
+)
+
+func TestMergeOwnedMetadataPreservesForeignKeys(t *testing.T) {
+	labels, annotations := core.MergeOwnedMetadata(nil, nil,
+		map[string]string{core.ManagedLabel: "true"},
+		map[string]string{core.HashAnnotation: "h1", core.SourcesAnnotation: "a/b,c/d"})
+
+	// Another tool adds its own metadata between syncs.
+	labels["app.kubernetes.io/instance"] = "argo"
+	annotations["reloader.stakater.com/match"] = "true"
+
+	labels, annotations = core.MergeOwnedMetadata(labels, annotations,
+		map[string]string{core.ManagedLabel: "true"},
+		map[string]string{core.HashAnnotation: "h2"})
+
+	if !reflect.DeepEqual(labels, map[string]string{core.ManagedLabel: "true", "app.kubernetes.io/instance": "argo"}) {
+		t.Fatalf("unexpected labels %+v", labels)
+	}
+
+	if annotations[core.HashAnnotation] != "h2" || annotations["reloader.stakater.com/match"] != "true" {
+		t.Fatalf("expected owned keys updated and foreign keys kept, got %+v", annotations)
+	}
+
+	if _, stale := annotations[core.SourcesAnnotation]; stale {
+		t.Fatalf("expected a previously owned key that is no longer desired to be removed")
+	}
+
+	owned := core.ParseOwnedMetadata(annotations)
+	if !reflect.DeepEqual(owned, core.OwnedMetadata{Labels: []string{core.ManagedLabel}, Annotations: []string{core.HashAnnotation}}) {
+		t.Fatalf("unexpected ownership record %+v", owned)
+	}
+}
+
+func TestMergeOwnedMetadataLegacyTarget(t *testing.T) {
+	liveAnnotations := map[string]string{core.HashAnnotation: "old", core.KeySourcesAnnotation: "{}", "kubectl.kubernetes.io/last-applied-configuration": "{}"}
+
+	_, annotations := core.MergeOwnedMetadata(nil, liveAnnotations, nil, map[string]string{core.HashAnnotation: "new"})
+
+	if _, stale := annotations[core.KeySourcesAnnotation]; stale {
+		t.Fatalf("expected well-known managed keys to be treated as owned on legacy targets")
+	}
+
+	if annotations["kubectl.kubernetes.io/last-applied-configuration"] != "{}" {
+		t.Fatalf("expected foreign annotation to survive, got %+v", annotations)
+	}
+}
+
+func TestStripOwnedMetadata(t *testing.T) {
+	labels, annotations := core.MergeOwnedMetadata(
+		map[string]string{"team": "payments"},
+		map[string]string{"cost-center": "42"},
+		map[string]string{core.ManagedLabel: "true"},
+		map[string]string{core.SourceAnnotation: "platform/app", core.HashAnnotation: "h"})
+
+	labels, annotations = core.StripOwnedMetadata(labels, annotations)
+
+	if !reflect.DeepEqual(labels, map[string]string{"team": "payments"}) || !reflect.DeepEqual(annotations, map[string]string{"cost-center": "42"}) {
+		t.Fatalf("expected only foreign metadata to remain, got %+v %+v", labels, annotations)
+	}
+}
+
+func TestTargetMetadataFingerprint(t *testing.T) {
+	if core.TargetMetadataFingerprint("h", nil, map[string]string{}) != "h" {
+		t.Fatalf("expected specs without custom metadata to keep the content hash")
+	}
+
+	labelled := core.TargetMetadataFingerprint("h", map[string]string{"team": "a"}, nil)
+	annotated := core.TargetMetadataFingerprint("h", nil, map[string]string{"team": "a"})
+	if labelled == "h" || labelled == annotated || labelled != core.TargetMetadataFingerprint("h", map[string]string{"team": "a"}, nil) {
+		t.Fatalf("expected a stable fingerprint that tells labels and annotations apart")
+	}
+}
+
+func TestTargetMetadataCurrent(t *testing.T) {
+	desiredLabels := map[string]string{core.ManagedLabel: "true", "team": "a"}
+	labels, annotations := core.MergeOwnedMetadata(nil, nil, desiredLabels, map[string]string{core.HashAnnotation: "h", "note": "x"})
+
+	if !core.TargetMetadataCurrent(labels, annotations, map[string]string{"team": "a"}, map[string]string{"note": "x"}) {
+		t.Fatalf("expected freshly written metadata to be current")
+	}
+
+	if core.TargetMetadataCurrent(labels, annotations, map[string]string{"team": "b"}, map[string]string{"note": "x"}) {
+		t.Fatalf("expected a changed label value to be stale")
+	}
+
+	if core.TargetMetadataCurrent(labels, annotations, map[string]string{"team": "a"}, nil) {
+		t.Fatalf("expected an owned annotation dropped from the spec to be stale")
+	}
+
+	if !core.TargetMetadataCurrent(map[string]string{core.ManagedLabel: "true"}, map[string]string{core.HashAnnotation: "h"}, nil, nil) {
+		t.Fatalf("expected legacy targets without custom metadata to be current")
+	}
+}
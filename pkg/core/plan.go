@@ -0,0 +1,64 @@
+package core
+
+import (
+	"fmt"
+	"sort"
+)
+
+// NewPlan orders the planned targets by namespace and name and counts them per action. syncedCount is the number
+// of selected namespaces the reconcile would leave in sync; those it would not write are counted as unchanged.
+// Skipped namespaces that only wait for a later rolling batch are counted as pending instead of listed.
+func NewPlan(targets []PlannedTarget, targetCount, syncedCount int) *PlanStatus {
+	plan := &PlanStatus{TargetCount: int32(targetCount)}
+
+	var ordered []PlannedTarget
+	for _, target := range targets {
+		if target.Action == PlanSkip && target.Reason == ReasonPendingRollout {
+			plan.Pending++
+			continue
+		}
+
+		ordered = append(ordered, target)
+	}
+
+	sort.SliceStable(ordered, func(left, right int) bool {
+		if ordered[left].Namespace != ordered[right].Namespace {
+			return ordered[left].Namespace < ordered[right].Namespace
+		}
+		return ordered[left].Name < ordered[right].Name
+	})
+
+	plan.Targets = ordered
+	for _, target := range ordered {
+		switch target.Action {
+		case PlanCreate:
+			plan.Create++
+		case PlanUpdate:
+			plan.Update++
+		case PlanPrune:
+			plan.Prune++
+		case PlanDetach:
+			plan.Detach++
+		case PlanSkip:
+			plan.Skip++
+		}
+	}
+
+	if unchanged := int32(syncedCount) - plan.Create - plan.Update; unchanged > 0 {
+		plan.Unchanged = unchanged
+	}
+
+	return plan
+}
+
+// Summary renders the action counts as one line, e.g. for a status condition.
+func (plan *PlanStatus) Summary() string {
+	summary := fmt.Sprintf("would create %d, update %d, prune %d, detach %d and skip %d targets; %d of %d selected namespaces unchanged",
+		plan.Create, plan.Update, plan.Prune, plan.Detach, plan.Skip, plan.Unchanged, plan.TargetCount)
+
+	if plan.Pending > 0 {
+		summary = fmt.Sprintf("%s, %d pending later batches", summary, plan.Pending)
+	}
+
+	return summary
+}
@@ -0,0 +1,37 @@
+package core_test
+
+import (
+	"testing"
+
+	core "configpropagation/pkg/core"
+)
+
+func TestNewPlanOrdersAndCountsActions(t *testing.T) {
+	plan := core.NewPlan([]core.PlannedTarget{
+		{Namespace: "app-2", Name: "base", Action: core.PlanUpdate},
+		{Namespace: "app-1", Name: "renamed", Action: core.PlanCreate},
+		{Namespace: "app-1", Name: "base", Action: core.PlanPrune},
+		{Namespace: "app-3", Action: core.PlanSkip, Reason: core.ReasonOwnedByOther},
+		{Namespace: "app-4", Action: core.PlanSkip, Reason: core.ReasonPendingRollout},
+	}, 5, 3)
+
+	order := []string{"app-1/base", "app-1/renamed", "app-2/base", "app-3/"}
+	for index, target := range plan.Targets {
+		if got := target.Namespace + "/" + target.Name; got != order[index] {
+			t.Fatalf("expected target %d to be %s, got %s", index, order[index], got)
+		}
+	}
+
+	if len(plan.Targets) != len(order) {
+		t.Fatalf("expected pending namespaces to be counted, not listed, got %+v", plan.Targets)
+	}
+
+	if plan.Create != 1 || plan.Update != 1 || plan.Prune != 1 || plan.Detach != 0 || plan.Skip != 1 || plan.Unchanged != 1 || plan.Pending != 1 {
+		t.Fatalf("unexpected counts: %+v", plan)
+	}
+
+	want := "would create 1, update 1, prune 1, detach 0 and skip 1 targets; 1 of 5 selected namespaces unchanged, 1 pending later batches"
+	if plan.Summary() != want {
+		t.Fatalf("expected summary %q, got %q", want, plan.Summary())
+	}
+}
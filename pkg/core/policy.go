@@ -0,0 +1,190 @@
+package core
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+)
+
+// ConfigPropagationPolicySpec restricts what ConfigPropagations may do cluster-wide. Empty fields leave that
+// aspect unrestricted. Namespace and label key entries are exact names or globs as in path.Match; an invalid
+// glob matches nothing, so a broken policy denies rather than allows.
+type ConfigPropagationPolicySpec struct {
+	AllowedSourceNamespaces []string       `json:"allowedSourceNamespaces,omitempty"` // covers sourceRef and every overlay
+	SelectorRules           []SelectorRule `json:"selectorRules,omitempty"`
+	MaxTargets              *int32         `json:"maxTargets,omitempty"`
+	AllowedStrategies       []string       `json:"allowedStrategies,omitempty"`  // rolling|immediate
+	AllowPruneDisabled      *bool          `json:"allowPruneDisabled,omitempty"` // false forbids prune: false; default true
+}
+
+// SelectorRule lets ConfigPropagations in the matching namespaces select target namespaces by the listed label keys.
+type SelectorRule struct {
+	Namespaces       []string `json:"namespaces"`       // ConfigPropagation namespaces
+	AllowedLabelKeys []string `json:"allowedLabelKeys"` // namespace label keys their selectors may reference
+}
+
+// Policy is a named ConfigPropagationPolicy as read from the cluster.
+type Policy struct {
+	Name string
+	Spec ConfigPropagationPolicySpec
+}
+
+// PolicyViolationError lists the rules of one policy a ConfigPropagation breaks.
+type PolicyViolationError struct {
+	Policy     string
+	Violations []string
+}
+
+// Error renders the violations as one admission or status message.
+func (violation *PolicyViolationError) Error() string {
+	return fmt.Sprintf("violates ConfigPropagationPolicy %s: %s", violation.Policy, strings.Join(violation.Violations, "; "))
+}
+
+// EvaluatePolicies checks a ConfigPropagation in the given namespace against every policy and returns a
+// *PolicyViolationError for the first one, by name, that it breaks. A negative targetCount skips maxTargets,
+// for callers that have not listed the selected namespaces yet.
+func EvaluatePolicies(policies []Policy, namespace string, spec *ConfigPropagationSpec, targetCount int) error {
+	ordered := append([]Policy(nil), policies...)
+	sort.Slice(ordered, func(left, right int) bool {
+		return ordered[left].Name < ordered[right].Name
+	})
+
+	for index := range ordered {
+		if violations := policyViolations(&ordered[index].Spec, namespace, spec, targetCount); len(violations) > 0 {
+			return &PolicyViolationError{Policy: ordered[index].Name, Violations: violations}
+		}
+	}
+
+	return nil
+}
+
+// PoliciesLimitTargets reports whether any policy sets maxTargets, so callers only count targets when needed.
+func PoliciesLimitTargets(policies []Policy) bool {
+	for index := range policies {
+		if policies[index].Spec.MaxTargets != nil {
+			return true
+		}
+	}
+
+	return false
+}
+
+// policyViolations describes every rule of one policy the spec breaks.
+func policyViolations(policy *ConfigPropagationPolicySpec, namespace string, spec *ConfigPropagationSpec, targetCount int) []string {
+	var violations []string
+
+	if len(policy.AllowedSourceNamespaces) > 0 {
+		for _, sourceLayer := range SourceLayers(spec) {
+			if !matchesAnyPattern(policy.AllowedSourceNamespaces, sourceLayer.Namespace) {
+				violations = append(violations, fmt.Sprintf("source namespace %s is not in allowedSourceNamespaces", sourceLayer.Namespace))
+			}
+		}
+	}
+
+	if len(policy.SelectorRules) > 0 {
+		violations = append(violations, selectorViolations(policy.SelectorRules, namespace, spec.NamespaceSelector)...)
+	}
+
+	if policy.MaxTargets != nil && targetCount >= 0 && targetCount > int(*policy.MaxTargets) {
+		violations = append(violations, fmt.Sprintf("selects %d namespaces, more than maxTargets %d", targetCount, *policy.MaxTargets))
+	}
+
+	if len(policy.AllowedStrategies) > 0 {
+		strategyType := StrategyRolling
+		if spec.Strategy != nil && spec.Strategy.Type != "" {
+			strategyType = spec.Strategy.Type
+		}
+
+		if !containsString(policy.AllowedStrategies, strategyType) {
+			violations = append(violations, fmt.Sprintf("strategy %s is not in allowedStrategies [%s]", strategyType, strings.Join(policy.AllowedStrategies, ", ")))
+		}
+	}
+
+	if policy.AllowPruneDisabled != nil && !*policy.AllowPruneDisabled && spec.Prune != nil && !*spec.Prune {
+		violations = append(violations, "prune: false is not allowed")
+	}
+
+	return violations
+}
+
+// selectorViolations checks the namespace selector against the selector rules that apply to the namespace.
+// The selector must require at least one label, and every label key it references must be allowed.
+func selectorViolations(rules []SelectorRule, namespace string, selector *LabelSelector) []string {
+	var allowedKeys []string
+
+	for _, rule := range rules {
+		if matchesAnyPattern(rule.Namespaces, namespace) {
+			allowedKeys = append(allowedKeys, rule.AllowedLabelKeys...)
+		}
+	}
+
+	if allowedKeys == nil {
+		return []string{fmt.Sprintf("no selectorRules entry allows ConfigPropagations in namespace %s", namespace)}
+	}
+
+	if selector == nil {
+		return []string{"namespaceSelector must require a label allowed by selectorRules"}
+	}
+
+	referencedKeys := map[string]bool{}
+	for labelKey := range selector.MatchLabels {
+		referencedKeys[labelKey] = true
+	}
+
+	for _, expression := range selector.MatchExpressions {
+		required := expression.Operator == "In" || expression.Operator == "Exists"
+		referencedKeys[expression.Key] = referencedKeys[expression.Key] || required
+	}
+
+	var violations []string
+	requiresLabel := false
+
+	labelKeys := make([]string, 0, len(referencedKeys))
+	for labelKey := range referencedKeys {
+		labelKeys = append(labelKeys, labelKey)
+	}
+
+	sort.Strings(labelKeys)
+
+	for _, labelKey := range labelKeys {
+		if !matchesAnyPattern(allowedKeys, labelKey) {
+			violations = append(violations, fmt.Sprintf("namespaceSelector key %s is not allowed for namespace %s", labelKey, namespace))
+			continue
+		}
+
+		requiresLabel = requiresLabel || referencedKeys[labelKey]
+	}
+
+	if !requiresLabel && len(violations) == 0 {
+		violations = append(violations, "namespaceSelector must require a label allowed by selectorRules")
+	}
+
+	return violations
+}
+
+// matchesAnyPattern reports whether the value equals or globs one of the patterns.
+func matchesAnyPattern(patterns []string, value string) bool {
+	for _, pattern := range patterns {
+		if pattern == value {
+			return true
+		}
+
+		if matched, err := path.Match(pattern, value); err == nil && matched {
+			return true
+		}
+	}
+
+	return false
+}
+
+// containsString reports whether the value is one of the entries.
+func containsString(entries []string, value string) bool {
+	for _, entry := range entries {
+		if entry == value {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,112 @@
+package core_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	core "configpropagation/pkg/core"
+)
+
+func policySpec(selector *core.LabelSelector) *core.ConfigPropagationSpec {
+	spec := &core.ConfigPropagationSpec{
+		SourceRef:         core.ObjectRef{Namespace: "platform", Name: "base"},
+		NamespaceSelector: selector,
+	}
+	core.DefaultSpec(spec)
+	return spec
+}
+
+func TestEvaluatePolicies(t *testing.T) {
+	maxTargets := int32(3)
+	pruneDisabledForbidden := false
+
+	policies := []core.Policy{{
+		Name: "platform",
+		Spec: core.ConfigPropagationPolicySpec{
+			AllowedSourceNamespaces: []string{"platform", "shared-*"},
+			SelectorRules: []core.SelectorRule{
+				{Namespaces: []string{"team-*"}, AllowedLabelKeys: []string{"team", "example.com/*"}},
+			},
+			MaxTargets:         &maxTargets,
+			AllowedStrategies:  []string{core.StrategyRolling},
+			AllowPruneDisabled: &pruneDisabledForbidden,
+		},
+	}}
+
+	teamSelector := &core.LabelSelector{MatchLabels: map[string]string{"team": "a"}}
+
+	cases := map[string]struct {
+		namespace   string
+		spec        func() *core.ConfigPropagationSpec
+		targetCount int
+		violation   string
+	}{
+		"compliant":                      {namespace: "team-a", spec: func() *core.ConfigPropagationSpec { return policySpec(teamSelector) }, targetCount: 3},
+		"unknown count skips maxTargets": {namespace: "team-a", spec: func() *core.ConfigPropagationSpec { return policySpec(teamSelector) }, targetCount: -1},
+		"overlay from disallowed namespace": {namespace: "team-a", spec: func() *core.ConfigPropagationSpec {
+			spec := policySpec(teamSelector)
+			spec.Sources = []core.SourceSpec{{Namespace: "kube-system", Name: "secrets"}}
+			return spec
+		}, violation: "source namespace kube-system is not in allowedSourceNamespaces"},
+		"namespace without rule": {namespace: "default", spec: func() *core.ConfigPropagationSpec { return policySpec(teamSelector) }, violation: "no selectorRules entry allows ConfigPropagations in namespace default"},
+		"disallowed label key": {namespace: "team-a", spec: func() *core.ConfigPropagationSpec {
+			return policySpec(&core.LabelSelector{MatchLabels: map[string]string{"team": "a", "tier": "web"}})
+		}, violation: "namespaceSelector key tier is not allowed"},
+		"globbed label key": {namespace: "team-a", spec: func() *core.ConfigPropagationSpec {
+			return policySpec(&core.LabelSelector{MatchExpressions: []core.LabelSelectorReq{{Key: "example.com/env", Operator: "Exists"}}})
+		}},
+		"wide-open selector": {namespace: "team-a", spec: func() *core.ConfigPropagationSpec { return policySpec(&core.LabelSelector{}) }, violation: "namespaceSelector must require a label"},
+		"only negative requirements": {namespace: "team-a", spec: func() *core.ConfigPropagationSpec {
+			return policySpec(&core.LabelSelector{MatchExpressions: []core.LabelSelectorReq{{Key: "team", Operator: "DoesNotExist"}}})
+		}, violation: "namespaceSelector must require a label"},
+		"too many targets": {namespace: "team-a", spec: func() *core.ConfigPropagationSpec { return policySpec(teamSelector) }, targetCount: 4, violation: "selects 4 namespaces, more than maxTargets 3"},
+		"immediate strategy": {namespace: "team-a", spec: func() *core.ConfigPropagationSpec {
+			spec := policySpec(teamSelector)
+			spec.Strategy.Type = core.StrategyImmediate
+			return spec
+		}, violation: "strategy immediate is not in allowedStrategies [rolling]"},
+		"prune disabled": {namespace: "team-a", spec: func() *core.ConfigPropagationSpec {
+			spec := policySpec(teamSelector)
+			*spec.Prune = false
+			return spec
+		}, violation: "prune: false is not allowed"},
+	}
+
+	for name, testCase := range cases {
+		err := core.EvaluatePolicies(policies, testCase.namespace, testCase.spec(), testCase.targetCount)
+
+		if testCase.violation == "" {
+			if err != nil {
+				t.Fatalf("%s: unexpected violation: %v", name, err)
+			}
+			continue
+		}
+
+		var violation *core.PolicyViolationError
+		if !errors.As(err, &violation) || violation.Policy != "platform" || !strings.Contains(err.Error(), testCase.violation) {
+			t.Fatalf("%s: expected violation %q, got %v", name, testCase.violation, err)
+		}
+	}
+}
+
+func TestEvaluatePoliciesReportsFirstPolicyByName(t *testing.T) {
+	policies := []core.Policy{
+		{Name: "zeta", Spec: core.ConfigPropagationPolicySpec{AllowedStrategies: []string{core.StrategyImmediate}}},
+		{Name: "alpha", Spec: core.ConfigPropagationPolicySpec{AllowedSourceNamespaces: []string{"elsewhere"}}},
+		{Name: "open"},
+	}
+
+	err := core.EvaluatePolicies(policies, "team-a", policySpec(&core.LabelSelector{}), 0)
+	if err == nil || !strings.HasPrefix(err.Error(), "violates ConfigPropagationPolicy alpha:") {
+		t.Fatalf("expected the alpha policy to be reported first, got %v", err)
+	}
+
+	if core.EvaluatePolicies(nil, "team-a", policySpec(nil), 1000) != nil {
+		t.Fatalf("expected no policies to allow everything")
+	}
+
+	if core.PoliciesLimitTargets(policies) {
+		t.Fatalf("expected no policy to limit targets")
+	}
+}
@@ -3,6 +3,7 @@ package core
 import (
 	"sort"
 	"sync"
+	"time"
 )
 
 // NamespacedName identifies a namespaced Kubernetes resource.
@@ -17,6 +18,14 @@ type RolloutResult struct {
 	TotalTargets   int
 	CompletedCount int
 	OutOfSync      []OutOfSyncItem
+	// CleanupFailures are targets no longer desired that could not be pruned or detached. They are kept out of
+	// OutOfSync, which only describes the selected namespaces.
+	CleanupFailures []OutOfSyncItem
+	Rollout         *RolloutStatus
+	// RetryAfter is the delay until the earliest backed-off target may be retried, or zero when none is waiting.
+	RetryAfter time.Duration
+	// MatchedKeys reports the keys selected from every source with dataKeys or excludeKeys.
+	MatchedKeys []SourceKeys
 }
 
 // RolloutPlanner tracks per-object rollout progress for rolling strategies.
@@ -28,6 +37,7 @@ type RolloutPlanner struct {
 type rolloutState struct {
 	hash      string
 	completed map[string]struct{}
+	startedAt time.Time
 }
 
 // NewRolloutPlanner constructs an empty planner.
@@ -106,6 +116,21 @@ func (planner *RolloutPlanner) MarkCompleted(identifier NamespacedName, desiredH
 	return len(state.completed)
 }
 
+// Reopen marks completed namespaces as pending again, for targets that no longer carry the rolled-out content.
+func (planner *RolloutPlanner) Reopen(identifier NamespacedName, desiredHash string, namespaces []string) {
+	planner.mutex.Lock()
+	defer planner.mutex.Unlock()
+
+	state, exists := planner.states[identifier]
+	if !exists || state.hash != desiredHash {
+		return
+	}
+
+	for _, namespace := range namespaces {
+		delete(state.completed, namespace)
+	}
+}
+
 // CompletedNamespaces returns the namespaces currently marked as completed for the identifier.
 func (planner *RolloutPlanner) CompletedNamespaces(identifier NamespacedName, desiredHash string) []string {
 	planner.mutex.Lock()
@@ -125,6 +150,77 @@ func (planner *RolloutPlanner) CompletedNamespaces(identifier NamespacedName, de
 	return completed
 }
 
+// Tracking reports whether the planner holds progress for the object at the desired hash.
+func (planner *RolloutPlanner) Tracking(identifier NamespacedName, desiredHash string) bool {
+	planner.mutex.Lock()
+	defer planner.mutex.Unlock()
+
+	state, exists := planner.states[identifier]
+	return exists && state.hash == desiredHash
+}
+
+// TrackedHash returns the hash of the rollout currently tracked for the object, if any.
+func (planner *RolloutPlanner) TrackedHash(identifier NamespacedName) (string, bool) {
+	planner.mutex.Lock()
+	defer planner.mutex.Unlock()
+
+	state, exists := planner.states[identifier]
+	if !exists {
+		return "", false
+	}
+
+	return state.hash, true
+}
+
+// Restore loads previously persisted progress. Progress for the same hash is merged with what
+// the planner already knows; progress for any other hash replaces the tracked state.
+func (planner *RolloutPlanner) Restore(identifier NamespacedName, status RolloutStatus) {
+	planner.mutex.Lock()
+	defer planner.mutex.Unlock()
+
+	startedAt, err := time.Parse(time.RFC3339, status.StartedAt)
+	if err != nil {
+		startedAt = time.Now().UTC()
+	}
+
+	state, exists := planner.states[identifier]
+	if !exists || state.hash != status.Hash {
+		state = &rolloutState{hash: status.Hash, completed: map[string]struct{}{}, startedAt: startedAt}
+		planner.states[identifier] = state
+	} else if startedAt.Before(state.startedAt) {
+		state.startedAt = startedAt
+	}
+
+	for _, namespace := range status.CompletedNamespaces {
+		state.completed[namespace] = struct{}{}
+	}
+}
+
+// Status snapshots the tracked progress for the desired hash in its persisted form.
+func (planner *RolloutPlanner) Status(identifier NamespacedName, desiredHash string, currentBatch []string) *RolloutStatus {
+	planner.mutex.Lock()
+	defer planner.mutex.Unlock()
+
+	state, exists := planner.states[identifier]
+	if !exists || state.hash != desiredHash {
+		return nil
+	}
+
+	completed := make([]string, 0, len(state.completed))
+	for namespace := range state.completed {
+		completed = append(completed, namespace)
+	}
+
+	sort.Strings(completed)
+
+	return &RolloutStatus{
+		Hash:                state.hash,
+		CompletedNamespaces: completed,
+		CurrentBatch:        append([]string(nil), currentBatch...),
+		StartedAt:           state.startedAt.Format(time.RFC3339),
+	}
+}
+
 // Forget removes any stored rollout state for the provided object.
 func (planner *RolloutPlanner) Forget(identifier NamespacedName) {
 	planner.mutex.Lock()
@@ -138,7 +234,7 @@ func (planner *RolloutPlanner) ensureStateLocked(identifier NamespacedName, desi
 	state, exists := planner.states[identifier]
 
 	if !exists {
-		state = &rolloutState{hash: desiredHash, completed: map[string]struct{}{}}
+		state = &rolloutState{hash: desiredHash, completed: map[string]struct{}{}, startedAt: time.Now().UTC()}
 		planner.states[identifier] = state
 
 		return state
@@ -147,6 +243,7 @@ func (planner *RolloutPlanner) ensureStateLocked(identifier NamespacedName, desi
 	if state.hash != desiredHash {
 		state.hash = desiredHash
 		state.completed = map[string]struct{}{}
+		state.startedAt = time.Now().UTC()
 	}
 
 	return state
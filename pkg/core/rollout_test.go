@@ -1,6 +1,9 @@
 package core
 
-import "testing"
+import (
+	"reflect"
+	"testing"
+)
 
 func TestRolloutPlannerRollingProgress(t *testing.T) {
 	planner := NewRolloutPlanner()
@@ -43,6 +46,25 @@ func TestRolloutPlannerHashChangeResets(t *testing.T) {
 	}
 }
 
+func TestRolloutPlannerReopenPlansCompletedNamespaceAgain(t *testing.T) {
+	planner := NewRolloutPlanner()
+	id := NamespacedName{Namespace: "ns", Name: "cp"}
+	targets := []string{"a", "b"}
+
+	planner.MarkCompleted(id, "h1", targets)
+	planner.Reopen(id, "h2", []string{"a"})
+	if completed := planner.CompletedNamespaces(id, "h1"); !reflect.DeepEqual(completed, targets) {
+		t.Fatalf("expected reopening another hash to be ignored, got %v", completed)
+	}
+
+	planner.Reopen(id, "h1", []string{"a"})
+
+	planned, completed := planner.Plan(id, "h1", StrategyRolling, 2, targets)
+	if !reflect.DeepEqual(planned, []string{"a"}) || completed != 1 {
+		t.Fatalf("expected the reopened namespace to be planned again, got planned=%v completed=%d", planned, completed)
+	}
+}
+
 func TestRolloutPlannerImmediateReturnsAll(t *testing.T) {
 	planner := NewRolloutPlanner()
 	id := NamespacedName{Namespace: "ns", Name: "cp"}
@@ -57,3 +79,51 @@ func TestRolloutPlannerImmediateReturnsAll(t *testing.T) {
 		t.Fatalf("marking empty namespaces should not change state, got %d", got)
 	}
 }
+
+func TestRolloutPlannerRestoreResumesProgress(t *testing.T) {
+	planner := NewRolloutPlanner()
+	id := NamespacedName{Namespace: "ns", Name: "cp"}
+	targets := []string{"a", "b", "c", "d"}
+
+	if planner.Tracking(id, "h1") {
+		t.Fatalf("fresh planner should not track any rollout")
+	}
+
+	planner.Restore(id, RolloutStatus{Hash: "h1", CompletedNamespaces: []string{"a", "b"}, StartedAt: "2024-01-02T03:04:05Z"})
+	if !planner.Tracking(id, "h1") {
+		t.Fatalf("expected restored rollout to be tracked")
+	}
+
+	planned, completed := planner.Plan(id, "h1", StrategyRolling, 2, targets)
+	if !reflect.DeepEqual(planned, []string{"c", "d"}) || completed != 2 {
+		t.Fatalf("expected restore to resume at c,d, got planned=%v completed=%d", planned, completed)
+	}
+
+	status := planner.Status(id, "h1", planned)
+	want := &RolloutStatus{Hash: "h1", CompletedNamespaces: []string{"a", "b"}, CurrentBatch: []string{"c", "d"}, StartedAt: "2024-01-02T03:04:05Z"}
+	if !reflect.DeepEqual(status, want) {
+		t.Fatalf("unexpected status snapshot: %+v", status)
+	}
+	if planner.Status(id, "other", nil) != nil {
+		t.Fatalf("expected no status for an untracked hash")
+	}
+}
+
+func TestRolloutPlannerRestoreMergesSameHashAndReplacesOthers(t *testing.T) {
+	planner := NewRolloutPlanner()
+	id := NamespacedName{Namespace: "ns", Name: "cp"}
+
+	planner.MarkCompleted(id, "h1", []string{"a"})
+	planner.Restore(id, RolloutStatus{Hash: "h1", CompletedNamespaces: []string{"b"}})
+	if got := planner.CompletedNamespaces(id, "h1"); !reflect.DeepEqual(got, []string{"a", "b"}) {
+		t.Fatalf("expected merged progress, got %v", got)
+	}
+
+	planner.Restore(id, RolloutStatus{Hash: "h2", CompletedNamespaces: []string{"c"}})
+	if hash, tracked := planner.TrackedHash(id); !tracked || hash != "h2" {
+		t.Fatalf("expected h2 to replace h1, got %q tracked=%v", hash, tracked)
+	}
+	if got := planner.CompletedNamespaces(id, "h2"); !reflect.DeepEqual(got, []string{"c"}) {
+		t.Fatalf("expected replaced progress, got %v", got)
+	}
+}
@@ -0,0 +1,37 @@
+package core
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SourceNotShareableError reports a source ConfigMap in another namespace that has not opted in to being read
+// from the ConfigPropagation's namespace.
+type SourceNotShareableError struct {
+	Source    ObjectRef
+	Namespace string
+}
+
+// Error renders the missing opt-in as one status message.
+func (notShareable *SourceNotShareableError) Error() string {
+	return fmt.Sprintf("source %s/%s does not allow ConfigPropagations from namespace %s; add %s to its %s annotation",
+		notShareable.Source.Namespace, notShareable.Source.Name, notShareable.Namespace, notShareable.Namespace, AllowFromNamespacesAnnotation)
+}
+
+// SourceShareableWith reports whether a ConfigPropagation in the namespace may read the source ConfigMap.
+// Sources in the ConfigPropagation's own namespace are always shareable; others must list the namespace, or a
+// glob matching it such as team-* or *, in the comma-separated AllowFromNamespacesAnnotation.
+func SourceShareableWith(source ObjectRef, sourceAnnotations map[string]string, namespace string) bool {
+	if source.Namespace == namespace {
+		return true
+	}
+
+	var allowedNamespaces []string
+	for _, entry := range strings.Split(sourceAnnotations[AllowFromNamespacesAnnotation], ",") {
+		if entry = strings.TrimSpace(entry); entry != "" {
+			allowedNamespaces = append(allowedNamespaces, entry)
+		}
+	}
+
+	return matchesAnyPattern(allowedNamespaces, namespace)
+}
@@ -0,0 +1,40 @@
+package core_test
+
+import (
+	"strings"
+	"testing"
+
+	core "configpropagation/pkg/core"
+)
+
+func TestSourceShareableWith(t *testing.T) {
+	source := core.ObjectRef{Namespace: "platform", Name: "base"}
+	allowing := func(value string) map[string]string {
+		return map[string]string{core.AllowFromNamespacesAnnotation: value}
+	}
+
+	cases := map[string]struct {
+		annotations map[string]string
+		namespace   string
+		want        bool
+	}{
+		"same namespace":        {annotations: nil, namespace: "platform", want: true},
+		"no annotation":         {annotations: nil, namespace: "team-a", want: false},
+		"listed":                {annotations: allowing("team-b, team-a"), namespace: "team-a", want: true},
+		"not listed":            {annotations: allowing("team-b"), namespace: "team-a", want: false},
+		"glob":                  {annotations: allowing("team-*"), namespace: "team-a", want: true},
+		"everyone":              {annotations: allowing("*"), namespace: "sandbox", want: true},
+		"empty entries ignored": {annotations: allowing(" , "), namespace: "team-a", want: false},
+	}
+
+	for name, testCase := range cases {
+		if got := core.SourceShareableWith(source, testCase.annotations, testCase.namespace); got != testCase.want {
+			t.Fatalf("%s: want %v got %v", name, testCase.want, got)
+		}
+	}
+
+	message := (&core.SourceNotShareableError{Source: source, Namespace: "team-a"}).Error()
+	if !strings.Contains(message, "platform/base") || !strings.Contains(message, core.AllowFromNamespacesAnnotation) {
+		t.Fatalf("expected the message to name the source and the annotation, got %q", message)
+	}
+}
@@ -0,0 +1,115 @@
+package core
+
+import (
+	"fmt"
+	"sort"
+)
+
+// SourceContent is the filtered content read from one source layer.
+type SourceContent struct {
+	Ref        ObjectRef
+	Data       map[string]string
+	BinaryData map[string][]byte
+}
+
+// MergedContent is the result of layering several sources into one target payload.
+type MergedContent struct {
+	Data       map[string]string
+	BinaryData map[string][]byte
+	// KeySources maps every key to the namespace/name of the source that provided it.
+	KeySources map[string]string
+	// Contributors lists, in precedence order, the sources that provided at least one key.
+	Contributors []string
+}
+
+// SourceLayers returns the sources of a spec in precedence order: sourceRef first, then each overlay.
+func SourceLayers(spec *ConfigPropagationSpec) []SourceSpec {
+	layers := []SourceSpec{{Namespace: spec.SourceRef.Namespace, Name: spec.SourceRef.Name, DataKeys: spec.DataKeys, ExcludeKeys: spec.ExcludeKeys}}
+	return append(layers, spec.Sources...)
+}
+
+// MergeSources layers source content in order so later sources win on key collisions.
+// In reject mode a key provided by more than one source is an error instead.
+func MergeSources(mergeMode string, layers []SourceContent) (MergedContent, error) {
+	merged := MergedContent{Data: map[string]string{}, BinaryData: map[string][]byte{}, KeySources: map[string]string{}}
+
+	for _, layer := range layers {
+		sourceIdentifier := fmt.Sprintf("%s/%s", layer.Ref.Namespace, layer.Ref.Name)
+
+		for _, key := range layerKeys(layer) {
+			if previousSource, collides := merged.KeySources[key]; collides {
+				if mergeMode == MergeReject {
+					return MergedContent{}, fmt.Errorf("key %q is provided by both %s and %s (mergeMode=reject)", key, previousSource, sourceIdentifier)
+				}
+
+				// The key may change between data and binaryData across layers.
+				delete(merged.Data, key)
+				delete(merged.BinaryData, key)
+			}
+
+			if value, isText := layer.Data[key]; isText {
+				merged.Data[key] = value
+			} else {
+				merged.BinaryData[key] = layer.BinaryData[key]
+			}
+
+			merged.KeySources[key] = sourceIdentifier
+		}
+	}
+
+	contributed := map[string]struct{}{}
+	for _, sourceIdentifier := range merged.KeySources {
+		contributed[sourceIdentifier] = struct{}{}
+	}
+
+	for _, layer := range layers {
+		sourceIdentifier := fmt.Sprintf("%s/%s", layer.Ref.Namespace, layer.Ref.Name)
+		if _, provided := contributed[sourceIdentifier]; provided {
+			merged.Contributors = append(merged.Contributors, sourceIdentifier)
+		}
+	}
+
+	return merged, nil
+}
+
+// layerKeys returns the sorted keys of a layer across data and binaryData.
+func layerKeys(layer SourceContent) []string {
+	keys := make([]string, 0, len(layer.Data)+len(layer.BinaryData))
+
+	for key := range layer.Data {
+		keys = append(keys, key)
+	}
+
+	for key := range layer.BinaryData {
+		if _, alsoText := layer.Data[key]; !alsoText {
+			keys = append(keys, key)
+		}
+	}
+
+	sort.Strings(keys)
+	return keys
+}
+
+// validateSources checks the overlay sources and merge mode of a spec.
+func validateSources(spec *ConfigPropagationSpec) error {
+	if spec.MergeMode != "" && spec.MergeMode != MergeOverride && spec.MergeMode != MergeReject {
+		return fmt.Errorf("invalid mergeMode: %s", spec.MergeMode)
+	}
+
+	seen := map[ObjectRef]struct{}{spec.SourceRef: {}}
+
+	for index, source := range spec.Sources {
+		if source.Namespace == "" || source.Name == "" {
+			return fmt.Errorf("sources[%d].namespace and sources[%d].name are required", index, index)
+		}
+
+		ref := ObjectRef{Namespace: source.Namespace, Name: source.Name}
+		if _, duplicate := seen[ref]; duplicate {
+			return fmt.Errorf("sources[%d]: %s/%s is listed more than once", index, source.Namespace, source.Name)
+		}
+
+		seen[ref] = struct{}{}
+	}
+
+	return nil
+}
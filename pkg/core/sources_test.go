@@ -0,0 +1,90 @@
+package core_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	core "configpropagation/pkg/core"
+)
+
+func TestMergeSourcesLaterWins(t *testing.T) {
+	layers := []core.SourceContent{
+		{Ref: core.ObjectRef{Namespace: "org", Name: "base"}, Data: map[string]string{"log": "info", "region": "eu"}, BinaryData: map[string][]byte{"ca": {0x01}}},
+		{Ref: core.ObjectRef{Namespace: "env", Name: "prod"}, Data: map[string]string{"log": "warn", "ca": "pem"}},
+		{Ref: core.ObjectRef{Namespace: "team", Name: "overlay"}, Data: map[string]string{"log": "error"}},
+		{Ref: core.ObjectRef{Namespace: "team", Name: "empty"}},
+	}
+
+	merged, err := core.MergeSources(core.MergeOverride, layers)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !reflect.DeepEqual(merged.Data, map[string]string{"log": "error", "region": "eu", "ca": "pem"}) || len(merged.BinaryData) != 0 {
+		t.Fatalf("unexpected merged content %+v %+v", merged.Data, merged.BinaryData)
+	}
+
+	wantSources := map[string]string{"log": "team/overlay", "region": "org/base", "ca": "env/prod"}
+	if !reflect.DeepEqual(merged.KeySources, wantSources) {
+		t.Fatalf("unexpected key sources %+v", merged.KeySources)
+	}
+
+	if !reflect.DeepEqual(merged.Contributors, []string{"org/base", "env/prod", "team/overlay"}) {
+		t.Fatalf("unexpected contributors %+v", merged.Contributors)
+	}
+}
+
+func TestMergeSourcesReject(t *testing.T) {
+	layers := []core.SourceContent{
+		{Ref: core.ObjectRef{Namespace: "org", Name: "base"}, Data: map[string]string{"log": "info"}},
+		{Ref: core.ObjectRef{Namespace: "env", Name: "prod"}, BinaryData: map[string][]byte{"log": {0x01}}},
+	}
+
+	_, err := core.MergeSources(core.MergeReject, layers)
+	if err == nil || !strings.Contains(err.Error(), "org/base") || !strings.Contains(err.Error(), "env/prod") {
+		t.Fatalf("expected collision naming both sources, got %v", err)
+	}
+
+	if _, err := core.MergeSources(core.MergeReject, layers[:1]); err != nil {
+		t.Fatalf("expected no collision for a single source: %v", err)
+	}
+}
+
+func TestSourceLayersAndValidation(t *testing.T) {
+	spec := &core.ConfigPropagationSpec{
+		SourceRef:         core.ObjectRef{Namespace: "org", Name: "base"},
+		NamespaceSelector: &core.LabelSelector{},
+		DataKeys:          []string{"a"},
+		Sources:           []core.SourceSpec{{Namespace: "env", Name: "prod", DataKeys: []string{"b"}}},
+	}
+
+	want := []core.SourceSpec{{Namespace: "org", Name: "base", DataKeys: []string{"a"}}, {Namespace: "env", Name: "prod", DataKeys: []string{"b"}}}
+	if got := core.SourceLayers(spec); !reflect.DeepEqual(got, want) {
+		t.Fatalf("unexpected layers %+v", got)
+	}
+
+	core.DefaultSpec(spec)
+	if spec.MergeMode != core.MergeOverride {
+		t.Fatalf("expected default mergeMode override, got %q", spec.MergeMode)
+	}
+	if err := core.ValidateSpec(spec); err != nil {
+		t.Fatalf("unexpected validation error: %v", err)
+	}
+
+	invalid := map[string]func(*core.ConfigPropagationSpec){
+		"merge mode":        func(s *core.ConfigPropagationSpec) { s.MergeMode = "union" },
+		"missing name":      func(s *core.ConfigPropagationSpec) { s.Sources = []core.SourceSpec{{Namespace: "env"}} },
+		"duplicate of base": func(s *core.ConfigPropagationSpec) { s.Sources = []core.SourceSpec{{Namespace: "org", Name: "base"}} },
+		"duplicate overlay": func(s *core.ConfigPropagationSpec) {
+			s.Sources = []core.SourceSpec{{Namespace: "env", Name: "prod"}, {Namespace: "env", Name: "prod"}}
+		},
+	}
+	for name, mutate := range invalid {
+		candidate := *spec
+		mutate(&candidate)
+		if err := core.ValidateSpec(&candidate); err == nil {
+			t.Fatalf("%s: expected validation error", name)
+		}
+	}
+}
@@ -0,0 +1,199 @@
+package core
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"text/template"
+)
+
+// maxConfigMapNameLength is the Kubernetes limit for DNS-1123 subdomain names.
+const maxConfigMapNameLength = 253
+
+// maxQualifiedNameLength bounds the name part of label and annotation keys, and label values.
+const maxQualifiedNameLength = 63
+
+// managedKeyDomain is the key prefix reserved for metadata the controller manages itself.
+const managedKeyDomain = "configpropagator.platform.example.com"
+
+var configMapNamePattern = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?(\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*$`)
+
+var qualifiedNamePattern = regexp.MustCompile(`^([A-Za-z0-9][-A-Za-z0-9_.]*)?[A-Za-z0-9]$`)
+
+// TargetNameValues are the variables available to spec.target.nameTemplate.
+type TargetNameValues struct {
+	SourceName      string
+	SourceNamespace string
+	Namespace       string
+}
+
+// TargetNamer renders the target ConfigMap name for each namespace of a spec.
+type TargetNamer struct {
+	sourceRef    ObjectRef
+	fixedName    string
+	nameTemplate *template.Template
+}
+
+// NewTargetNamer prepares the naming rules of a spec, parsing the name template once.
+func NewTargetNamer(spec *ConfigPropagationSpec) (*TargetNamer, error) {
+	namer := &TargetNamer{sourceRef: spec.SourceRef, fixedName: spec.SourceRef.Name}
+
+	if spec.Target == nil {
+		return namer, nil
+	}
+
+	if spec.Target.Name != "" {
+		namer.fixedName = spec.Target.Name
+	}
+
+	if spec.Target.NameTemplate != "" {
+		nameTemplate, err := parseNameTemplate(spec.Target.NameTemplate)
+		if err != nil {
+			return nil, err
+		}
+
+		namer.nameTemplate = nameTemplate
+	}
+
+	return namer, nil
+}
+
+// Name returns the target ConfigMap name for the namespace.
+func (namer *TargetNamer) Name(namespace string) (string, error) {
+	if namer.nameTemplate == nil {
+		return namer.fixedName, nil
+	}
+
+	values := TargetNameValues{SourceName: namer.sourceRef.Name, SourceNamespace: namer.sourceRef.Namespace, Namespace: namespace}
+
+	builder := strings.Builder{}
+	if err := namer.nameTemplate.Execute(&builder, values); err != nil {
+		return "", fmt.Errorf("render target.nameTemplate: %w", err)
+	}
+
+	renderedName := strings.TrimSpace(builder.String())
+	if err := validateConfigMapName(renderedName); err != nil {
+		return "", fmt.Errorf("rendered target name %q: %w", renderedName, err)
+	}
+
+	return renderedName, nil
+}
+
+// validateTarget checks the target naming fields of a spec.
+func validateTarget(target *TargetSpec) error {
+	if target == nil {
+		return nil
+	}
+
+	if target.Name != "" && target.NameTemplate != "" {
+		return fmt.Errorf("target.name and target.nameTemplate are mutually exclusive")
+	}
+
+	if target.Name != "" {
+		if err := validateConfigMapName(target.Name); err != nil {
+			return fmt.Errorf("invalid target.name: %w", err)
+		}
+	}
+
+	if target.NameTemplate != "" {
+		nameTemplate, err := parseNameTemplate(target.NameTemplate)
+		if err != nil {
+			return err
+		}
+
+		// Render with representative values so unknown fields are caught at admission time.
+		sample := TargetNameValues{SourceName: "source", SourceNamespace: "source-namespace", Namespace: "namespace"}
+		if err := nameTemplate.Execute(&strings.Builder{}, sample); err != nil {
+			return fmt.Errorf("invalid target.nameTemplate: %w", err)
+		}
+	}
+
+	for _, labelKey := range sortedKeys(target.Labels) {
+		if err := validateMetadataKey(labelKey); err != nil {
+			return fmt.Errorf("invalid target.labels key %q: %w", labelKey, err)
+		}
+
+		if err := validateLabelValue(target.Labels[labelKey]); err != nil {
+			return fmt.Errorf("invalid target.labels[%q]: %w", labelKey, err)
+		}
+	}
+
+	for _, annotationKey := range sortedKeys(target.Annotations) {
+		if err := validateMetadataKey(annotationKey); err != nil {
+			return fmt.Errorf("invalid target.annotations key %q: %w", annotationKey, err)
+		}
+	}
+
+	return nil
+}
+
+// validateMetadataKey checks a label or annotation key against the Kubernetes qualified name rules
+// and rejects the prefix reserved for the controller's own metadata.
+func validateMetadataKey(key string) error {
+	prefix, name, hasPrefix := strings.Cut(key, "/")
+	if !hasPrefix {
+		name = prefix
+	} else {
+		if err := validateConfigMapName(prefix); err != nil {
+			return fmt.Errorf("prefix: %w", err)
+		}
+
+		if prefix == managedKeyDomain || strings.HasSuffix(prefix, "."+managedKeyDomain) {
+			return fmt.Errorf("prefix %s is reserved for the controller", managedKeyDomain)
+		}
+	}
+
+	if len(name) > maxQualifiedNameLength {
+		return fmt.Errorf("name must be no more than %d characters", maxQualifiedNameLength)
+	}
+
+	if !qualifiedNamePattern.MatchString(name) {
+		return fmt.Errorf("name must consist of alphanumeric characters, '-', '_' or '.', and start and end with an alphanumeric character")
+	}
+
+	return nil
+}
+
+// validateLabelValue checks a label value against the Kubernetes rules; empty values are allowed.
+func validateLabelValue(value string) error {
+	if value == "" {
+		return nil
+	}
+
+	if len(value) > maxQualifiedNameLength {
+		return fmt.Errorf("value must be no more than %d characters", maxQualifiedNameLength)
+	}
+
+	if !qualifiedNamePattern.MatchString(value) {
+		return fmt.Errorf("value must consist of alphanumeric characters, '-', '_' or '.', and start and end with an alphanumeric character")
+	}
+
+	return nil
+}
+
+// parseNameTemplate parses a target name template, rejecting references to unknown values.
+func parseNameTemplate(text string) (*template.Template, error) {
+	nameTemplate, err := template.New("target.nameTemplate").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid target.nameTemplate: %w", err)
+	}
+
+	return nameTemplate, nil
+}
+
+// validateConfigMapName checks a ConfigMap name against the DNS-1123 subdomain rules.
+func validateConfigMapName(name string) error {
+	if name == "" {
+		return fmt.Errorf("name must not be empty")
+	}
+
+	if len(name) > maxConfigMapNameLength {
+		return fmt.Errorf("name must be no more than %d characters", maxConfigMapNameLength)
+	}
+
+	if !configMapNamePattern.MatchString(name) {
+		return fmt.Errorf("name must consist of lower case alphanumeric characters, '-' or '.', and start and end with an alphanumeric character")
+	}
+
+	return nil
+}
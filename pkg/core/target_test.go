@@ -0,0 +1,89 @@
+package core_test
+
+import (
+	"strings"
+	"testing"
+
+	core "configpropagation/pkg/core"
+)
+
+func TestTargetNamerDefaultsAndFixedName(t *testing.T) {
+	spec := &core.ConfigPropagationSpec{SourceRef: core.ObjectRef{Namespace: "src", Name: "platform-base"}}
+
+	namer, err := core.NewTargetNamer(spec)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if name, _ := namer.Name("team-a"); name != "platform-base" {
+		t.Fatalf("expected source name by default, got %q", name)
+	}
+
+	spec.Target = &core.TargetSpec{Name: "app-config"}
+	namer, _ = core.NewTargetNamer(spec)
+	if name, _ := namer.Name("team-a"); name != "app-config" {
+		t.Fatalf("expected fixed target name, got %q", name)
+	}
+}
+
+func TestTargetNamerTemplate(t *testing.T) {
+	spec := &core.ConfigPropagationSpec{
+		SourceRef: core.ObjectRef{Namespace: "src", Name: "platform-base"},
+		Target:    &core.TargetSpec{NameTemplate: "{{ .SourceNamespace }}-{{ .SourceName }}-{{ .Namespace }}"},
+	}
+
+	namer, err := core.NewTargetNamer(spec)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if name, err := namer.Name("team-a"); err != nil || name != "src-platform-base-team-a" {
+		t.Fatalf("unexpected rendered name %q err=%v", name, err)
+	}
+
+	spec.Target.NameTemplate = "cfg-{{ .Namespace }}"
+	namer, _ = core.NewTargetNamer(spec)
+	if _, err := namer.Name(strings.Repeat("a", 260)); err == nil {
+		t.Fatalf("expected over-long rendered name to fail")
+	}
+	if _, err := namer.Name("Upper"); err == nil {
+		t.Fatalf("expected invalid rendered name to fail")
+	}
+}
+
+func TestValidateSpecTarget(t *testing.T) {
+	base := func(target *core.TargetSpec) *core.ConfigPropagationSpec {
+		return &core.ConfigPropagationSpec{
+			SourceRef:         core.ObjectRef{Namespace: "src", Name: "cfg"},
+			NamespaceSelector: &core.LabelSelector{},
+			Target:            target,
+		}
+	}
+
+	valid := []*core.TargetSpec{
+		nil, {}, {Name: "app-config"}, {NameTemplate: "{{ .SourceName }}-{{ .Namespace }}"},
+		{Labels: map[string]string{"team": "payments", "example.com/cost-center": "", "app.kubernetes.io/part-of": "shop_1"}},
+		{Annotations: map[string]string{"reloader.stakater.com/match": "true", "note": "free text: any value"}},
+	}
+	for _, target := range valid {
+		if err := core.ValidateSpec(base(target)); err != nil {
+			t.Fatalf("expected %+v to be valid: %v", target, err)
+		}
+	}
+
+	invalid := map[string]*core.TargetSpec{
+		"both set":      {Name: "a", NameTemplate: "b"},
+		"bad name":      {Name: "App_Config"},
+		"syntax error":  {NameTemplate: "{{ .SourceName "},
+		"unknown field": {NameTemplate: "{{ .Cluster }}"},
+		"label key":     {Labels: map[string]string{"-team": "a"}},
+		"label value":   {Labels: map[string]string{"team": "has space"}},
+		"long value":    {Labels: map[string]string{"team": strings.Repeat("a", 64)}},
+		"bad prefix":    {Annotations: map[string]string{"Example.com/x": "y"}},
+		"reserved":      {Labels: map[string]string{core.ManagedLabel: "false"}},
+		"reserved sub":  {Annotations: map[string]string{"team.configpropagator.platform.example.com/x": "y"}},
+	}
+	for name, target := range invalid {
+		if err := core.ValidateSpec(base(target)); err == nil {
+			t.Fatalf("%s: expected validation error", name)
+		}
+	}
+}
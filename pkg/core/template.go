@@ -0,0 +1,114 @@
+package core
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+// templateDomain prefixes the rollout fingerprint of templated content.
+const templateDomain = "configpropagator/template/v1"
+
+// TemplateValues is the data every value template renders against.
+type TemplateValues struct {
+	Namespace   string
+	Labels      map[string]string
+	Annotations map[string]string
+	Values      map[string]string
+}
+
+// DataTemplate renders the effective data of a source once per target namespace.
+type DataTemplate struct {
+	templates  map[string]*template.Template
+	values     map[string]string
+	parseError error
+}
+
+// templateFunctions is the restricted function set offered to value templates.
+// It only transforms strings; nothing reaches the environment, files or the network.
+var templateFunctions = template.FuncMap{
+	"default": func(fallback, value string) string {
+		if value == "" {
+			return fallback
+		}
+		return value
+	},
+	"upper":      strings.ToUpper,
+	"lower":      strings.ToLower,
+	"trim":       strings.TrimSpace,
+	"trimPrefix": func(prefix, value string) string { return strings.TrimPrefix(value, prefix) },
+	"trimSuffix": func(suffix, value string) string { return strings.TrimSuffix(value, suffix) },
+	"replace":    func(old, new, value string) string { return strings.ReplaceAll(value, old, new) },
+	"quote":      func(value string) string { return fmt.Sprintf("%q", value) },
+}
+
+// NewDataTemplate parses every data value as a template when templating is enabled, and returns nil otherwise.
+// A parse error is kept and returned by Render, so it is reported against each target namespace.
+func NewDataTemplate(spec *TemplateSpec, data map[string]string) *DataTemplate {
+	if spec == nil || !spec.Enabled {
+		return nil
+	}
+
+	dataTemplate := &DataTemplate{templates: make(map[string]*template.Template, len(data)), values: spec.Values}
+
+	for _, key := range sortedKeys(data) {
+		parsed, err := template.New(key).Option("missingkey=error").Funcs(templateFunctions).Parse(data[key])
+		if err != nil {
+			dataTemplate.parseError = fmt.Errorf("parse template for key %q: %w", key, err)
+			break
+		}
+
+		dataTemplate.templates[key] = parsed
+	}
+
+	return dataTemplate
+}
+
+// Render executes the value templates for one namespace.
+func (dataTemplate *DataTemplate) Render(namespace string, labels, annotations map[string]string) (map[string]string, error) {
+	if dataTemplate.parseError != nil {
+		return nil, dataTemplate.parseError
+	}
+
+	values := TemplateValues{
+		Namespace:   namespace,
+		Labels:      nonNilMap(labels),
+		Annotations: nonNilMap(annotations),
+		Values:      nonNilMap(dataTemplate.values),
+	}
+
+	rendered := make(map[string]string, len(dataTemplate.templates))
+
+	for _, key := range sortedKeys(dataTemplate.templates) {
+		var output strings.Builder
+		if err := dataTemplate.templates[key].Execute(&output, values); err != nil {
+			return nil, fmt.Errorf("render template for key %q: %w", key, err)
+		}
+
+		rendered[key] = output.String()
+	}
+
+	return rendered, nil
+}
+
+// Fingerprint combines the unrendered content hash with the template values so the rollout
+// restarts when either changes, even though every target records the hash of its own rendering.
+func (dataTemplate *DataTemplate) Fingerprint(contentHash string) string {
+	hasher := sha256.New()
+	writeLengthPrefixed(hasher, []byte(templateDomain))
+	writeLengthPrefixed(hasher, []byte(contentHash))
+	writeLengthPrefixed(hasher, []byte(hashStringData(dataTemplate.values)))
+
+	return hex.EncodeToString(hasher.Sum(nil))
+}
+
+// nonNilMap gives templates an empty map for absent metadata so range and index behave the same everywhere.
+func nonNilMap(values map[string]string) map[string]string {
+	if values == nil {
+		return map[string]string{}
+	}
+
+	return values
+}
@@ -0,0 +1,76 @@
+package core_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	core "configpropagation/pkg/core"
+)
+
+func TestDataTemplateDisabled(t *testing.T) {
+	if core.NewDataTemplate(nil, map[string]string{"a": "{{ .Namespace }}"}) != nil {
+		t.Fatalf("expected no template without spec.template")
+	}
+
+	if core.NewDataTemplate(&core.TemplateSpec{Values: map[string]string{"x": "y"}}, nil) != nil {
+		t.Fatalf("expected no template unless enabled")
+	}
+}
+
+func TestDataTemplateRender(t *testing.T) {
+	dataTemplate := core.NewDataTemplate(&core.TemplateSpec{Enabled: true, Values: map[string]string{"domain": "example.com"}}, map[string]string{
+		"endpoint": "https://{{ .Namespace }}.{{ .Values.domain }}",
+		"tier":     `{{ default "standard" (index .Labels "tier") | upper }}`,
+		"owner":    `{{ index .Annotations "owner" | quote }}`,
+		"plain":    "no templating here",
+	})
+
+	rendered, err := dataTemplate.Render("payments", map[string]string{"tier": "gold"}, map[string]string{"owner": "team-a"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := map[string]string{
+		"endpoint": "https://payments.example.com",
+		"tier":     "GOLD",
+		"owner":    `"team-a"`,
+		"plain":    "no templating here",
+	}
+	if !reflect.DeepEqual(rendered, want) {
+		t.Fatalf("unexpected rendering %+v", rendered)
+	}
+
+	rendered, err = dataTemplate.Render("search", nil, nil)
+	if err != nil || rendered["tier"] != "STANDARD" {
+		t.Fatalf("expected default tier without labels, got %+v %v", rendered, err)
+	}
+}
+
+func TestDataTemplateErrors(t *testing.T) {
+	missingValue := core.NewDataTemplate(&core.TemplateSpec{Enabled: true}, map[string]string{"region": "{{ .Values.region }}"})
+	if _, err := missingValue.Render("team-a", nil, nil); err == nil || !strings.Contains(err.Error(), `key "region"`) {
+		t.Fatalf("expected missing value error, got %v", err)
+	}
+
+	unparsable := core.NewDataTemplate(&core.TemplateSpec{Enabled: true}, map[string]string{"broken": "{{ .Namespace"})
+	if _, err := unparsable.Render("team-a", nil, nil); err == nil || !strings.Contains(err.Error(), "parse template") {
+		t.Fatalf("expected parse error, got %v", err)
+	}
+
+	restricted := core.NewDataTemplate(&core.TemplateSpec{Enabled: true}, map[string]string{"env": `{{ env "HOME" }}`})
+	if _, err := restricted.Render("team-a", nil, nil); err == nil {
+		t.Fatalf("expected functions outside the restricted set to be rejected")
+	}
+}
+
+func TestDataTemplateFingerprintTracksValues(t *testing.T) {
+	data := map[string]string{"a": "{{ .Values.x }}"}
+	first := core.NewDataTemplate(&core.TemplateSpec{Enabled: true, Values: map[string]string{"x": "1"}}, data)
+	second := core.NewDataTemplate(&core.TemplateSpec{Enabled: true, Values: map[string]string{"x": "2"}}, data)
+
+	contentHash := core.HashData(data, nil)
+	if first.Fingerprint(contentHash) == second.Fingerprint(contentHash) || first.Fingerprint(contentHash) == contentHash {
+		t.Fatalf("expected the fingerprint to change with template values")
+	}
+}
@@ -4,11 +4,52 @@ package core
 type ConfigPropagationSpec struct {
 	SourceRef           ObjectRef       `json:"sourceRef"`
 	NamespaceSelector   *LabelSelector  `json:"namespaceSelector"`
-	DataKeys            []string        `json:"dataKeys,omitempty"`
+	DataKeys            []string        `json:"dataKeys,omitempty"`    // exact keys, globs or /regex/
+	ExcludeKeys         []string        `json:"excludeKeys,omitempty"` // same syntax as dataKeys; wins over dataKeys
 	Strategy            *UpdateStrategy `json:"strategy,omitempty"`
 	ConflictPolicy      string          `json:"conflictPolicy,omitempty"`
 	Prune               *bool           `json:"prune,omitempty"`
 	ResyncPeriodSeconds *int32          `json:"resyncPeriodSeconds,omitempty"`
+	Target              *TargetSpec     `json:"target,omitempty"`
+	Sources             []SourceSpec    `json:"sources,omitempty"` // overlays applied over sourceRef in order
+	MergeMode           string          `json:"mergeMode,omitempty"`
+	KeyMappings         []KeyMapping    `json:"keyMappings,omitempty"`
+	Template            *TemplateSpec   `json:"template,omitempty"`
+	DryRun              bool            `json:"dryRun,omitempty"` // report status.plan instead of writing targets
+	Paused              bool            `json:"paused,omitempty"` // stop reconciling until unset; deletion still cleans up
+}
+
+// TemplateSpec opts into rendering every propagated data value as a Go text/template per target namespace.
+type TemplateSpec struct {
+	Enabled bool              `json:"enabled,omitempty"`
+	Values  map[string]string `json:"values,omitempty"` // cluster-wide values exposed as .Values
+}
+
+// KeyMapping renames source keys on their way to the targets. Each mapping sets exactly one rule:
+// an exact rename (from/to), a prefix rewrite (stripPrefix and/or addPrefix) or a regex rewrite (regex/replacement).
+type KeyMapping struct {
+	From        string `json:"from,omitempty"`
+	To          string `json:"to,omitempty"`
+	StripPrefix string `json:"stripPrefix,omitempty"`
+	AddPrefix   string `json:"addPrefix,omitempty"`
+	Regex       string `json:"regex,omitempty"`
+	Replacement string `json:"replacement,omitempty"`
+}
+
+// SourceSpec is an additional source ConfigMap layered over sourceRef.
+type SourceSpec struct {
+	Namespace   string   `json:"namespace"`
+	Name        string   `json:"name"`
+	DataKeys    []string `json:"dataKeys,omitempty"`
+	ExcludeKeys []string `json:"excludeKeys,omitempty"`
+}
+
+// TargetSpec controls how target ConfigMaps are named in each namespace and which extra metadata they carry.
+type TargetSpec struct {
+	Name         string            `json:"name,omitempty"`         // fixed name; defaults to sourceRef.name
+	NameTemplate string            `json:"nameTemplate,omitempty"` // text/template over SourceName, SourceNamespace, Namespace
+	Labels       map[string]string `json:"labels,omitempty"`       // stamped on every target next to the managed label
+	Annotations  map[string]string `json:"annotations,omitempty"`  // stamped on every target next to the managed annotations
 }
 
 // ObjectRef references a namespaced object (ConfigMap source).
@@ -43,7 +84,48 @@ type ConfigPropagationStatus struct {
 	SyncedCount    int32           `json:"syncedCount,omitempty"`
 	OutOfSyncCount int32           `json:"outOfSyncCount,omitempty"`
 	OutOfSync      []OutOfSyncItem `json:"outOfSync,omitempty"`
-	LastSyncTime   string          `json:"lastSyncTime,omitempty"` // RFC3339
+	// CleanupFailures lists targets of deselected namespaces, or of renamed targets, that could not be removed.
+	CleanupFailures []OutOfSyncItem `json:"cleanupFailures,omitempty"`
+	LastSyncTime    string          `json:"lastSyncTime,omitempty"` // RFC3339
+	Rollout         *RolloutStatus  `json:"rollout,omitempty"`
+	MatchedKeys     []SourceKeys    `json:"matchedKeys,omitempty"`
+	Plan            *PlanStatus     `json:"plan,omitempty"` // set while spec.dryRun is true
+}
+
+// PlanStatus summarizes what reconciling the spec would change, computed by a dry run that writes nothing.
+type PlanStatus struct {
+	TargetCount int32           `json:"targetCount"`
+	Create      int32           `json:"create"`
+	Update      int32           `json:"update"`
+	Prune       int32           `json:"prune"`
+	Detach      int32           `json:"detach"`
+	Skip        int32           `json:"skip"`
+	Unchanged   int32           `json:"unchanged"`
+	Pending     int32           `json:"pending,omitempty"` // namespaces left for later rolling batches
+	Targets     []PlannedTarget `json:"targets,omitempty"` // unchanged targets are only counted
+}
+
+// PlannedTarget is the action a reconcile would take on one target ConfigMap.
+type PlannedTarget struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name,omitempty"`
+	Action    string `json:"action"`           // Create|Update|Prune|Detach|Skip
+	Reason    string `json:"reason,omitempty"` // out-of-sync reason of a skipped target
+	Message   string `json:"message,omitempty"`
+}
+
+// SourceKeys lists the source keys selected by the dataKeys and excludeKeys of one filtered source.
+type SourceKeys struct {
+	Source string   `json:"source"` // namespace/name
+	Keys   []string `json:"keys"`
+}
+
+// RolloutStatus persists rolling rollout progress so a new leader can resume it.
+type RolloutStatus struct {
+	Hash                string   `json:"hash"`
+	CompletedNamespaces []string `json:"completedNamespaces,omitempty"`
+	CurrentBatch        []string `json:"currentBatch,omitempty"`
+	StartedAt           string   `json:"startedAt,omitempty"` // RFC3339
 }
 
 // Condition is a standard status condition.
@@ -38,6 +38,22 @@ func ValidateSpec(spec *ConfigPropagationSpec) error {
 		return fmt.Errorf("resyncPeriodSeconds must be >= 10")
 	}
 
+	if err := validateTarget(spec.Target); err != nil {
+		return err
+	}
+
+	if err := validateSources(spec); err != nil {
+		return err
+	}
+
+	if err := validateKeySelection(spec); err != nil {
+		return err
+	}
+
+	if err := validateKeyMappings(spec.KeyMappings); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -60,6 +76,10 @@ func DefaultSpec(spec *ConfigPropagationSpec) {
 		spec.ConflictPolicy = ConflictOverwrite
 	}
 
+	if spec.MergeMode == "" {
+		spec.MergeMode = MergeOverride
+	}
+
 	if spec.Prune == nil {
 		shouldPrune := true
 		spec.Prune = &shouldPrune
@@ -0,0 +1,67 @@
+package core
+
+import (
+	"os"
+	"strconv"
+	"sync"
+)
+
+// defaultTargetWorkers matches the chart default for env.workers.
+const defaultTargetWorkers = 4
+
+// DefaultTargetWorkers reads from WORKERS how many targets of one batch a reconcile writes concurrently.
+func DefaultTargetWorkers() int {
+	if environmentValue := os.Getenv("WORKERS"); environmentValue != "" {
+		if parsed, err := strconv.Atoi(environmentValue); err == nil && parsed >= 1 {
+			return parsed
+		}
+	}
+
+	return defaultTargetWorkers
+}
+
+// ForEachBounded calls work for every index below count on at most workers goroutines and waits for them.
+// Indexes are started in ascending order; once stop reports true no further index is started.
+// Callers that need deterministic output store results by index rather than in completion order.
+func ForEachBounded(count, workers int, stop func() bool, work func(index int)) {
+	if workers < 1 {
+		workers = 1
+	}
+
+	if workers > count {
+		workers = count
+	}
+
+	var (
+		mutex     sync.Mutex
+		nextIndex int
+		waitGroup sync.WaitGroup
+	)
+
+	claim := func() (int, bool) {
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		if nextIndex >= count || stop() {
+			return 0, false
+		}
+
+		index := nextIndex
+		nextIndex++
+		return index, true
+	}
+
+	waitGroup.Add(workers)
+
+	for worker := 0; worker < workers; worker++ {
+		go func() {
+			defer waitGroup.Done()
+
+			for index, ok := claim(); ok; index, ok = claim() {
+				work(index)
+			}
+		}()
+	}
+
+	waitGroup.Wait()
+}
@@ -0,0 +1,77 @@
+package core_test
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	core "configpropagation/pkg/core"
+)
+
+func TestForEachBoundedVisitsEveryIndexWithinTheBound(t *testing.T) {
+	const count, workers = 40, 4
+
+	var inFlight, peak int32
+	visits := make([]int32, count)
+
+	core.ForEachBounded(count, workers, func() bool { return false }, func(index int) {
+		current := atomic.AddInt32(&inFlight, 1)
+		for {
+			observed := atomic.LoadInt32(&peak)
+			if current <= observed || atomic.CompareAndSwapInt32(&peak, observed, current) {
+				break
+			}
+		}
+
+		time.Sleep(time.Millisecond)
+		atomic.AddInt32(&visits[index], 1)
+		atomic.AddInt32(&inFlight, -1)
+	})
+
+	for index, visited := range visits {
+		if visited != 1 {
+			t.Fatalf("index %d visited %d times", index, visited)
+		}
+	}
+
+	if peak < 2 || peak > workers {
+		t.Fatalf("expected between 2 and %d concurrent calls, saw %d", workers, peak)
+	}
+}
+
+func TestForEachBoundedStopsStartingWork(t *testing.T) {
+	var mutex sync.Mutex
+	var started []int
+
+	core.ForEachBounded(10, 1, func() bool {
+		mutex.Lock()
+		defer mutex.Unlock()
+		return len(started) == 3
+	}, func(index int) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		started = append(started, index)
+	})
+
+	if len(started) != 3 || started[0] != 0 || started[2] != 2 {
+		t.Fatalf("expected indexes 0-2 before stopping, got %v", started)
+	}
+}
+
+func TestDefaultTargetWorkers(t *testing.T) {
+	t.Setenv("WORKERS", "")
+	if workers := core.DefaultTargetWorkers(); workers != 4 {
+		t.Fatalf("expected default of 4 workers, got %d", workers)
+	}
+
+	t.Setenv("WORKERS", "12")
+	if workers := core.DefaultTargetWorkers(); workers != 12 {
+		t.Fatalf("expected WORKERS to be honored, got %d", workers)
+	}
+
+	t.Setenv("WORKERS", "0")
+	if workers := core.DefaultTargetWorkers(); workers != 4 {
+		t.Fatalf("expected invalid WORKERS to fall back to the default, got %d", workers)
+	}
+}
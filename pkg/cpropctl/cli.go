@@ -0,0 +1,197 @@
+// Package cpropctl implements the commands of the cpropctl CLI on top of the same core and adapter packages the
+// manager uses, so what the CLI reports matches what the controller would do.
+package cpropctl
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"configpropagation/pkg/adapters"
+	configv1alpha1 "configpropagation/pkg/api/v1alpha1"
+	"configpropagation/pkg/controllers/configpropagation"
+	"configpropagation/pkg/core"
+)
+
+// shortHashLength is how many characters of a content hash the tables show.
+const shortHashLength = 12
+
+// CLI runs cpropctl commands against a Kubernetes client and prints their results.
+type CLI struct {
+	// Client talks to the API server directly; the field indexes of the manager's cache are not required.
+	Client client.Client
+	// Out receives the command output.
+	Out io.Writer
+	// RequireSourceOptIn mirrors the manager flag of the same name for diff and plan.
+	RequireSourceOptIn bool
+}
+
+// NewCLI returns a CLI that prints to out and, like the manager by default, requires the source opt-in.
+func NewCLI(kubeClient client.Client, out io.Writer) *CLI {
+	return &CLI{Client: kubeClient, Out: out, RequireSourceOptIn: true}
+}
+
+// kubeClient returns the adapter the reconciler reads through, listing managed targets without cache indexes.
+func (cli *CLI) kubeClient() adapters.KubeClient {
+	return adapters.NewControllerRuntimeClient(cli.Client, adapters.ClientOptions{UnindexedTargetLookup: true})
+}
+
+// reconciler returns a reconciler over kubeClient for the read-only Diff and PlanNextBatch.
+func (cli *CLI) reconciler() *configpropagation.Reconciler {
+	reconciler := configpropagation.NewReconciler(cli.kubeClient(), nil, nil)
+	reconciler.RequireSourceOptIn(cli.RequireSourceOptIn)
+
+	return reconciler
+}
+
+// getConfigPropagation reads one ConfigPropagation.
+func (cli *CLI) getConfigPropagation(requestContext context.Context, namespace, name string) (*configv1alpha1.ConfigPropagation, error) {
+	var configPropagation configv1alpha1.ConfigPropagation
+
+	if err := cli.Client.Get(requestContext, types.NamespacedName{Namespace: namespace, Name: name}, &configPropagation); err != nil {
+		return nil, fmt.Errorf("get ConfigPropagation %s/%s: %w", namespace, name, err)
+	}
+
+	return &configPropagation, nil
+}
+
+// reconcileKey identifies the ConfigPropagation to the reconciler, including its UID for ownership checks.
+func reconcileKey(configPropagation *configv1alpha1.ConfigPropagation) configpropagation.Key {
+	return configpropagation.Key{Namespace: configPropagation.Namespace, Name: configPropagation.Name, UID: string(configPropagation.UID)}
+}
+
+// Diff prints, per selected target, the keys whose live value differs from what the sources produce, and reports
+// whether any target differs.
+func (cli *CLI) Diff(requestContext context.Context, namespace, name string) (bool, error) {
+	configPropagation, err := cli.getConfigPropagation(requestContext, namespace, name)
+	if err != nil {
+		return false, err
+	}
+
+	differences, err := cli.reconciler().Diff(requestContext, reconcileKey(configPropagation), &configPropagation.Spec)
+	if err != nil {
+		return false, err
+	}
+
+	differing := 0
+	for _, difference := range differences {
+		if difference.Err == nil && difference.Found && len(difference.Keys) == 0 {
+			continue
+		}
+		differing++
+
+		target := difference.Namespace + "/" + difference.Name
+		switch {
+		case difference.Err != nil:
+			fmt.Fprintf(cli.Out, "%s: %v\n", target, difference.Err)
+			continue
+		case !difference.Found:
+			fmt.Fprintf(cli.Out, "%s (missing):\n", target)
+		default:
+			fmt.Fprintf(cli.Out, "%s:\n", target)
+		}
+
+		for _, keyDifference := range difference.Keys {
+			if keyDifference.Change != core.DiffMissing {
+				fmt.Fprintf(cli.Out, "-  %s: %s\n", keyDifference.Key, keyDifference.Live)
+			}
+			if keyDifference.Change != core.DiffExtra {
+				fmt.Fprintf(cli.Out, "+  %s: %s\n", keyDifference.Key, keyDifference.Desired)
+			}
+		}
+	}
+
+	fmt.Fprintf(cli.Out, "%d of %d targets differ\n", differing, len(differences))
+	return differing > 0, nil
+}
+
+// Plan prints what the next reconcile would write, resuming the rollout progress recorded in status.
+func (cli *CLI) Plan(requestContext context.Context, namespace, name string) error {
+	configPropagation, err := cli.getConfigPropagation(requestContext, namespace, name)
+	if err != nil {
+		return err
+	}
+
+	plan, err := cli.reconciler().PlanNextBatch(requestContext, reconcileKey(configPropagation), &configPropagation.Spec, configPropagation.Status.Rollout)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintln(cli.Out, plan.Summary())
+	if len(plan.Targets) == 0 {
+		return nil
+	}
+
+	table := newTable(cli.Out, "NAMESPACE", "TARGET", "ACTION", "REASON", "MESSAGE")
+	for _, target := range plan.Targets {
+		table.row(target.Namespace, target.Name, target.Action, target.Reason, target.Message)
+	}
+
+	return table.flush()
+}
+
+// Pause sets spec.paused so the controller stops writing targets for the ConfigPropagation.
+func (cli *CLI) Pause(requestContext context.Context, namespace, name string) error {
+	return cli.setPaused(requestContext, namespace, name, true)
+}
+
+// Resume clears spec.paused so the controller reconciles the ConfigPropagation again.
+func (cli *CLI) Resume(requestContext context.Context, namespace, name string) error {
+	return cli.setPaused(requestContext, namespace, name, false)
+}
+
+// setPaused merge-patches spec.paused, removing the field on resume.
+func (cli *CLI) setPaused(requestContext context.Context, namespace, name string, paused bool) error {
+	configPropagation, err := cli.getConfigPropagation(requestContext, namespace, name)
+	if err != nil {
+		return err
+	}
+
+	patch := []byte(`{"spec":{"paused":null}}`)
+	verb := "resumed"
+	if paused {
+		patch = []byte(`{"spec":{"paused":true}}`)
+		verb = "paused"
+	}
+
+	if err := cli.Client.Patch(requestContext, configPropagation, client.RawPatch(types.MergePatchType, patch)); err != nil {
+		return fmt.Errorf("patch ConfigPropagation %s/%s: %w", namespace, name, err)
+	}
+
+	fmt.Fprintf(cli.Out, "configpropagation %s/%s %s\n", namespace, name, verb)
+	return nil
+}
+
+// table prints tab-aligned columns, showing empty cells as "-".
+type table struct {
+	writer *tabwriter.Writer
+}
+
+// newTable starts a table with the given header.
+func newTable(out io.Writer, header ...string) *table {
+	printedTable := &table{writer: tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)}
+	printedTable.row(header...)
+
+	return printedTable
+}
+
+// row prints one table row.
+func (printedTable *table) row(cells ...string) {
+	for index, cell := range cells {
+		if cell == "" {
+			cells[index] = "-"
+		}
+	}
+
+	fmt.Fprintln(printedTable.writer, strings.Join(cells, "\t"))
+}
+
+// flush writes the aligned table.
+func (printedTable *table) flush() error {
+	return printedTable.writer.Flush()
+}
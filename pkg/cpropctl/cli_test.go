@@ -0,0 +1,230 @@
+package cpropctl
+
+import (
+	"bytes"
+	"context"
+	"regexp"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"configpropagation/pkg/adapters"
+	configv1alpha1 "configpropagation/pkg/api/v1alpha1"
+	"configpropagation/pkg/controllers/configpropagation"
+	"configpropagation/pkg/core"
+)
+
+// cliScheme registers the types cpropctl reads, as the command does.
+func cliScheme(t *testing.T) *runtime.Scheme {
+	t.Helper()
+
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatalf("add core scheme: %v", err)
+	}
+	if err := configv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatalf("add configpropagation scheme: %v", err)
+	}
+
+	return scheme
+}
+
+// propagatedCluster returns a fake cluster where team-a/cp has propagated platform/base to app-1 and app-2, after
+// which app-2's copy was edited by hand and app-3 joined the selection.
+func propagatedCluster(t *testing.T) client.Client {
+	t.Helper()
+
+	configPropagation := &configv1alpha1.ConfigPropagation{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "team-a", Name: "cp", UID: "uid-1"},
+		Spec: core.ConfigPropagationSpec{
+			SourceRef:         core.ObjectRef{Namespace: "platform", Name: "base"},
+			NamespaceSelector: &core.LabelSelector{MatchLabels: map[string]string{"team": "a"}},
+			Strategy:          &core.UpdateStrategy{Type: core.StrategyImmediate},
+		},
+		Status: core.ConfigPropagationStatus{
+			TargetCount:     2,
+			SyncedCount:     2,
+			OutOfSync:       []core.OutOfSyncItem{{Namespace: "app-9", Reason: core.ReasonNamespaceNotFound, Message: "namespace app-9 was deleted"}},
+			CleanupFailures: []core.OutOfSyncItem{{Namespace: "app-8", Reason: core.ReasonForbidden, Message: "delete app-8/base: forbidden"}},
+		},
+	}
+
+	objects := []client.Object{
+		configPropagation,
+		&corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "platform", Name: "base", Annotations: map[string]string{core.AllowFromNamespacesAnnotation: "team-a"}},
+			Data:       map[string]string{"level": "info", "region": "eu"},
+		},
+	}
+	for _, namespace := range []string{"app-1", "app-2"} {
+		objects = append(objects, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: namespace, Labels: map[string]string{"team": "a"}}})
+	}
+
+	kubeClient := fake.NewClientBuilder().WithScheme(cliScheme(t)).WithObjects(objects...).Build()
+
+	reconciler := configpropagation.NewReconciler(adapters.NewControllerRuntimeClient(kubeClient, adapters.ClientOptions{UnindexedTargetLookup: true}), nil, nil)
+	key := configpropagation.Key{Namespace: "team-a", Name: "cp", UID: "uid-1"}
+	if _, err := reconciler.Reconcile(context.Background(), key, &configPropagation.DeepCopy().Spec); err != nil {
+		t.Fatalf("propagate: %v", err)
+	}
+
+	var edited corev1.ConfigMap
+	if err := kubeClient.Get(context.Background(), client.ObjectKey{Namespace: "app-2", Name: "base"}, &edited); err != nil {
+		t.Fatalf("get target: %v", err)
+	}
+	edited.Data["level"] = "debug"
+	edited.Data["local"] = "yes"
+	if err := kubeClient.Update(context.Background(), &edited); err != nil {
+		t.Fatalf("edit target: %v", err)
+	}
+
+	newNamespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "app-3", Labels: map[string]string{"team": "a"}}}
+	if err := kubeClient.Create(context.Background(), newNamespace); err != nil {
+		t.Fatalf("create namespace: %v", err)
+	}
+
+	return kubeClient
+}
+
+// tableRows splits printed table rows into their cells.
+func tableRows(output string) [][]string {
+	var rows [][]string
+
+	for _, line := range strings.Split(strings.TrimSpace(output), "\n") {
+		rows = append(rows, regexp.MustCompile(`\s{2,}`).Split(strings.TrimSpace(line), -1))
+	}
+
+	return rows
+}
+
+// hasRow reports whether a printed row starts with the given cells.
+func hasRow(output string, cells ...string) bool {
+	for _, row := range tableRows(output) {
+		if len(row) >= len(cells) && strings.Join(row[:len(cells)], "|") == strings.Join(cells, "|") {
+			return true
+		}
+	}
+
+	return false
+}
+
+func TestStatusReportsEveryTargetState(t *testing.T) {
+	var out bytes.Buffer
+	cli := NewCLI(propagatedCluster(t), &out)
+
+	if err := cli.Status(context.Background(), "team-a", "cp"); err != nil {
+		t.Fatalf("status: %v", err)
+	}
+
+	output := out.String()
+	for _, row := range [][]string{
+		{"NAMESPACE", "TARGET", "STATE", "HASH", "MESSAGE"},
+		{"app-2", "base", "Edited"},
+		{"app-3", "base", "Missing", "-", "-"},
+		{"app-9", "-", core.ReasonNamespaceNotFound, "-", "namespace app-9 was deleted"},
+		{"app-8", "-", core.ReasonForbidden, "-", "cleanup: delete app-8/base: forbidden"},
+	} {
+		if !hasRow(output, row...) {
+			t.Fatalf("expected row %v in:\n%s", row, output)
+		}
+	}
+
+	hash := core.HashData(map[string]string{"level": "info", "region": "eu"}, nil)
+	if !hasRow(output, "app-1", "base", "Synced", hash[:shortHashLength]) {
+		t.Fatalf("expected app-1 to be synced with its short hash in:\n%s", output)
+	}
+}
+
+func TestDiffAndPlanReadWithoutWriting(t *testing.T) {
+	kubeClient := propagatedCluster(t)
+
+	var out bytes.Buffer
+	cli := NewCLI(kubeClient, &out)
+
+	differs, err := cli.Diff(context.Background(), "team-a", "cp")
+	if err != nil || !differs {
+		t.Fatalf("expected targets to differ, got %v %v", differs, err)
+	}
+
+	want := "app-2/base:\n-  level: debug\n+  level: info\n-  local: yes\napp-3/base (missing):\n+  level: info\n+  region: eu\n2 of 3 targets differ\n"
+	if out.String() != want {
+		t.Fatalf("unexpected diff:\n%s\nwant:\n%s", out.String(), want)
+	}
+
+	out.Reset()
+	if err := cli.Plan(context.Background(), "team-a", "cp"); err != nil {
+		t.Fatalf("plan: %v", err)
+	}
+
+	output := out.String()
+	if !strings.HasPrefix(output, "would create 1, update 1, prune 0, detach 0 and skip 0 targets; 1 of 3 selected namespaces unchanged\n") {
+		t.Fatalf("unexpected plan summary:\n%s", output)
+	}
+	if !hasRow(output, "app-2", "base", core.PlanUpdate, "-", "changes keys level, local") || !hasRow(output, "app-3", "base", core.PlanCreate) {
+		t.Fatalf("unexpected plan rows:\n%s", output)
+	}
+
+	var missing corev1.ConfigMap
+	if err := kubeClient.Get(context.Background(), client.ObjectKey{Namespace: "app-3", Name: "base"}, &missing); err == nil {
+		t.Fatalf("expected plan not to create the target")
+	}
+
+	// Without the opt-in annotation the manager would refuse the source, and so do diff and plan.
+	var source corev1.ConfigMap
+	if err := kubeClient.Get(context.Background(), client.ObjectKey{Namespace: "platform", Name: "base"}, &source); err != nil {
+		t.Fatalf("get source: %v", err)
+	}
+	source.Annotations = nil
+	if err := kubeClient.Update(context.Background(), &source); err != nil {
+		t.Fatalf("update source: %v", err)
+	}
+
+	if err := cli.Plan(context.Background(), "team-a", "cp"); err == nil {
+		t.Fatalf("expected plan to refuse a source without opt-in")
+	}
+
+	cli.RequireSourceOptIn = false
+	if _, err := cli.Diff(context.Background(), "team-a", "cp"); err != nil {
+		t.Fatalf("expected diff to read the source when the opt-in is not required: %v", err)
+	}
+}
+
+func TestPauseAndResumeToggleSpecPaused(t *testing.T) {
+	kubeClient := propagatedCluster(t)
+
+	var out bytes.Buffer
+	cli := NewCLI(kubeClient, &out)
+
+	paused := func() bool {
+		t.Helper()
+
+		var configPropagation configv1alpha1.ConfigPropagation
+		if err := kubeClient.Get(context.Background(), client.ObjectKey{Namespace: "team-a", Name: "cp"}, &configPropagation); err != nil {
+			t.Fatalf("get ConfigPropagation: %v", err)
+		}
+
+		return configPropagation.Spec.Paused
+	}
+
+	if err := cli.Pause(context.Background(), "team-a", "cp"); err != nil || !paused() {
+		t.Fatalf("expected the ConfigPropagation to be paused, got %v", err)
+	}
+
+	if err := cli.Resume(context.Background(), "team-a", "cp"); err != nil || paused() {
+		t.Fatalf("expected the ConfigPropagation to be resumed, got %v", err)
+	}
+
+	if out.String() != "configpropagation team-a/cp paused\nconfigpropagation team-a/cp resumed\n" {
+		t.Fatalf("unexpected output:\n%s", out.String())
+	}
+
+	if err := cli.Pause(context.Background(), "team-a", "missing"); err == nil {
+		t.Fatalf("expected pausing a missing ConfigPropagation to fail")
+	}
+}
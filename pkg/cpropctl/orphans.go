@@ -0,0 +1,105 @@
+package cpropctl
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	configv1alpha1 "configpropagation/pkg/api/v1alpha1"
+	"configpropagation/pkg/core"
+)
+
+// Orphan is a managed target ConfigMap that no existing ConfigPropagation will update or clean up.
+type Orphan struct {
+	Namespace string
+	Name      string
+	Owner     string // namespace/name from the owner annotation; empty for targets written before it existed
+	Reason    string
+}
+
+// FindOrphans lists the managed ConfigMaps in the namespace, or in every namespace when it is empty, whose owning
+// ConfigPropagation no longer exists. A ConfigPropagation recreated under the same name does not own its
+// predecessor's targets, as the controller compares owner UIDs. Targets without an owner annotation are orphaned
+// only when no ConfigPropagation propagates their source.
+func (cli *CLI) FindOrphans(requestContext context.Context, namespace string) ([]Orphan, error) {
+	var configMaps corev1.ConfigMapList
+
+	if err := cli.Client.List(requestContext, &configMaps, client.InNamespace(namespace), client.MatchingLabels{core.ManagedLabel: "true"}); err != nil {
+		return nil, fmt.Errorf("list managed ConfigMaps: %w", err)
+	}
+
+	var configPropagations configv1alpha1.ConfigPropagationList
+
+	if err := cli.Client.List(requestContext, &configPropagations); err != nil {
+		return nil, fmt.Errorf("list ConfigPropagations: %w", err)
+	}
+
+	ownerUIDs := map[string]string{}
+	propagatedSources := map[string]struct{}{}
+	for _, configPropagation := range configPropagations.Items {
+		ownerUIDs[configPropagation.Namespace+"/"+configPropagation.Name] = string(configPropagation.UID)
+		propagatedSources[configPropagation.Spec.SourceRef.Namespace+"/"+configPropagation.Spec.SourceRef.Name] = struct{}{}
+	}
+
+	var orphans []Orphan
+	for _, configMap := range configMaps.Items {
+		owner := configMap.Annotations[core.OwnerAnnotation]
+		orphan := Orphan{Namespace: configMap.Namespace, Name: configMap.Name, Owner: owner}
+
+		if owner == "" {
+			source := configMap.Annotations[core.SourceAnnotation]
+			if _, propagated := propagatedSources[source]; propagated {
+				continue
+			}
+
+			orphan.Reason = fmt.Sprintf("no ConfigPropagation propagates source %s", source)
+			orphans = append(orphans, orphan)
+			continue
+		}
+
+		ownerUID, exists := ownerUIDs[owner]
+		recordedUID := configMap.Annotations[core.OwnerUIDAnnotation]
+		switch {
+		case !exists:
+			orphan.Reason = "ConfigPropagation " + owner + " not found"
+		case recordedUID != "" && ownerUID != "" && recordedUID != ownerUID:
+			orphan.Reason = "ConfigPropagation " + owner + " was recreated and does not own this target"
+		default:
+			continue
+		}
+
+		orphans = append(orphans, orphan)
+	}
+
+	sort.Slice(orphans, func(left, right int) bool {
+		if orphans[left].Namespace != orphans[right].Namespace {
+			return orphans[left].Namespace < orphans[right].Namespace
+		}
+		return orphans[left].Name < orphans[right].Name
+	})
+
+	return orphans, nil
+}
+
+// Orphans prints the result of FindOrphans as a table.
+func (cli *CLI) Orphans(requestContext context.Context, namespace string) error {
+	orphans, err := cli.FindOrphans(requestContext, namespace)
+	if err != nil {
+		return err
+	}
+
+	if len(orphans) == 0 {
+		fmt.Fprintln(cli.Out, "no orphaned targets found")
+		return nil
+	}
+
+	table := newTable(cli.Out, "NAMESPACE", "NAME", "OWNER", "REASON")
+	for _, orphan := range orphans {
+		table.row(orphan.Namespace, orphan.Name, orphan.Owner, orphan.Reason)
+	}
+
+	return table.flush()
+}
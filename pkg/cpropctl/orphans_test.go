@@ -0,0 +1,64 @@
+package cpropctl
+
+import (
+	"bytes"
+	"context"
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	configv1alpha1 "configpropagation/pkg/api/v1alpha1"
+	"configpropagation/pkg/core"
+)
+
+// managedTarget builds a managed ConfigMap with the given annotations.
+func managedTarget(namespace, name string, annotations map[string]string) *corev1.ConfigMap {
+	return &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name, Labels: map[string]string{core.ManagedLabel: "true"}, Annotations: annotations}}
+}
+
+func TestFindOrphansReportsTargetsWithoutALivingOwner(t *testing.T) {
+	objects := []client.Object{
+		&configv1alpha1.ConfigPropagation{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "team-a", Name: "cp", UID: "uid-2"},
+			Spec:       core.ConfigPropagationSpec{SourceRef: core.ObjectRef{Namespace: "platform", Name: "base"}},
+		},
+		managedTarget("app-1", "base", map[string]string{core.SourceAnnotation: "platform/base", core.OwnerAnnotation: "team-a/cp", core.OwnerUIDAnnotation: "uid-2"}),
+		// Written by the ConfigPropagation team-a/cp replaced.
+		managedTarget("app-2", "base", map[string]string{core.SourceAnnotation: "platform/base", core.OwnerAnnotation: "team-a/cp", core.OwnerUIDAnnotation: "uid-1"}),
+		managedTarget("app-2", "gone", map[string]string{core.SourceAnnotation: "platform/gone", core.OwnerAnnotation: "team-b/gone"}),
+		// Written before the owner annotation existed.
+		managedTarget("app-3", "base", map[string]string{core.SourceAnnotation: "platform/base"}),
+		managedTarget("app-3", "legacy", map[string]string{core.SourceAnnotation: "platform/legacy"}),
+		// Detached copies are not managed.
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "app-4", Name: "gone", Annotations: map[string]string{core.OwnerAnnotation: "team-b/gone"}}},
+	}
+
+	var out bytes.Buffer
+	cli := NewCLI(fake.NewClientBuilder().WithScheme(cliScheme(t)).WithObjects(objects...).Build(), &out)
+
+	orphans, err := cli.FindOrphans(context.Background(), "")
+	want := []Orphan{
+		{Namespace: "app-2", Name: "base", Owner: "team-a/cp", Reason: "ConfigPropagation team-a/cp was recreated and does not own this target"},
+		{Namespace: "app-2", Name: "gone", Owner: "team-b/gone", Reason: "ConfigPropagation team-b/gone not found"},
+		{Namespace: "app-3", Name: "legacy", Reason: "no ConfigPropagation propagates source platform/legacy"},
+	}
+	if err != nil || !reflect.DeepEqual(orphans, want) {
+		t.Fatalf("unexpected orphans %+v %v", orphans, err)
+	}
+
+	if err := cli.Orphans(context.Background(), "app-3"); err != nil {
+		t.Fatalf("orphans: %v", err)
+	}
+	if output := out.String(); !hasRow(output, "app-3", "legacy", "-") || hasRow(output, "app-2", "gone") {
+		t.Fatalf("expected only app-3 orphans, got:\n%s", output)
+	}
+
+	out.Reset()
+	if err := cli.Orphans(context.Background(), "app-1"); err != nil || out.String() != "no orphaned targets found\n" {
+		t.Fatalf("expected no orphans in app-1, got %q %v", out.String(), err)
+	}
+}
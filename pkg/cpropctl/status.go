@@ -0,0 +1,131 @@
+package cpropctl
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"configpropagation/pkg/adapters"
+	"configpropagation/pkg/core"
+)
+
+// Target states the status table reports for targets the controller did not list as out of sync.
+const (
+	stateSynced  = "Synced"
+	stateMissing = "Missing"
+	stateEdited  = "Edited"    // live data no longer matches the hash the controller recorded
+	stateForeign = "Unmanaged" // a ConfigMap of the target name that the controller does not manage
+)
+
+// Status prints the conditions of a ConfigPropagation and one row per selected namespace: the out-of-sync reason
+// recorded in status, otherwise the state of the live target, with the short hash recorded on it. Out-of-sync
+// namespaces that are no longer selected are listed last, followed by targets that could not be cleaned up.
+func (cli *CLI) Status(requestContext context.Context, namespace, name string) error {
+	configPropagation, err := cli.getConfigPropagation(requestContext, namespace, name)
+	if err != nil {
+		return err
+	}
+
+	spec := &configPropagation.Spec
+	status := &configPropagation.Status
+	core.DefaultSpec(spec)
+
+	fmt.Fprintf(cli.Out, "%s/%s: %d targets, %d synced, %d out of sync\n", namespace, name, status.TargetCount, status.SyncedCount, status.OutOfSyncCount)
+	if spec.Paused {
+		fmt.Fprintln(cli.Out, "paused: targets are not updated until resumed")
+	}
+	for _, condition := range status.Conditions {
+		fmt.Fprintf(cli.Out, "%s=%s %s: %s\n", condition.Type, condition.Status, condition.Reason, condition.Message)
+	}
+
+	selector := spec.NamespaceSelector
+	if selector == nil {
+		selector = &core.LabelSelector{}
+	}
+
+	kubeClient := cli.kubeClient()
+	targetNamespaces, err := kubeClient.ListNamespacesBySelector(requestContext, selector.MatchLabels, selectorRequirements(selector))
+	if err != nil {
+		return fmt.Errorf("list namespaces: %w", err)
+	}
+	sort.Strings(targetNamespaces)
+
+	targetNamer, err := core.NewTargetNamer(spec)
+	if err != nil {
+		return err
+	}
+
+	outOfSync := map[string]core.OutOfSyncItem{}
+	for _, item := range status.OutOfSync {
+		outOfSync[item.Namespace] = item
+	}
+
+	table := newTable(cli.Out, "NAMESPACE", "TARGET", "STATE", "HASH", "MESSAGE")
+	for _, targetNamespace := range targetNamespaces {
+		targetName, state, hash, message := cli.targetStatus(requestContext, kubeClient, targetNamer, targetNamespace)
+		if item, listed := outOfSync[targetNamespace]; listed {
+			state, message = item.Reason, item.Message
+			delete(outOfSync, targetNamespace)
+		}
+
+		table.row(targetNamespace, targetName, state, hash, message)
+	}
+
+	// Namespaces that were deleted or unlabelled since the last reconcile still show their recorded problem.
+	for _, item := range status.OutOfSync {
+		if _, remaining := outOfSync[item.Namespace]; remaining {
+			table.row(item.Namespace, "", item.Reason, "", item.Message)
+		}
+	}
+	for _, item := range status.CleanupFailures {
+		table.row(item.Namespace, "", item.Reason, "", "cleanup: "+item.Message)
+	}
+
+	return table.flush()
+}
+
+// targetStatus reads the target in one namespace and compares its data with the hash recorded on it.
+func (cli *CLI) targetStatus(requestContext context.Context, kubeClient adapters.KubeClient, targetNamer *core.TargetNamer, targetNamespace string) (targetName, state, hash, message string) {
+	targetName, err := targetNamer.Name(targetNamespace)
+	if err != nil {
+		return "", core.ReasonInvalidTargetName, "", err.Error()
+	}
+
+	data, binaryData, labels, annotations, found, err := kubeClient.GetTargetConfigMap(requestContext, targetNamespace, targetName)
+	switch {
+	case err != nil:
+		return targetName, "Unknown", "", err.Error()
+	case !found:
+		return targetName, stateMissing, "", ""
+	case labels[core.ManagedLabel] != "true":
+		return targetName, stateForeign, "", "not written by the controller"
+	}
+
+	recordedHash := annotations[core.HashAnnotation]
+	hash = shortHash(recordedHash)
+	if core.HashData(data, binaryData) != recordedHash {
+		return targetName, stateEdited, hash, "live data differs from the recorded hash"
+	}
+
+	return targetName, stateSynced, hash, ""
+}
+
+// shortHash abbreviates a content hash for display.
+func shortHash(hash string) string {
+	if len(hash) > shortHashLength {
+		return hash[:shortHashLength]
+	}
+
+	return hash
+}
+
+// selectorRequirements translates the selector expressions into adapter requirements, as the reconciler does.
+func selectorRequirements(selector *core.LabelSelector) []adapters.LabelSelectorRequirement {
+	var requirements []adapters.LabelSelectorRequirement
+
+	for _, expression := range selector.MatchExpressions {
+		requirements = append(requirements, adapters.LabelSelectorRequirement{Key: expression.Key, Operator: expression.Operator, Values: expression.Values})
+	}
+
+	return requirements
+}